@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 
 	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/config"
 	grpcServer "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/grpc"
+	httpServer "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/http"
 	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/messaging"
 	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/postgres"
 	"github.com/joho/godotenv"
 	"google.golang.org/grpc"
@@ -18,10 +21,14 @@ type App struct {
 	Log    *logger.Logger
 	DB     *sql.DB
 	GRPC   *grpcServer.Server
+	HTTP   *httpServer.Server
+	Bus    messaging.Bus // Set by EnableMessaging when a bus is configured
 	ctx    context.Context
 	cancel context.CancelFunc
 
 	grpcFactory GRPCOptionFactory
+	workers     []Worker
+	wg          sync.WaitGroup
 }
 
 func New(opts ...Option) (*App, error) {
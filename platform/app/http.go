@@ -0,0 +1,19 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+
+	httpServer "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/http"
+)
+
+// EnableHTTP serves handler on HTTP_PORT alongside the gRPC server.
+// The server is started by Run and stopped on shutdown before gRPC.
+func (a *App) EnableHTTP(handler http.Handler) error {
+	h, err := httpServer.New(fmt.Sprintf("%d", a.Cfg.HTTP.Port), httpServer.DefaultMiddleware(a.Log, handler))
+	if err != nil {
+		return err
+	}
+	a.HTTP = h
+	return nil
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/messaging"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/outbox"
+)
+
+// EnableMessaging connects the message bus when MESSAGING_DSN is set.
+// The bus is closed on shutdown.
+func (a *App) EnableMessaging() error {
+	if a.Cfg.Messaging.DSN == "" {
+		return nil
+	}
+
+	bus, err := messaging.NewPostgresBus(a.ctx, a.Cfg.Messaging, a.Log)
+	if err != nil {
+		return err
+	}
+	a.Bus = bus
+	a.Log.Info().Msg("message bus connected")
+	return nil
+}
+
+// EnableOutbox starts the relay of the outbox table of the service to the
+// bus. It requires a bus, see EnableMessaging, as the relay would otherwise
+// mark the events delivered without anyone receiving them.
+func (a *App) EnableOutbox() error {
+	if a.Bus == nil {
+		return pErrors.E(pErrors.Invalid, "the outbox relay requires a message bus, set MESSAGING_DSN", nil)
+	}
+
+	a.AddWorker(outbox.NewRelay(a.DB, messaging.OutboxPublisher(a.Bus), a.Cfg.Outbox, a.Log).Run)
+	return nil
+}
+
+// Subscribe consumes topic as a member of group for the lifetime of the app.
+// It requires a bus, see EnableMessaging.
+func (a *App) Subscribe(topic, group string, handler messaging.Handler) {
+	a.AddWorker(func(ctx context.Context) {
+		for {
+			err := a.Bus.Subscribe(ctx, topic, group, handler)
+			if ctx.Err() != nil || errors.Is(err, messaging.ErrClosed) {
+				return
+			}
+
+			a.Log.Error().Err(err).Str("topic", topic).Str("group", group).Msg("Subscription failed, retrying")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(a.Cfg.Messaging.PollInterval):
+			}
+		}
+	})
+}
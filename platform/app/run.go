@@ -10,6 +10,23 @@ func (a *App) Run() error {
 		}
 	}()
 
+	if a.HTTP != nil {
+		go func() {
+			a.Log.Info().Msg(fmt.Sprintf("HTTP server started on :%d", a.Cfg.HTTP.Port))
+			if err := a.HTTP.Start(); err != nil {
+				a.Log.Fatal().Err(err).Msg("http crashed")
+			}
+		}()
+	}
+
+	for _, w := range a.workers {
+		a.wg.Add(1)
+		go func(w Worker) {
+			defer a.wg.Done()
+			w(a.ctx)
+		}(w)
+	}
+
 	a.waitShutdown()
 	return nil
 }
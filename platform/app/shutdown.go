@@ -1,9 +1,11 @@
 package app
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func (a *App) waitShutdown() {
@@ -13,7 +15,18 @@ func (a *App) waitShutdown() {
 	<-sig
 	a.Log.Info().Msg("shutting down...")
 
+	if a.HTTP != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := a.HTTP.Stop(ctx); err != nil {
+			a.Log.Error().Err(err).Msg("http shutdown")
+		}
+		cancel()
+	}
 	a.GRPC.Stop()
-	a.DB.Close()
 	a.cancel()
+	a.wg.Wait()
+	if a.Bus != nil {
+		a.Bus.Close()
+	}
+	a.DB.Close()
 }
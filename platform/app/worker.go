@@ -0,0 +1,12 @@
+package app
+
+import "context"
+
+// Worker is a long-running background loop that shares the App lifecycle.
+// It must return once ctx is cancelled.
+type Worker func(ctx context.Context)
+
+// AddWorker registers a worker to be started by Run and stopped on shutdown.
+func (a *App) AddWorker(w Worker) {
+	a.workers = append(a.workers, w)
+}
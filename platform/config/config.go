@@ -1,9 +1,15 @@
 package config
 
+import "time"
+
 type Config struct {
-	App      AppConfig
-	GRPC     GRPCConfig
-	Postgres PostgresConfig
+	App       AppConfig
+	GRPC      GRPCConfig
+	HTTP      HTTPConfig
+	Postgres  PostgresConfig
+	Outbox    OutboxConfig
+	Messaging MessagingConfig
+	Saga      SagaConfig
 }
 
 // =======================
@@ -24,6 +30,14 @@ type GRPCConfig struct {
 	Reflection bool `env:"REFLECTION" env-default:"false"`
 }
 
+// =======================
+// HTTP Runtime (optional, see App.EnableHTTP)
+// =======================
+
+type HTTPConfig struct {
+	Port int `env:"HTTP_PORT" env-default:"8080"`
+}
+
 // =======================
 // Database
 // =======================
@@ -40,3 +54,53 @@ type PostgresConfig struct {
 	MaxIdleConns    int `env:"MAX_IDLE_CONNS" env-default:"5"`
 	ConnMaxLifetime int `env:"CONN_MAX_LIFETIME" env-default:"5"`
 }
+
+// =======================
+// Outbox relay (optional, see outbox.NewRelay)
+// =======================
+
+type OutboxConfig struct {
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" env-default:"1s"`
+	BatchSize    int           `env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+	MaxBackoff   time.Duration `env:"OUTBOX_MAX_BACKOFF" env-default:"1m"`
+	// LeaseTimeout is how long a relay has to publish the messages it
+	// claimed before another relay may claim them again
+	LeaseTimeout time.Duration `env:"OUTBOX_LEASE_TIMEOUT" env-default:"30s"`
+	// Retention is how long delivered messages are kept before cleanup
+	Retention time.Duration `env:"OUTBOX_RETENTION" env-default:"24h"`
+}
+
+// =======================
+// Message bus consumers (optional, see platform/messaging)
+// =======================
+
+type MessagingConfig struct {
+	// DSN of the database of the bus shared by the services; messaging is
+	// disabled when empty
+	DSN          string        `env:"MESSAGING_DSN"`
+	PollInterval time.Duration `env:"MESSAGING_POLL_INTERVAL" env-default:"5s"`
+	BatchSize    int           `env:"MESSAGING_BATCH_SIZE" env-default:"10"`
+	// VisibilityTimeout bounds the handling of a message; it is delivered
+	// again once it expires without an ack
+	VisibilityTimeout time.Duration `env:"MESSAGING_VISIBILITY_TIMEOUT" env-default:"30s"`
+	// MaxDeliveries is the number of deliveries before a message is dead-lettered
+	MaxDeliveries   int           `env:"MESSAGING_MAX_DELIVERIES" env-default:"10"`
+	RetryBackoff    time.Duration `env:"MESSAGING_RETRY_BACKOFF" env-default:"1s"`
+	MaxRetryBackoff time.Duration `env:"MESSAGING_MAX_RETRY_BACKOFF" env-default:"1m"`
+}
+
+// =======================
+// Saga execution mode
+// =======================
+
+const (
+	// SagaModeOrchestration runs sagas from the orchestrator, which calls the services
+	SagaModeOrchestration = "orchestration"
+	// SagaModeChoreography runs sagas from the services, which react to each
+	// other's events on the message bus while the orchestrator observes
+	SagaModeChoreography = "choreography"
+)
+
+type SagaConfig struct {
+	Mode string `env:"SAGA_MODE" env-default:"orchestration"`
+}
@@ -12,6 +12,40 @@ func validate(c *Config) error {
 		return fmt.Errorf("MAX_IDLE_CONNS cannot be negative")
 	}
 
+	if c.Outbox.PollInterval <= 0 {
+		return fmt.Errorf("OUTBOX_POLL_INTERVAL must be > 0")
+	}
+
+	if c.Outbox.BatchSize <= 0 {
+		return fmt.Errorf("OUTBOX_BATCH_SIZE must be > 0")
+	}
+
+	if c.Outbox.MaxBackoff < c.Outbox.PollInterval {
+		return fmt.Errorf("OUTBOX_MAX_BACKOFF must be >= OUTBOX_POLL_INTERVAL")
+	}
+
+	if c.Outbox.LeaseTimeout <= 0 {
+		return fmt.Errorf("OUTBOX_LEASE_TIMEOUT must be > 0")
+	}
+
+	if c.Messaging.PollInterval <= 0 || c.Messaging.VisibilityTimeout <= 0 || c.Messaging.RetryBackoff <= 0 {
+		return fmt.Errorf("MESSAGING_POLL_INTERVAL, MESSAGING_VISIBILITY_TIMEOUT and MESSAGING_RETRY_BACKOFF must be > 0")
+	}
+
+	if c.Messaging.BatchSize <= 0 || c.Messaging.MaxDeliveries <= 0 {
+		return fmt.Errorf("MESSAGING_BATCH_SIZE and MESSAGING_MAX_DELIVERIES must be > 0")
+	}
+
+	switch c.Saga.Mode {
+	case SagaModeOrchestration:
+	case SagaModeChoreography:
+		if c.Messaging.DSN == "" {
+			return fmt.Errorf("SAGA_MODE=choreography requires MESSAGING_DSN")
+		}
+	default:
+		return fmt.Errorf("SAGA_MODE must be %s or %s", SagaModeOrchestration, SagaModeChoreography)
+	}
+
 	if c.App.Env == "production" {
 
 		if c.Postgres.SSLMode != "require" {
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	derr "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+// ErrorBody is the JSON body of every error response
+type ErrorBody struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Code    derr.Code `json:"code"`
+	Message string    `json:"message"`
+}
+
+// ToStatus maps an error to an HTTP status code and a JSON error body.
+// Errors that are not platform errors are reported as internal errors
+// without leaking their message.
+func ToStatus(err error) (int, ErrorBody) {
+	var e *derr.Error
+	if !errors.As(err, &e) {
+		return http.StatusInternalServerError, internalError()
+	}
+
+	switch e.Code {
+	case derr.NotFound:
+		return http.StatusNotFound, errorBody(e)
+
+	case derr.Conflict:
+		return http.StatusConflict, errorBody(e)
+
+	case derr.Invalid:
+		return http.StatusBadRequest, errorBody(e)
+
+	case derr.Forbidden:
+		return http.StatusForbidden, errorBody(e)
+
+	case derr.Unauthorized:
+		return http.StatusUnauthorized, errorBody(e)
+
+	default:
+		return http.StatusInternalServerError, internalError()
+	}
+}
+
+// WriteError writes err as a JSON error response
+func WriteError(w http.ResponseWriter, err error) {
+	status, body := ToStatus(err)
+	WriteJSON(w, status, body)
+}
+
+// WriteJSON writes v as a JSON response with the given status code
+func WriteJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func errorBody(e *derr.Error) ErrorBody {
+	return ErrorBody{Error: ErrorDetail{Code: e.Code, Message: e.Message}}
+}
+
+func internalError() ErrorBody {
+	return ErrorBody{Error: ErrorDetail{Code: derr.Internal, Message: "internal server error"}}
+}
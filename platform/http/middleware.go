@@ -0,0 +1,60 @@
+package http
+
+import (
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+)
+
+// DefaultMiddleware wraps a handler with panic recovery and request logging,
+// the HTTP counterpart of DefaultUnaryInterceptors
+func DefaultMiddleware(log *logger.Logger, h http.Handler) http.Handler {
+	return Logging(log, Recovery(log, h))
+}
+
+func Logging(log *logger.Logger, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		h.ServeHTTP(rec, r)
+
+		log.Info().
+			Str("method", r.Method).
+			Str("path", r.URL.Path).
+			Int("status", rec.status).
+			Dur("duration", time.Since(start)).
+			Msg("http request")
+	})
+}
+
+func Recovery(log *logger.Logger, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Error().
+					Interface("panic", rec).
+					Bytes("stack", debug.Stack()).
+					Str("method", r.Method).
+					Str("path", r.URL.Path).
+					Msg("panic recovered")
+
+				WriteJSON(w, http.StatusInternalServerError, internalError())
+			}
+		}()
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+type Server struct {
+	s *http.Server
+	l net.Listener
+}
+
+func New(port string, handler http.Handler) (*Server, error) {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return &Server{
+		s: s,
+		l: lis,
+	}, nil
+}
+
+func (h *Server) Start() error {
+	if err := h.s.Serve(h.l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Stop waits for in-flight requests until ctx is done
+func (h *Server) Stop(ctx context.Context) error {
+	return h.s.Shutdown(ctx)
+}
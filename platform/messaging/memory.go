@@ -0,0 +1,221 @@
+package messaging
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/config"
+)
+
+// InMemoryBus is a Bus within one process, for tests and local runs.
+// Messages are lost when the process stops.
+type InMemoryBus struct {
+	cfg config.MessagingConfig
+
+	mu     sync.Mutex
+	seq    int64
+	groups map[string]map[string]*memoryGroup // by topic, then group
+	// unclaimed holds the messages published to topics without groups
+	unclaimed map[string][]*Message
+	closed    chan struct{}
+}
+
+// memoryGroup holds the pending messages of one group of a topic.
+// changed is closed and replaced whenever a message becomes available.
+type memoryGroup struct {
+	pending []*memoryDelivery
+	dead    []*Message
+	changed chan struct{}
+}
+
+type memoryDelivery struct {
+	msg     Message
+	readyAt time.Time
+}
+
+// NewInMemoryBus creates an empty bus
+func NewInMemoryBus(cfg config.MessagingConfig) *InMemoryBus {
+	return &InMemoryBus{
+		cfg:       cfg,
+		groups:    make(map[string]map[string]*memoryGroup),
+		unclaimed: make(map[string][]*Message),
+		closed:    make(chan struct{}),
+	}
+}
+
+// Publish delivers msg to every group subscribed to its topic. A message
+// published to a topic without groups is kept for the first group that
+// subscribes.
+func (b *InMemoryBus) Publish(ctx context.Context, msg *Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	select {
+	case <-b.closed:
+		return ErrClosed
+	default:
+	}
+
+	b.seq++
+	msg.ID = strconv.FormatInt(b.seq, 10)
+	msg.PublishedAt = time.Now()
+	if len(b.groups[msg.Topic]) == 0 {
+		b.unclaimed[msg.Topic] = append(b.unclaimed[msg.Topic], copyMessage(msg))
+		return nil
+	}
+	for _, g := range b.groups[msg.Topic] {
+		g.pending = append(g.pending, &memoryDelivery{msg: *copyMessage(msg), readyAt: msg.PublishedAt})
+		g.notify()
+	}
+	return nil
+}
+
+// Subscribe handles the messages of topic for group until ctx is cancelled
+func (b *InMemoryBus) Subscribe(ctx context.Context, topic, group string, handler Handler) error {
+	b.mu.Lock()
+	if _, ok := b.groups[topic][group]; !ok {
+		g := b.group(topic, group)
+		for _, msg := range b.unclaimed[topic] {
+			g.pending = append(g.pending, &memoryDelivery{msg: *msg, readyAt: msg.PublishedAt})
+		}
+		delete(b.unclaimed, topic)
+	}
+	b.mu.Unlock()
+
+	for {
+		delivery, wait, changed, err := b.next(topic, group)
+		if err != nil {
+			return err
+		}
+
+		if delivery == nil {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-b.closed:
+				timer.Stop()
+				return ErrClosed
+			case <-changed:
+			case <-timer.C:
+			}
+			timer.Stop()
+			continue
+		}
+
+		delivery.msg.Attempt++
+		handleCtx, cancel := context.WithTimeout(ctx, b.cfg.VisibilityTimeout)
+		err = handler(handleCtx, copyMessage(&delivery.msg))
+		cancel()
+		b.settle(topic, group, delivery, err)
+	}
+}
+
+// next takes the first message of the group that is ready. When there is
+// none, it returns how long to wait for one.
+func (b *InMemoryBus) next(topic, group string) (*memoryDelivery, time.Duration, <-chan struct{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	select {
+	case <-b.closed:
+		return nil, 0, nil, ErrClosed
+	default:
+	}
+
+	g := b.group(topic, group)
+	now := time.Now()
+	wait := b.cfg.PollInterval
+	for i, d := range g.pending {
+		if !d.readyAt.After(now) {
+			g.pending = append(g.pending[:i], g.pending[i+1:]...)
+			return d, 0, nil, nil
+		}
+		wait = min(wait, d.readyAt.Sub(now))
+	}
+	return nil, wait, g.changed, nil
+}
+
+// settle nacks or dead-letters a delivery whose handler failed
+func (b *InMemoryBus) settle(topic, group string, delivery *memoryDelivery, err error) {
+	if err == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g := b.group(topic, group)
+	if IsRejected(err) || delivery.msg.Attempt >= b.cfg.MaxDeliveries {
+		g.dead = append(g.dead, copyMessage(&delivery.msg))
+		return
+	}
+
+	delivery.readyAt = time.Now().Add(retryBackoff(b.cfg, delivery.msg.Attempt))
+	g.pending = append(g.pending, delivery)
+	g.notify()
+}
+
+// DeadLetters returns the messages dead-lettered for a group
+func (b *InMemoryBus) DeadLetters(topic, group string) []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, ok := b.groups[topic][group]
+	if !ok {
+		return nil
+	}
+	var dead []*Message
+	for _, msg := range g.dead {
+		dead = append(dead, copyMessage(msg))
+	}
+	return dead
+}
+
+// Close stops the subscribers and rejects later publishes
+func (b *InMemoryBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	select {
+	case <-b.closed:
+	default:
+		close(b.closed)
+	}
+	return nil
+}
+
+// group returns the group of a topic, creating it on first use.
+// The caller holds b.mu.
+func (b *InMemoryBus) group(topic, group string) *memoryGroup {
+	groups, ok := b.groups[topic]
+	if !ok {
+		groups = make(map[string]*memoryGroup)
+		b.groups[topic] = groups
+	}
+
+	g, ok := groups[group]
+	if !ok {
+		g = &memoryGroup{changed: make(chan struct{})}
+		groups[group] = g
+	}
+	return g
+}
+
+func (g *memoryGroup) notify() {
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// copyMessage keeps the consumers of different groups from sharing headers
+func copyMessage(msg *Message) *Message {
+	c := *msg
+	c.Headers = make(map[string]string, len(msg.Headers))
+	for k, v := range msg.Headers {
+		c.Headers[k] = v
+	}
+	return &c
+}
@@ -0,0 +1,288 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/config"
+)
+
+func testConfig() config.MessagingConfig {
+	return config.MessagingConfig{
+		PollInterval:      10 * time.Millisecond,
+		BatchSize:         10,
+		VisibilityTimeout: time.Second,
+		MaxDeliveries:     3,
+		RetryBackoff:      time.Millisecond,
+		MaxRetryBackoff:   5 * time.Millisecond,
+	}
+}
+
+// delivery is one call of a handler
+type delivery struct {
+	group   string
+	id      string
+	attempt int
+}
+
+// subscribe runs a consumer of group until the test ends, reporting every
+// delivery on the returned channel. The handler decides the outcome.
+func subscribe(t *testing.T, bus *InMemoryBus, topic, group string, handle func(msg *Message) error) <-chan delivery {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	deliveries := make(chan delivery, 100)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		bus.Subscribe(ctx, topic, group, func(ctx context.Context, msg *Message) error {
+			deliveries <- delivery{group: group, id: msg.ID, attempt: msg.Attempt}
+			return handle(msg)
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	return deliveries
+}
+
+func ack(msg *Message) error { return nil }
+
+func receive(t *testing.T, deliveries <-chan delivery) delivery {
+	t.Helper()
+	select {
+	case d := <-deliveries:
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("no delivery")
+		return delivery{}
+	}
+}
+
+func expectNone(t *testing.T, deliveries <-chan delivery) {
+	t.Helper()
+	select {
+	case d := <-deliveries:
+		t.Fatalf("unexpected delivery %+v", d)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// waitForGroup waits until a subscription registered its group
+func waitForGroup(t *testing.T, bus *InMemoryBus, topic, group string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		bus.mu.Lock()
+		_, ok := bus.groups[topic][group]
+		bus.mu.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("group %s of %s not registered", group, topic)
+}
+
+func publish(t *testing.T, bus *InMemoryBus, topic string) *Message {
+	t.Helper()
+	msg, err := NewMessage(topic, "order-1", map[string]string{"order_id": "order-1"})
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	if err := bus.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	return msg
+}
+
+func TestInMemoryBusDeliversToEveryGroup(t *testing.T) {
+	bus := NewInMemoryBus(testConfig())
+	defer bus.Close()
+
+	payments := subscribe(t, bus, "OrderCreated", "payment", ack)
+	inventory := subscribe(t, bus, "OrderCreated", "inventory", ack)
+	waitForGroup(t, bus, "OrderCreated", "payment")
+	waitForGroup(t, bus, "OrderCreated", "inventory")
+
+	msg := publish(t, bus, "OrderCreated")
+
+	for _, deliveries := range []<-chan delivery{payments, inventory} {
+		d := receive(t, deliveries)
+		if d.id != msg.ID || d.attempt != 1 {
+			t.Errorf("delivery = %+v, want message %s, attempt 1", d, msg.ID)
+		}
+		expectNone(t, deliveries)
+	}
+}
+
+func TestInMemoryBusSharesMessagesWithinGroup(t *testing.T) {
+	bus := NewInMemoryBus(testConfig())
+	defer bus.Close()
+
+	first := subscribe(t, bus, "OrderCreated", "payment", ack)
+	second := subscribe(t, bus, "OrderCreated", "payment", ack)
+	waitForGroup(t, bus, "OrderCreated", "payment")
+
+	const n = 5
+	for i := 0; i < n; i++ {
+		publish(t, bus, "OrderCreated")
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < n; i++ {
+		var d delivery
+		select {
+		case d = <-first:
+		case d = <-second:
+		case <-time.After(time.Second):
+			t.Fatalf("got %d deliveries, want %d", i, n)
+		}
+		if seen[d.id] {
+			t.Fatalf("message %s delivered twice", d.id)
+		}
+		seen[d.id] = true
+	}
+	expectNone(t, first)
+	expectNone(t, second)
+}
+
+func TestInMemoryBusKeepsMessagesForFirstGroup(t *testing.T) {
+	bus := NewInMemoryBus(testConfig())
+	defer bus.Close()
+
+	// Published before its consumer started
+	msg := publish(t, bus, "OrderCreated")
+
+	deliveries := subscribe(t, bus, "OrderCreated", "inventory", ack)
+	if d := receive(t, deliveries); d.id != msg.ID {
+		t.Errorf("delivery = %+v, want message %s", d, msg.ID)
+	}
+
+	// Later groups only receive what is published after they subscribed
+	late := subscribe(t, bus, "OrderCreated", "payment", ack)
+	waitForGroup(t, bus, "OrderCreated", "payment")
+	expectNone(t, late)
+}
+
+func TestInMemoryBusRedelivery(t *testing.T) {
+	handlerErr := errors.New("downstream unavailable")
+
+	tests := []struct {
+		name string
+		// fail returns the error of the handler for an attempt
+		fail         func(attempt int) error
+		wantAttempts int
+		wantDead     bool
+	}{
+		{
+			name:         "acked at once",
+			fail:         func(attempt int) error { return nil },
+			wantAttempts: 1,
+		},
+		{
+			name: "redelivered until acked",
+			fail: func(attempt int) error {
+				if attempt < 3 {
+					return handlerErr
+				}
+				return nil
+			},
+			wantAttempts: 3,
+		},
+		{
+			name:         "dead-lettered after max deliveries",
+			fail:         func(attempt int) error { return handlerErr },
+			wantAttempts: 3,
+			wantDead:     true,
+		},
+		{
+			name:         "rejected messages are dead-lettered at once",
+			fail:         func(attempt int) error { return Reject(handlerErr) },
+			wantAttempts: 1,
+			wantDead:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewInMemoryBus(testConfig())
+			defer bus.Close()
+
+			deliveries := subscribe(t, bus, "PaymentFailed", "order", func(msg *Message) error {
+				return tt.fail(msg.Attempt)
+			})
+			waitForGroup(t, bus, "PaymentFailed", "order")
+			msg := publish(t, bus, "PaymentFailed")
+
+			for attempt := 1; attempt <= tt.wantAttempts; attempt++ {
+				d := receive(t, deliveries)
+				if d.id != msg.ID || d.attempt != attempt {
+					t.Fatalf("delivery = %+v, want message %s, attempt %d", d, msg.ID, attempt)
+				}
+			}
+			expectNone(t, deliveries)
+
+			dead := bus.DeadLetters("PaymentFailed", "order")
+			if tt.wantDead != (len(dead) == 1) {
+				t.Fatalf("dead letters = %d, want dead-lettered %v", len(dead), tt.wantDead)
+			}
+			if tt.wantDead && dead[0].ID != msg.ID {
+				t.Errorf("dead letter = %s, want %s", dead[0].ID, msg.ID)
+			}
+		})
+	}
+}
+
+func TestInMemoryBusClose(t *testing.T) {
+	bus := NewInMemoryBus(testConfig())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- bus.Subscribe(context.Background(), "OrderCreated", "payment", func(ctx context.Context, msg *Message) error {
+			return nil
+		})
+	}()
+	waitForGroup(t, bus, "OrderCreated", "payment")
+
+	bus.Close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("Subscribe() error = %v, want ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Subscribe() did not return after Close")
+	}
+
+	msg, _ := NewMessage("OrderCreated", "order-1", struct{}{})
+	if err := bus.Publish(context.Background(), msg); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish() error = %v, want ErrClosed", err)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	cfg := config.MessagingConfig{RetryBackoff: time.Second, MaxRetryBackoff: 10 * time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{20, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := retryBackoff(cfg, tt.attempt); got != tt.want {
+			t.Errorf("retryBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package messaging is an asynchronous message bus. Messages are published
+// to a topic and delivered to every consumer group subscribed to it; within
+// a group, each message is handled by one of its consumers.
+//
+// Delivery is at least once: a message is acknowledged when its handler
+// returns nil, and delivered again after a backoff when the handler fails or
+// does not finish within the visibility timeout. After MaxDeliveries failed
+// deliveries, or when the handler rejects it, the message is dead-lettered
+// for the group. Handlers must therefore be idempotent.
+//
+// A group receives the messages published after it first subscribed. The
+// messages published to a topic before any group subscribed are kept, and
+// delivered to the first group that does.
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/config"
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Message is one message of a topic
+type Message struct {
+	// ID is assigned when the message is published
+	ID    string
+	Topic string
+	// Key identifies what the message is about, e.g. an aggregate id
+	Key         string
+	Payload     json.RawMessage
+	Headers     map[string]string
+	PublishedAt time.Time
+	// Attempt numbers the deliveries of the message to the group, from 1
+	Attempt int
+}
+
+// NewMessage encodes payload as the JSON body of a message (factory function)
+func NewMessage(topic, key string, payload any) (*Message, error) {
+	if topic == "" {
+		return nil, pErrors.E(pErrors.Invalid, "topic is required", nil)
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Invalid, "failed to encode message payload", err)
+	}
+
+	return &Message{Topic: topic, Key: key, Payload: b, Headers: map[string]string{}}, nil
+}
+
+// Decode reads the JSON payload of msg into a protobuf event, rejecting a
+// message that does not decode. Unknown fields are ignored, so that
+// producers can add fields first.
+func Decode(msg *Message, event proto.Message) error {
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(msg.Payload, event); err != nil {
+		return Reject(pErrors.E(pErrors.Invalid, "failed to decode "+msg.Topic+" message", err))
+	}
+	return nil
+}
+
+// Handler processes one delivery of a message. Returning nil acks it; an
+// error nacks it, and it is delivered again after a backoff.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Consumer consumes a topic as a member of a group for the lifetime of the
+// service, such as an app.App. The handlers of a service register on it.
+type Consumer interface {
+	Subscribe(topic, group string, handler Handler)
+}
+
+// Publisher publishes messages
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// Subscriber consumes the messages of a topic as a member of a group.
+// Subscribe blocks until ctx is cancelled or the bus is closed.
+type Subscriber interface {
+	Subscribe(ctx context.Context, topic, group string, handler Handler) error
+}
+
+// Bus is a message bus backend
+type Bus interface {
+	Publisher
+	Subscriber
+	Close() error
+}
+
+// ErrClosed is returned once the bus is closed
+var ErrClosed = errors.New("messaging: bus closed")
+
+type rejected struct{ err error }
+
+func (r rejected) Error() string { return "rejected: " + r.err.Error() }
+func (r rejected) Unwrap() error { return r.err }
+
+// Reject wraps the error of a handler to dead-letter the message at once,
+// for messages that no redelivery can fix
+func Reject(err error) error {
+	return rejected{err: err}
+}
+
+// IsRejected reports whether a handler rejected the message
+func IsRejected(err error) bool {
+	var r rejected
+	return errors.As(err, &r)
+}
+
+// RejectPermanent rejects the platform errors that no redelivery can fix,
+// those of the message rather than of the handler: invalid, not found,
+// conflict and the like. Other errors are returned as they are, so that the
+// message is delivered again.
+func RejectPermanent(err error) error {
+	var pErr *pErrors.Error
+	if errors.As(err, &pErr) && pErr.Code != pErrors.Internal {
+		return Reject(err)
+	}
+	return err
+}
+
+// retryBackoff doubles the backoff with every failed delivery, up to the maximum
+func retryBackoff(cfg config.MessagingConfig, attempt int) time.Duration {
+	backoff := cfg.RetryBackoff
+	for i := 1; i < attempt && backoff < cfg.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, max(cfg.MaxRetryBackoff, cfg.RetryBackoff))
+}
@@ -0,0 +1,51 @@
+package messaging
+
+import (
+	"errors"
+	"testing"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+func TestRejectPermanent(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantRejected bool
+	}{
+		{"invalid message", pErrors.E(pErrors.Invalid, "invalid order id", nil), true},
+		{"unknown aggregate", pErrors.E(pErrors.NotFound, "order not found", nil), true},
+		{"wrapped conflict", errors.Join(errors.New("reserve"), pErrors.E(pErrors.Conflict, "already reserved", nil)), true},
+		{"internal error", pErrors.E(pErrors.Internal, "database unavailable", nil), false},
+		{"plain error", errors.New("connection reset"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RejectPermanent(tt.err)
+			if got := IsRejected(err); got != tt.wantRejected {
+				t.Errorf("IsRejected(RejectPermanent(err)) = %v, want %v", got, tt.wantRejected)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("RejectPermanent(err) does not wrap err")
+			}
+		})
+	}
+}
+
+func TestNewMessage(t *testing.T) {
+	if _, err := NewMessage("", "order-1", struct{}{}); err == nil {
+		t.Error("NewMessage() without topic succeeded")
+	}
+	if _, err := NewMessage("OrderCreated", "order-1", func() {}); err == nil {
+		t.Error("NewMessage() with an unencodable payload succeeded")
+	}
+
+	msg, err := NewMessage("OrderCreated", "order-1", map[string]string{"order_id": "order-1"})
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	if string(msg.Payload) != `{"order_id":"order-1"}` || msg.Key != "order-1" {
+		t.Errorf("NewMessage() = %+v", msg)
+	}
+}
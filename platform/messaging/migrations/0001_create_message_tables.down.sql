@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS message_deliveries;
+DROP TABLE IF EXISTS message_groups;
+DROP TABLE IF EXISTS messages;
@@ -0,0 +1,35 @@
+-- Message bus of platform/messaging, in a database shared by the services
+
+CREATE TABLE messages (
+    id              BIGSERIAL PRIMARY KEY,
+    topic           VARCHAR(100) NOT NULL,
+    key             VARCHAR(255) NOT NULL DEFAULT '',
+    payload         JSONB NOT NULL,
+    headers         JSONB NOT NULL DEFAULT '{}',
+    published_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Consumer groups, registered by their first subscription
+CREATE TABLE message_groups (
+    topic           VARCHAR(100) NOT NULL,
+    group_name      VARCHAR(100) NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (topic, group_name)
+);
+
+-- One row per message and group, deleted once the group acked it
+CREATE TABLE message_deliveries (
+    message_id      BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    topic           VARCHAR(100) NOT NULL,
+    group_name      VARCHAR(100) NOT NULL,
+    attempts        INT NOT NULL DEFAULT 0,
+    available_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT,
+    dead_at         TIMESTAMPTZ,
+
+    PRIMARY KEY (message_id, group_name)
+);
+
+CREATE INDEX idx_message_deliveries_available ON message_deliveries(topic, group_name, available_at, message_id) WHERE dead_at IS NULL;
+CREATE INDEX idx_message_deliveries_dead ON message_deliveries(topic, group_name) WHERE dead_at IS NOT NULL;
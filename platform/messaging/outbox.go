@@ -0,0 +1,34 @@
+package messaging
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/outbox"
+)
+
+// Headers set on the messages relayed from an outbox
+const (
+	HeaderAggregateType = "aggregate_type"
+	HeaderOutboxID      = "outbox_id"
+)
+
+// OutboxPublisher relays outbox messages to the bus: each event is published
+// to the topic named after its type, keyed by its aggregate id
+func OutboxPublisher(publisher Publisher) outbox.Publisher {
+	return outbox.PublisherFunc(func(ctx context.Context, msg *outbox.Message) error {
+		headers := make(map[string]string, len(msg.Headers)+2)
+		for k, v := range msg.Headers {
+			headers[k] = v
+		}
+		headers[HeaderAggregateType] = msg.AggregateType
+		headers[HeaderOutboxID] = strconv.FormatInt(msg.ID, 10)
+
+		return publisher.Publish(ctx, &Message{
+			Topic:   msg.EventType,
+			Key:     msg.AggregateID,
+			Payload: msg.Payload,
+			Headers: headers,
+		})
+	})
+}
@@ -0,0 +1,382 @@
+package messaging
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/config"
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/lib/pq"
+)
+
+// notifyChannel is the LISTEN/NOTIFY channel; the payload is the topic
+const notifyChannel = "messaging"
+
+// PostgresBus is a Bus stored in a Postgres database shared by the services,
+// created by the migrations in platform/messaging/migrations.
+//
+// A published message gets one delivery row per subscribed group, or none
+// until the first group of its topic subscribes. Consumers
+// claim deliveries with SKIP LOCKED and hide them for the visibility timeout;
+// an ack deletes the delivery. NOTIFY wakes the consumers of a topic at once,
+// polling only covers notifications lost while the listener reconnects.
+type PostgresBus struct {
+	db       *sql.DB
+	listener *pq.Listener
+	cfg      config.MessagingConfig
+	logger   *logger.Logger
+
+	mu      sync.Mutex
+	waiters map[string]map[chan struct{}]struct{} // by topic
+	closed  chan struct{}
+}
+
+// NewPostgresBus connects to the bus database at cfg.DSN and starts listening
+func NewPostgresBus(ctx context.Context, cfg config.MessagingConfig, log *logger.Logger) (*PostgresBus, error) {
+	db, err := sql.Open("postgres", cfg.DSN)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to open message bus database", err)
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		db.Close()
+		return nil, pErrors.E(pErrors.Internal, "failed to connect message bus database", err)
+	}
+
+	b := &PostgresBus{
+		db:      db,
+		cfg:     cfg,
+		logger:  log,
+		waiters: make(map[string]map[chan struct{}]struct{}),
+		closed:  make(chan struct{}),
+	}
+	b.listener = pq.NewListener(cfg.DSN, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warn().Err(err).Msg("Message bus listener disconnected")
+		}
+	})
+	if err := b.listener.Listen(notifyChannel); err != nil {
+		b.listener.Close()
+		db.Close()
+		return nil, pErrors.E(pErrors.Internal, "failed to listen for messages", err)
+	}
+
+	go b.dispatch()
+	return b, nil
+}
+
+// dispatch wakes the consumers of the topics that got a message. A nil
+// notification follows a reconnect, after which every consumer looks.
+func (b *PostgresBus) dispatch() {
+	for n := range b.listener.Notify {
+		b.mu.Lock()
+		for topic, waiters := range b.waiters {
+			if n != nil && n.Extra != topic {
+				continue
+			}
+			for wake := range waiters {
+				select {
+				case wake <- struct{}{}:
+				default:
+				}
+			}
+		}
+		b.mu.Unlock()
+	}
+}
+
+// Publish stores msg with one delivery per group subscribed to its topic.
+// A message published to a topic without groups is kept for the first
+// group that subscribes.
+func (b *PostgresBus) Publish(ctx context.Context, msg *Message) error {
+	headers := []byte(`{}`)
+	if len(msg.Headers) > 0 {
+		// A map of strings always encodes
+		headers, _ = json.Marshal(msg.Headers)
+	}
+
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to publish message", err)
+	}
+	defer tx.Rollback()
+
+	// Publishes share the lock of the topic, so that a group registering
+	// waits for them and then sees their messages, see register
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock_shared(hashtext('messaging'), hashtext($1))`, msg.Topic); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to publish message", err)
+	}
+
+	// The notification is sent when the transaction commits
+	query := `
+		WITH message AS (
+			INSERT INTO messages (topic, key, payload, headers)
+			VALUES ($1, $2, $3::jsonb, $4::jsonb)
+			RETURNING id, published_at
+		), deliveries AS (
+			INSERT INTO message_deliveries (message_id, topic, group_name)
+			SELECT message.id, $1, message_groups.group_name
+			FROM message, message_groups
+			WHERE message_groups.topic = $1
+		)
+		SELECT message.id, message.published_at
+		FROM message CROSS JOIN LATERAL (SELECT pg_notify($5, $1)) AS notified
+	`
+	var id int64
+	var published time.Time
+	err = tx.QueryRowContext(ctx, query,
+		msg.Topic, msg.Key, []byte(msg.Payload), headers, notifyChannel,
+	).Scan(&id, &published)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to publish message", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to publish message", err)
+	}
+
+	msg.ID = strconv.FormatInt(id, 10)
+	msg.PublishedAt = published
+	return nil
+}
+
+// register records the group of a topic on its first subscription. The
+// first group of a topic takes over the messages published before it, so
+// that events are not lost when their consumers start after the producers.
+func (b *PostgresBus) register(ctx context.Context, topic, group string) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Waits for the publishes in progress, which could not see the group
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('messaging'), hashtext($1))`, topic); err != nil {
+		return err
+	}
+
+	// Messages without deliveries were published while the topic had no
+	// group; acked messages are deleted with their last delivery
+	_, err = tx.ExecContext(ctx, `
+		WITH registered AS (
+			INSERT INTO message_groups (topic, group_name) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+			RETURNING group_name
+		)
+		INSERT INTO message_deliveries (message_id, topic, group_name)
+		SELECT messages.id, $1, registered.group_name
+		FROM messages, registered
+		WHERE messages.topic = $1
+		  AND NOT EXISTS (SELECT 1 FROM message_deliveries WHERE message_deliveries.message_id = messages.id)
+	`, topic, group)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Subscribe registers the group and handles the messages of topic until ctx
+// is cancelled or the bus is closed. Several consumers of a group, in this
+// process or others, share its messages.
+func (b *PostgresBus) Subscribe(ctx context.Context, topic, group string, handler Handler) error {
+	if err := b.register(ctx, topic, group); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to register consumer group", err)
+	}
+
+	wake := make(chan struct{}, 1)
+	b.mu.Lock()
+	if b.waiters[topic] == nil {
+		b.waiters[topic] = make(map[chan struct{}]struct{})
+	}
+	b.waiters[topic][wake] = struct{}{}
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.waiters[topic], wake)
+		b.mu.Unlock()
+	}()
+
+	ticker := time.NewTicker(b.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := b.consume(ctx, topic, group, handler)
+		if err != nil && ctx.Err() == nil {
+			b.logger.Error().Err(err).Str("topic", topic).Str("group", group).Msg("Failed to consume messages")
+		}
+
+		// A full batch means more messages may be waiting
+		if err == nil && n == b.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.closed:
+			return ErrClosed
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// consume claims one batch of deliveries and handles them one by one
+func (b *PostgresBus) consume(ctx context.Context, topic, group string, handler Handler) (int, error) {
+	// Claiming hides the deliveries for the visibility timeout, after
+	// which they are delivered again if they were not settled
+	query := `
+		UPDATE message_deliveries
+		SET attempts = message_deliveries.attempts + 1,
+			available_at = NOW() + make_interval(secs => $4)
+		FROM messages
+		WHERE messages.id = message_deliveries.message_id
+		  AND (message_deliveries.message_id, message_deliveries.group_name) IN (
+			SELECT message_id, group_name
+			FROM message_deliveries
+			WHERE topic = $1 AND group_name = $2
+			  AND dead_at IS NULL AND available_at <= NOW()
+			ORDER BY message_id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		  )
+		RETURNING messages.id, messages.key, messages.payload, messages.headers, messages.published_at, message_deliveries.attempts
+	`
+	rows, err := b.db.QueryContext(ctx, query, topic, group, b.cfg.BatchSize, b.cfg.VisibilityTimeout.Seconds())
+	if err != nil {
+		return 0, pErrors.E(pErrors.Internal, "failed to claim messages", err)
+	}
+
+	var messages []*Message
+	ids := make(map[*Message]int64)
+	for rows.Next() {
+		var id int64
+		var headers []byte
+		msg := &Message{Topic: topic}
+		if err := rows.Scan(&id, &msg.Key, &msg.Payload, &headers, &msg.PublishedAt, &msg.Attempt); err != nil {
+			rows.Close()
+			return 0, pErrors.E(pErrors.Internal, "failed to scan message", err)
+		}
+		if err := json.Unmarshal(headers, &msg.Headers); err != nil {
+			rows.Close()
+			return 0, pErrors.E(pErrors.Internal, "failed to decode message headers", err)
+		}
+		msg.ID = strconv.FormatInt(id, 10)
+		ids[msg] = id
+		messages = append(messages, msg)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, pErrors.E(pErrors.Internal, "failed to claim messages", err)
+	}
+
+	// RETURNING does not keep the order of the subquery
+	sort.Slice(messages, func(i, j int) bool { return ids[messages[i]] < ids[messages[j]] })
+
+	for _, msg := range messages {
+		handleCtx, cancel := context.WithTimeout(ctx, b.cfg.VisibilityTimeout)
+		handleErr := handler(handleCtx, msg)
+		cancel()
+
+		if err := b.settle(ctx, group, msg, handleErr); err != nil {
+			return 0, err
+		}
+	}
+	return len(messages), nil
+}
+
+// settle acks, nacks or dead-letters a delivery after its handler returned
+func (b *PostgresBus) settle(ctx context.Context, group string, msg *Message, handleErr error) error {
+	var err error
+	switch {
+	case handleErr == nil:
+		err = b.ack(ctx, group, msg)
+
+	case IsRejected(handleErr) || msg.Attempt >= b.cfg.MaxDeliveries:
+		b.logger.Error().
+			Err(handleErr).
+			Str("topic", msg.Topic).
+			Str("group", group).
+			Str("message_id", msg.ID).
+			Int("attempt", msg.Attempt).
+			Msg("Message dead-lettered")
+
+		_, err = b.db.ExecContext(ctx,
+			`UPDATE message_deliveries SET dead_at = NOW(), last_error = $3 WHERE message_id = $1 AND group_name = $2`,
+			msg.ID, group, handleErr.Error(),
+		)
+
+	default:
+		b.logger.Warn().
+			Err(handleErr).
+			Str("topic", msg.Topic).
+			Str("group", group).
+			Str("message_id", msg.ID).
+			Int("attempt", msg.Attempt).
+			Msg("Message handling failed, will be redelivered")
+
+		_, err = b.db.ExecContext(ctx,
+			`UPDATE message_deliveries SET available_at = NOW() + make_interval(secs => $3), last_error = $4 WHERE message_id = $1 AND group_name = $2`,
+			msg.ID, group, retryBackoff(b.cfg, msg.Attempt).Seconds(), handleErr.Error(),
+		)
+	}
+
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to settle message", err)
+	}
+	return nil
+}
+
+// ack deletes the delivery, and the message once no group has one left.
+// The message row is locked so that the last of concurrent acks sees the
+// deliveries of the others gone.
+func (b *PostgresBus) ack(ctx context.Context, group string, msg *Message) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM messages WHERE id = $1 FOR UPDATE`, msg.ID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM message_deliveries WHERE message_id = $1 AND group_name = $2`, msg.ID, group); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM messages
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM message_deliveries WHERE message_id = $1)
+	`, msg.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Close stops the subscribers and the listener
+func (b *PostgresBus) Close() error {
+	b.mu.Lock()
+	select {
+	case <-b.closed:
+		b.mu.Unlock()
+		return nil
+	default:
+		close(b.closed)
+	}
+	b.mu.Unlock()
+
+	b.listener.Close()
+	return b.db.Close()
+}
@@ -0,0 +1,126 @@
+// Package outbox implements the transactional outbox: a service writes the
+// domain events of a change into the outbox table in the transaction of the
+// change, and a Relay publishes them afterwards. An event is therefore
+// published if and only if its change was committed, at least once, and in
+// order for each aggregate.
+//
+// The outbox table is created by the migrations of each service.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Message is one domain event of an aggregate.
+// ID is assigned on write and increases with every message of a database.
+type Message struct {
+	ID            int64
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Payload       json.RawMessage
+	Headers       map[string]string
+	CreatedAt     time.Time
+	// Attempts counts the failed deliveries so far
+	Attempts int
+}
+
+// NewMessage encodes payload as the JSON body of an event (factory function)
+func NewMessage(aggregateType, aggregateID, eventType string, payload any) (*Message, error) {
+	if aggregateType == "" || aggregateID == "" || eventType == "" {
+		return nil, pErrors.E(pErrors.Invalid, "aggregate type, aggregate id and event type are required", nil)
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Invalid, "failed to encode event payload", err)
+	}
+
+	return &Message{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       b,
+		Headers:       map[string]string{},
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// Write adds messages to the outbox within the caller's transaction, so they
+// are only published if the transaction commits. Messages of one aggregate
+// are published in the order they are written.
+func Write(ctx context.Context, tx *sql.Tx, messages ...*Message) error {
+	query := `
+		INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload, headers, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	for _, msg := range messages {
+		headers := []byte(`{}`)
+		if len(msg.Headers) > 0 {
+			// A map of strings always encodes
+			headers, _ = json.Marshal(msg.Headers)
+		}
+
+		err := tx.QueryRowContext(ctx, query,
+			msg.AggregateType, msg.AggregateID, msg.EventType, []byte(msg.Payload), headers, msg.CreatedAt,
+		).Scan(&msg.ID)
+		if err != nil {
+			return pErrors.E(pErrors.Internal, "failed to write outbox message", err)
+		}
+	}
+	return nil
+}
+
+// Store adds messages to the outbox in a transaction of their own. It is for
+// events that report a change committed before by an idempotent operation,
+// such as the reaction to a message: if the process stops in between, the
+// message is delivered again, the operation repeated and the events stored.
+func Store(ctx context.Context, db *sql.DB, messages ...*Message) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if err := Write(ctx, tx, messages...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return nil
+}
+
+// Publisher delivers a message to its consumers. An error leaves the message
+// in the outbox to be published again later, so consumers must tolerate
+// duplicates, e.g. by remembering the ids they handled.
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// PublisherFunc adapts a function to a Publisher
+type PublisherFunc func(ctx context.Context, msg *Message) error
+
+// Publish calls f
+func (f PublisherFunc) Publish(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// NewEvent encodes a protobuf event as JSON, typed after the name of its
+// message (factory function)
+func NewEvent(aggregateType, aggregateID string, event proto.Message) (*Message, error) {
+	payload, err := protojson.Marshal(event)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Invalid, "failed to encode event", err)
+	}
+	return NewMessage(aggregateType, aggregateID, string(event.ProtoReflect().Descriptor().Name()), json.RawMessage(payload))
+}
@@ -0,0 +1,217 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/config"
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+)
+
+// Relay publishes the messages of the outbox table. Several replicas may run
+// a relay on the same database: a message is leased to one of them at a
+// time, for the lease timeout.
+//
+// Only the oldest pending message of each aggregate can be claimed, so the
+// messages of an aggregate are published one after the other, in order. A
+// message that fails is retried with a growing backoff and holds back the
+// later messages of its aggregate, never those of other aggregates.
+type Relay struct {
+	db        *sql.DB
+	publisher Publisher
+	cfg       config.OutboxConfig
+	logger    *logger.Logger
+}
+
+// NewRelay creates a relay; Run is an app.Worker
+func NewRelay(db *sql.DB, publisher Publisher, cfg config.OutboxConfig, log *logger.Logger) *Relay {
+	return &Relay{db: db, publisher: publisher, cfg: cfg, logger: log}
+}
+
+// Run publishes pending messages until ctx is cancelled. It drains the
+// outbox before waiting for the next poll, and deletes the messages
+// delivered longer than the retention ago once per retention period.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			n, err := r.relay(ctx)
+			if err != nil {
+				r.logger.Error().Err(err).Msg("Failed to relay outbox messages")
+			}
+			if err != nil || n < r.cfg.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		if r.cfg.Retention > 0 && time.Since(lastCleanup) >= r.cfg.Retention {
+			lastCleanup = time.Now()
+			if err := r.cleanup(ctx); err != nil {
+				r.logger.Error().Err(err).Msg("Failed to clean up outbox")
+			}
+		}
+	}
+}
+
+// relay claims and publishes one batch, returning the number of messages claimed
+func (r *Relay) relay(ctx context.Context) (int, error) {
+	messages, err := r.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// No transaction is open while publishing. Messages not published
+	// within the lease are left to be claimed again once it expires.
+	leaseCtx, cancel := context.WithTimeout(ctx, r.cfg.LeaseTimeout)
+	defer cancel()
+
+	published := make(map[*Message]error, len(messages))
+	for _, msg := range messages {
+		if leaseCtx.Err() != nil {
+			break
+		}
+		published[msg] = r.publisher.Publish(leaseCtx, msg)
+	}
+
+	if err := r.settle(ctx, messages, published); err != nil {
+		return 0, err
+	}
+	return len(messages), nil
+}
+
+// claim leases a batch of pending messages to this relay by moving their
+// next attempt past the lease. Another relay skips them until the lease
+// expires, and sees the aggregates they head as pending, so it does not
+// claim their successors either.
+func (r *Relay) claim(ctx context.Context) ([]*Message, error) {
+	query := `
+		UPDATE outbox
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id
+			FROM outbox
+			WHERE delivered_at IS NULL
+			  AND next_attempt_at <= NOW()
+			  AND NOT EXISTS (
+				SELECT 1 FROM outbox AS earlier
+				WHERE earlier.aggregate_type = outbox.aggregate_type
+				  AND earlier.aggregate_id = outbox.aggregate_id
+				  AND earlier.delivered_at IS NULL
+				  AND earlier.id < outbox.id
+			  )
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, aggregate_type, aggregate_id, event_type, payload, headers, created_at, attempts
+	`
+	rows, err := r.db.QueryContext(ctx, query, r.cfg.BatchSize, r.cfg.LeaseTimeout.Seconds())
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to claim outbox messages", err)
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		var msg Message
+		var headers []byte
+		if err := rows.Scan(
+			&msg.ID, &msg.AggregateType, &msg.AggregateID, &msg.EventType,
+			&msg.Payload, &headers, &msg.CreatedAt, &msg.Attempts,
+		); err != nil {
+			return nil, pErrors.E(pErrors.Internal, "failed to scan outbox message", err)
+		}
+		if err := json.Unmarshal(headers, &msg.Headers); err != nil {
+			return nil, pErrors.E(pErrors.Internal, "failed to decode outbox message headers", err)
+		}
+		messages = append(messages, &msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to claim outbox messages", err)
+	}
+
+	// RETURNING does not keep the order of the subquery
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, nil
+}
+
+// settle marks the published messages delivered and reschedules the failed
+// ones. A crash before the commit publishes the messages again once their
+// lease expires.
+func (r *Relay) settle(ctx context.Context, messages []*Message, published map[*Message]error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	for _, msg := range messages {
+		publishErr, ok := published[msg]
+		if !ok {
+			continue
+		}
+
+		if publishErr != nil {
+			r.logger.Warn().
+				Err(publishErr).
+				Int64("message_id", msg.ID).
+				Str("event_type", msg.EventType).
+				Str("aggregate_id", msg.AggregateID).
+				Int("attempts", msg.Attempts+1).
+				Msg("Failed to publish outbox message")
+
+			_, err = tx.ExecContext(ctx,
+				`UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3 WHERE id = $1`,
+				msg.ID, time.Now().Add(r.backoff(msg.Attempts)), publishErr.Error(),
+			)
+			if err != nil {
+				return pErrors.E(pErrors.Internal, "failed to reschedule outbox message", err)
+			}
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE outbox SET delivered_at = NOW(), last_error = NULL WHERE id = $1`, msg.ID)
+		if err != nil {
+			return pErrors.E(pErrors.Internal, "failed to mark outbox message delivered", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return nil
+}
+
+// backoff doubles the poll interval with every failed attempt, up to MaxBackoff
+func (r *Relay) backoff(attempts int) time.Duration {
+	backoff := r.cfg.PollInterval
+	for i := 0; i < attempts && backoff < r.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, r.cfg.MaxBackoff)
+}
+
+func (r *Relay) cleanup(ctx context.Context) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM outbox WHERE delivered_at < $1`, time.Now().Add(-r.cfg.Retention))
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to delete delivered outbox messages", err)
+	}
+
+	if n, _ := result.RowsAffected(); n > 0 {
+		r.logger.Info().Int64("deleted", n).Msg("Delivered outbox messages cleaned up")
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	grpcServer "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/grpc"
 	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
 	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/inventory/internal/application/usecase"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/inventory/internal/infrastructure/choreography"
 	grpcHandler "github.com/dandirahmadani19/distributed-saga-orchestrator/services/inventory/internal/infrastructure/grpc"
 	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/inventory/internal/infrastructure/repository"
 	"google.golang.org/grpc"
@@ -34,6 +35,16 @@ func main() {
 	handler := grpcHandler.NewInventoryHandler(ucReserve, ucRelease)
 	handler.RegisterInventoryServiceServer(app.GRPC.Instance())
 
+	if err := app.EnableMessaging(); err != nil {
+		app.Log.Fatal().Err(err).Msg("failed to enable messaging")
+	}
+	if app.Cfg.Saga.Mode == config.SagaModeChoreography {
+		if err := app.EnableOutbox(); err != nil {
+			app.Log.Fatal().Err(err).Msg("failed to enable outbox")
+		}
+		choreography.NewInventoryHandler(app.DB, ucReserve, ucRelease, app.Log).Register(app)
+	}
+
 	if err := app.Run(); err != nil {
 		app.Log.Fatal().Err(err).Msg("failed to run app")
 	}
@@ -0,0 +1,118 @@
+package choreography
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/messaging"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/outbox"
+	eventsv1 "github.com/dandirahmadani19/distributed-saga-orchestrator/services/inventory/gen/proto/events/v1"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/inventory/internal/application/dto"
+	"google.golang.org/protobuf/proto"
+)
+
+// Group is the consumer group of the inventory service
+const Group = "inventory-service"
+
+const reservationAggregate = "reservation"
+
+type InventoryReserver interface {
+	Execute(ctx context.Context, req dto.ReserveInventoryRequest) (*dto.ReservationResponse, error)
+}
+type InventoryReleaser interface {
+	Execute(ctx context.Context, req dto.ReleaseInventoryRequest) (*dto.ReservationResponse, error)
+}
+
+// InventoryHandler takes part in the order saga when it runs as a
+// choreography: the items of a new order are reserved, and released when
+// the payment failed. The outcome is stored in the outbox once the use case
+// succeeded; a redelivered event repeats the idempotent use case and stores
+// the outcome again.
+type InventoryHandler struct {
+	db        *sql.DB
+	reserveUC InventoryReserver
+	releaseUC InventoryReleaser
+	logger    *logger.Logger
+}
+
+func NewInventoryHandler(db *sql.DB, reserveUC InventoryReserver, releaseUC InventoryReleaser, log *logger.Logger) *InventoryHandler {
+	return &InventoryHandler{db: db, reserveUC: reserveUC, releaseUC: releaseUC, logger: log}
+}
+
+// Register subscribes the handler to the events it reacts to
+func (h *InventoryHandler) Register(s messaging.Consumer) {
+	s.Subscribe("OrderCreated", Group, h.orderCreated)
+	s.Subscribe("PaymentFailed", Group, h.paymentFailed)
+}
+
+func (h *InventoryHandler) orderCreated(ctx context.Context, msg *messaging.Message) error {
+	var event eventsv1.OrderCreated
+	if err := messaging.Decode(msg, &event); err != nil {
+		return err
+	}
+
+	items := make([]dto.ReserveItemRequest, len(event.Items))
+	for i, item := range event.Items {
+		items[i] = dto.ReserveItemRequest{
+			ProductID: item.ProductId,
+			Quantity:  int(item.Quantity),
+		}
+	}
+
+	reservation, err := h.reserveUC.Execute(ctx, dto.ReserveInventoryRequest{
+		IdempotencyKey: "choreography:reserve:" + event.OrderId,
+		OrderID:        event.OrderId,
+		Items:          items,
+	})
+	if err != nil {
+		if messaging.IsRejected(messaging.RejectPermanent(err)) {
+			h.logger.WarnWithTrace(ctx).
+				Err(err).
+				Str("order_id", event.OrderId).
+				Msg("Inventory reservation failed")
+
+			return h.store(ctx, event.OrderId, &eventsv1.InventoryReservationFailed{
+				OrderId: event.OrderId,
+				Reason:  err.Error(),
+			})
+		}
+		return err
+	}
+
+	// The payment service charges the order from this event alone
+	return h.store(ctx, event.OrderId, &eventsv1.InventoryReserved{
+		OrderId:       event.OrderId,
+		ReservationId: reservation.ID,
+		CustomerId:    event.CustomerId,
+		TotalAmount:   event.TotalAmount,
+	})
+}
+
+func (h *InventoryHandler) paymentFailed(ctx context.Context, msg *messaging.Message) error {
+	var event eventsv1.PaymentFailed
+	if err := messaging.Decode(msg, &event); err != nil {
+		return err
+	}
+
+	reservation, err := h.releaseUC.Execute(ctx, dto.ReleaseInventoryRequest{
+		IdempotencyKey: "choreography:release:" + event.OrderId,
+		OrderID:        event.OrderId,
+	})
+	if err != nil {
+		return messaging.RejectPermanent(err)
+	}
+
+	return h.store(ctx, event.OrderId, &eventsv1.InventoryReleased{
+		OrderId:       event.OrderId,
+		ReservationId: reservation.ID,
+	})
+}
+
+func (h *InventoryHandler) store(ctx context.Context, orderID string, event proto.Message) error {
+	msg, err := outbox.NewEvent(reservationAggregate, orderID, event)
+	if err != nil {
+		return err
+	}
+	return outbox.Store(ctx, h.db, msg)
+}
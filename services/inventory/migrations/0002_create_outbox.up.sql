@@ -0,0 +1,20 @@
+-- Domain events written in the transaction of their change and published
+-- by the outbox relay of platform/outbox
+CREATE TABLE outbox (
+    id              BIGSERIAL PRIMARY KEY,
+    aggregate_type  VARCHAR(100) NOT NULL,
+    aggregate_id    VARCHAR(100) NOT NULL,
+    event_type      VARCHAR(100) NOT NULL,
+    payload         JSONB NOT NULL,
+    headers         JSONB NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempts        INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT,
+    delivered_at    TIMESTAMPTZ
+);
+
+-- Pending messages of an aggregate, in order
+CREATE INDEX idx_outbox_pending ON outbox(aggregate_type, aggregate_id, id) WHERE delivered_at IS NULL;
+-- Cleanup of delivered messages
+CREATE INDEX idx_outbox_delivered ON outbox(delivered_at) WHERE delivered_at IS NOT NULL;
@@ -0,0 +1,61 @@
+// Command diagram prints a saga definition, or a saga colored by the status
+// of its steps, as a Mermaid or Graphviz diagram. It asks the admin service
+// of a running orchestrator, which knows the registered definitions.
+//
+//	go run ./cmd/diagram -type order_saga                 latest definition, Mermaid
+//	go run ./cmd/diagram -type order_saga -version 1      an older version
+//	go run ./cmd/diagram -saga <id> -format dot | dot -Tsvg -o saga.svg
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	pb "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/gen/proto/orchestrator/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:50050", "gRPC address of the orchestrator")
+	sagaID := flag.String("saga", "", "id of the saga to draw")
+	sagaType := flag.String("type", "", "saga type whose definition to draw")
+	version := flag.Int("version", 0, "version of the definition with -type; 0 is the latest")
+	format := flag.String("format", "mermaid", "mermaid or dot")
+	timeout := flag.Duration("timeout", 10*time.Second, "how long to wait for the orchestrator")
+	flag.Parse()
+
+	if (*sagaID == "") == (*sagaType == "") {
+		fmt.Fprintln(os.Stderr, "exactly one of -saga and -type is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to dial %s: %v\n", *addr, err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	resp, err := pb.NewOrchestratorAdminServiceClient(conn).RenderDiagram(ctx, &pb.RenderDiagramRequest{
+		SagaId:   *sagaID,
+		SagaType: *sagaType,
+		Version:  int32(*version),
+		Format:   *format,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to render diagram: %s\n", status.Convert(err).Message())
+		conn.Close()
+		os.Exit(1)
+	}
+
+	fmt.Print(resp.Diagram)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/app"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/config"
+	grpcServer "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/grpc"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/executor"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/lock"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/saga"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/usecase"
+	orchestratorConfig "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/config"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/infrastructure/choreography"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/infrastructure/client"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/infrastructure/event"
+	grpcHandler "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/infrastructure/grpc"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/infrastructure/loader"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/infrastructure/repository"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/infrastructure/rest"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	app, err := app.New(
+		app.WithGRPCOptions(func(cfg *config.Config, log *logger.Logger) []grpc.ServerOption {
+			return []grpc.ServerOption{
+				grpc.ChainUnaryInterceptor(
+					grpcServer.DefaultUnaryInterceptors(log, 5*time.Minute)...,
+				),
+			}
+		}),
+	)
+	if err != nil {
+		log.Fatalf("failed to create app: %v", err)
+	}
+
+	cfg, err := orchestratorConfig.Load()
+	if err != nil {
+		app.Log.Fatal().Err(err).Msg("failed to load orchestrator config")
+	}
+
+	orders, err := client.NewOrderClient(cfg.Services.OrderAddr)
+	if err != nil {
+		app.Log.Fatal().Err(err).Msg("failed to create order client")
+	}
+	defer orders.Close()
+
+	payments, err := client.NewPaymentClient(cfg.Services.PaymentAddr)
+	if err != nil {
+		app.Log.Fatal().Err(err).Msg("failed to create payment client")
+	}
+	defer payments.Close()
+
+	inventory, err := client.NewInventoryClient(cfg.Services.InventoryAddr)
+	if err != nil {
+		app.Log.Fatal().Err(err).Msg("failed to create inventory client")
+	}
+	defer inventory.Close()
+
+	orderSaga, err := saga.OrderSaga(orders, payments, inventory, saga.FraudReview{
+		Threshold: cfg.Approval.Threshold,
+		Expiry:    cfg.Approval.Expiry,
+	})
+	if err != nil {
+		app.Log.Fatal().Err(err).Msg("invalid order saga definition")
+	}
+
+	registry := definition.NewRegistry()
+	if err := registry.Register(orderSaga); err != nil {
+		app.Log.Fatal().Err(err).Msg("failed to register order saga")
+	}
+
+	// The same checkout composed of reusable sub-sagas
+	chargeCustomerSaga, err := saga.ChargeCustomerSaga(payments)
+	if err != nil {
+		app.Log.Fatal().Err(err).Msg("invalid charge customer saga definition")
+	}
+	allocateStockSaga, err := saga.AllocateStockSaga(inventory)
+	if err != nil {
+		app.Log.Fatal().Err(err).Msg("invalid allocate stock saga definition")
+	}
+	checkoutSaga, err := saga.CheckoutSaga(orders)
+	if err != nil {
+		app.Log.Fatal().Err(err).Msg("invalid checkout saga definition")
+	}
+	for _, def := range []*definition.Definition{chargeCustomerSaga, allocateStockSaga, checkoutSaga} {
+		if err := registry.Register(def); err != nil {
+			app.Log.Fatal().Err(err).Str("saga_type", def.Type).Msg("failed to register saga")
+		}
+	}
+
+	// Flows declared in files call their services through reflection
+	if cfg.Definitions.Dir != "" {
+		methods := client.NewReflectionClient()
+		defer methods.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Definitions.ResolveTimeout)
+		defs, err := loader.Load(ctx, cfg.Definitions.Dir, methods)
+		cancel()
+		if err != nil {
+			app.Log.Fatal().Err(err).Str("dir", cfg.Definitions.Dir).Msg("invalid saga definition files")
+		}
+		for _, def := range defs {
+			if err := registry.Register(def); err != nil {
+				app.Log.Fatal().Err(err).Str("saga_type", def.Type).Msg("failed to register saga")
+			}
+			app.Log.Info().Str("saga_type", def.Type).Int("version", def.Version).Msg("saga definition loaded from file")
+		}
+	}
+
+	repo := repository.NewPostgresSagaRepository(app.DB)
+	locks := lock.NewManager(repository.NewPostgresLockRepository(app.DB), cfg.Lock.InstanceID, cfg.Lock.LeaseTTL, app.Log)
+	timers := repository.NewPostgresTimerRepository(app.DB)
+	interventions := repository.NewPostgresInterventionRepository(app.DB)
+	events := repository.NewPostgresEventRepository(app.DB)
+	completions := repository.NewPostgresCompletionRepository(app.DB)
+	signals := repository.NewPostgresSignalRepository(app.DB)
+	approvals := repository.NewPostgresApprovalRepository(app.DB)
+	exec := executor.NewExecutor(repo, timers, completions, signals, approvals, locks, registry, cfg.Worker.StepTimeout, app.Log)
+	poller := executor.NewPendingPoller(repo, exec, cfg.Worker.PollInterval, cfg.Worker.BatchSize, app.Log)
+	sweeper := executor.NewRecoverySweeper(repo, exec, cfg.Worker.RecoveryInterval, cfg.Worker.BatchSize, app.Log)
+	timerPoller := executor.NewTimerPoller(timers, exec, cfg.Worker.TimerInterval, cfg.Worker.BatchSize, app.Log)
+	choreographies := repository.NewPostgresChoreographyRepository(app.DB)
+
+	startUC := usecase.NewStartSagaUseCase(repo, registry, app.Log)
+	getUC := usecase.NewGetSagaUseCase(repo, interventions)
+	listUC := usecase.NewListSagasUseCase(repo)
+	cancelUC := usecase.NewCancelSagaUseCase(repo, registry, app.Log)
+	timelineUC := usecase.NewGetSagaTimelineUseCase(repo, events)
+	choreographyUC := usecase.NewGetChoreographyUseCase(choreographies)
+	completeUC := usecase.NewCompleteStepUseCase(repo, completions, registry, app.Log)
+	signalUC := usecase.NewSignalSagaUseCase(repo, signals, app.Log)
+	listVersionsUC := usecase.NewListDefinitionVersionsUseCase(repo, registry)
+	renderDiagramUC := usecase.NewRenderDiagramUseCase(repo, registry)
+
+	// Sagas run against the definition version they started with: refuse to
+	// start without a version some of them still need
+	versions, err := listVersionsUC.Execute(context.Background())
+	if err != nil {
+		app.Log.Fatal().Err(err).Msg("failed to list definition versions in use")
+	}
+	for _, v := range versions.Versions {
+		if v.Sagas > 0 && !v.Registered && len(registry.Upgrades(v.SagaType, v.Version)) == 0 {
+			app.Log.Fatal().Str("saga_type", v.SagaType).Int("version", v.Version).Int("sagas", v.Sagas).Msg("saga definition version still in use is not registered")
+		}
+	}
+
+	if err := app.EnableMessaging(); err != nil {
+		app.Log.Fatal().Err(err).Msg("failed to enable messaging")
+	}
+	if app.Cfg.Saga.Mode == config.SagaModeChoreography {
+		// The services run the sagas from each other's events, the
+		// orchestrator only observes them
+		orderChoreography := saga.OrderChoreography()
+		if err := orderChoreography.Validate(); err != nil {
+			app.Log.Fatal().Err(err).Msg("invalid order choreography")
+		}
+		observeUC := usecase.NewObserveChoreographyUseCase(choreographies, orderChoreography, app.Log)
+		choreography.NewObserver(observeUC).Register(app, orderChoreography.Events())
+	} else {
+		app.AddWorker(poller.Run)
+		app.AddWorker(sweeper.Run)
+		app.AddWorker(timerPoller.Run)
+		// Without a bus, asynchronous steps are completed by callbacks only
+		if app.Bus != nil {
+			event.NewCompletionHandler(completeUC).Register(app)
+		}
+	}
+
+	handler := grpcHandler.NewOrchestratorHandler(startUC, getUC, listUC, cancelUC, timelineUC, choreographyUC, completeUC, signalUC)
+	handler.RegisterOrchestratorServiceServer(app.GRPC.Instance())
+
+	retryCompensationUC := usecase.NewRetryCompensationUseCase(repo, interventions, locks, app.Log)
+	markCompensatedUC := usecase.NewMarkStepCompensatedUseCase(repo, interventions, locks, app.Log)
+	forceCompleteUC := usecase.NewForceCompleteSagaUseCase(repo, interventions, timers, locks, app.Log)
+	verifyProjectionUC := usecase.NewVerifyProjectionUseCase(repo, events)
+	rebuildProjectionUC := usecase.NewRebuildProjectionUseCase(repo, events, locks, app.Log)
+
+	listApprovalsUC := usecase.NewListApprovalsUseCase(approvals)
+	decideApprovalUC := usecase.NewDecideApprovalUseCase(repo, approvals, app.Log)
+
+	approvalHandler := grpcHandler.NewApprovalHandler(listApprovalsUC, decideApprovalUC)
+	approvalHandler.RegisterOrchestratorApprovalServiceServer(app.GRPC.Instance())
+
+	adminHandler := grpcHandler.NewAdminHandler(retryCompensationUC, markCompensatedUC, forceCompleteUC, verifyProjectionUC, rebuildProjectionUC, listVersionsUC, renderDiagramUC)
+	adminHandler.RegisterOrchestratorAdminServiceServer(app.GRPC.Instance())
+
+	mux := http.NewServeMux()
+	rest.NewSagaHandler(startUC, getUC, listUC, cancelUC, timelineUC, choreographyUC, completeUC, signalUC).Register(mux)
+	rest.NewApprovalHandler(listApprovalsUC, decideApprovalUC).Register(mux)
+	rest.NewAdminHandler(retryCompensationUC, markCompensatedUC, forceCompleteUC, verifyProjectionUC, rebuildProjectionUC, listVersionsUC, renderDiagramUC).Register(mux)
+	if err := app.EnableHTTP(mux); err != nil {
+		app.Log.Fatal().Err(err).Msg("failed to start http server")
+	}
+
+	if err := app.Run(); err != nil {
+		app.Log.Fatal().Err(err).Msg("failed to run app")
+	}
+
+}
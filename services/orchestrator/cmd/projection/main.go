@@ -0,0 +1,115 @@
+// Command projection checks the sagas and saga_steps tables against the saga
+// event log, and repairs them by replaying the events.
+//
+//	go run ./cmd/projection -saga <id>           verify one saga
+//	go run ./cmd/projection -all                 verify every saga
+//	go run ./cmd/projection -all -rebuild        repair every saga that differs
+//
+// It exits with status 1 when a saga differs and was not rebuilt, or failed.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/config"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/postgres"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/lock"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/usecase"
+	orchestratorConfig "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/config"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/infrastructure/repository"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	sagaID := flag.String("saga", "", "id of the saga to check")
+	all := flag.Bool("all", false, "check every saga that has events")
+	rebuild := flag.Bool("rebuild", false, "replace the stored state of the sagas that differ")
+	batch := flag.Int("batch", 100, "sagas read per page with -all")
+	flag.Parse()
+
+	if (*sagaID == "") == !*all {
+		fmt.Fprintln(os.Stderr, "exactly one of -saga and -all is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// The .env file is optional here, the environment may be set already
+	_ = godotenv.Load()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	orchestratorCfg, err := orchestratorConfig.Load()
+	if err != nil {
+		log.Fatalf("failed to load orchestrator config: %v", err)
+	}
+
+	ctx := context.Background()
+	db, err := postgres.NewPostgres(ctx, cfg.Postgres)
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	defer db.Close()
+
+	appLog := logger.New(cfg.App.Name + "-projection")
+	repo := repository.NewPostgresSagaRepository(db)
+	events := repository.NewPostgresEventRepository(db)
+	locks := lock.NewManager(repository.NewPostgresLockRepository(db), orchestratorCfg.Lock.InstanceID, orchestratorCfg.Lock.LeaseTTL, appLog)
+
+	var uc interface {
+		Execute(ctx context.Context, sagaID string) (*dto.ProjectionResponse, error)
+	} = usecase.NewVerifyProjectionUseCase(repo, events)
+	if *rebuild {
+		uc = usecase.NewRebuildProjectionUseCase(repo, events, locks, appLog)
+	}
+
+	ids := []string{*sagaID}
+	failed := 0
+	for after := ""; ; {
+		if *all {
+			ids, err = events.FindSagaIDs(ctx, after, *batch)
+			if err != nil {
+				log.Fatalf("failed to list sagas: %v", err)
+			}
+		}
+
+		for _, id := range ids {
+			result, err := uc.Execute(ctx, id)
+			switch {
+			case err != nil:
+				failed++
+				fmt.Printf("%s\tERROR\t%v\n", id, err)
+			case len(result.Differences) == 0:
+				fmt.Printf("%s\tOK\n", id)
+			default:
+				state := "DIFFERS"
+				if result.Rebuilt {
+					state = "REBUILT"
+				} else {
+					failed++
+				}
+				fmt.Printf("%s\t%s\n", id, state)
+				for _, difference := range result.Differences {
+					fmt.Printf("\t%s\n", difference)
+				}
+			}
+		}
+
+		if !*all || len(ids) < *batch {
+			break
+		}
+		after = ids[len(ids)-1]
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,163 @@
+// Package diagram renders saga definitions, and the sagas running them, as
+// Mermaid or Graphviz (DOT) flowcharts for design reviews and incident calls.
+package diagram
+
+import (
+	"fmt"
+	"strings"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+// Format is the language a diagram is written in
+type Format string
+
+const (
+	FormatMermaid Format = "mermaid"
+	FormatDOT     Format = "dot"
+)
+
+// ParseFormat reads a format name; empty means Mermaid
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", FormatMermaid:
+		return FormatMermaid, nil
+	case FormatDOT, "graphviz":
+		return FormatDOT, nil
+	}
+	return "", pErrors.E(pErrors.Invalid, fmt.Sprintf("unknown diagram format %q, use mermaid or dot", s), nil)
+}
+
+// Render draws the steps of a definition group by group: the steps of a
+// parallel group side by side, each compensation hanging off its step with a
+// dashed edge, the pivot with a double border. When saga is set, each step
+// is colored by its status in that saga.
+func Render(def *definition.Definition, saga *entity.Saga, format Format) (string, error) {
+	g := newGraph(def, saga)
+	switch format {
+	case FormatMermaid:
+		return g.mermaid(), nil
+	case FormatDOT:
+		return g.dot(), nil
+	}
+	return "", pErrors.E(pErrors.Invalid, fmt.Sprintf("unknown diagram format %q", format), nil)
+}
+
+// graph is what both formats draw
+type graph struct {
+	title  string
+	groups [][]node
+}
+
+type node struct {
+	id   string
+	name string
+	// notes describe how the step runs, e.g. that it waits for an approval
+	notes        []string
+	pivot        bool
+	compensation bool
+	// status is only set when a saga is drawn
+	status entity.StepStatus
+}
+
+func newGraph(def *definition.Definition, saga *entity.Saga) *graph {
+	g := &graph{title: fmt.Sprintf("%s v%d", def.Type, def.Version)}
+	if saga != nil {
+		g.title += fmt.Sprintf(" - saga %s %s", saga.ID, saga.Status)
+	}
+
+	for _, indexes := range def.Groups() {
+		group := make([]node, len(indexes))
+		for i, index := range indexes {
+			step := def.Steps[index]
+			n := node{
+				id:           "step_" + identifier(step.Name),
+				name:         step.Name,
+				notes:        notes(step),
+				pivot:        step.Pivot,
+				compensation: step.HasCompensation(),
+			}
+			if saga != nil {
+				// Steps are only materialized once the saga runs
+				n.status = entity.StepStatusPending
+				if s, ok := saga.Step(step.Name); ok {
+					n.status = s.Status
+				}
+			}
+			group[i] = n
+		}
+		g.groups = append(g.groups, group)
+	}
+	return g
+}
+
+func notes(step definition.Step) []string {
+	var notes []string
+	switch {
+	case step.AwaitsSignal():
+		notes = append(notes, fmt.Sprintf("waits for signal %s, %s", step.Signal, step.Wait))
+	case step.AwaitsApproval():
+		notes = append(notes, fmt.Sprintf("approval, expires after %s", step.Wait))
+	case step.StartsSubSaga():
+		notes = append(notes, fmt.Sprintf("sub-saga %s", step.SubSaga))
+	case step.IsAsync():
+		notes = append(notes, fmt.Sprintf("async, completes within %s", step.Wait))
+	}
+	if step.When != nil {
+		notes = append(notes, "conditional")
+	}
+	if step.Pivot {
+		notes = append(notes, "pivot")
+	}
+	return notes
+}
+
+// identifier turns a step name into a node id both languages accept
+func identifier(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// style is how a step status is colored
+type style struct {
+	class, fill, stroke string
+}
+
+func styleOf(status entity.StepStatus) style {
+	switch status {
+	case entity.StepStatusSucceeded:
+		return style{"succeeded", "#c8e6c9", "#2e7d32"}
+	case entity.StepStatusExecuting:
+		return style{"executing", "#bbdefb", "#1565c0"}
+	case entity.StepStatusWaiting:
+		return style{"waiting", "#fff9c4", "#f9a825"}
+	case entity.StepStatusFailed, entity.StepStatusTimedOut, entity.StepStatusCompensationFailed:
+		return style{"failed", "#ffcdd2", "#c62828"}
+	case entity.StepStatusCompensating:
+		return style{"compensating", "#ffe0b2", "#ef6c00"}
+	case entity.StepStatusCompensated:
+		return style{"compensated", "#e0e0e0", "#616161"}
+	case entity.StepStatusSkipped:
+		return style{"skipped", "#f5f5f5", "#bdbdbd"}
+	default:
+		return style{"pending", "#ffffff", "#9e9e9e"}
+	}
+}
+
+// label is the text of a step node, one line per entry
+func (n node) label() []string {
+	lines := append([]string{n.name}, n.notes...)
+	if n.status != "" {
+		lines = append(lines, string(n.status))
+	}
+	return lines
+}
@@ -0,0 +1,223 @@
+package diagram
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+func noop(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	return request, nil
+}
+
+func emptyRequest(state definition.State) (json.RawMessage, error) {
+	return json.RawMessage(`{}`), nil
+}
+
+// orderSaga has a step, a parallel group holding the pivot and an async
+// step, then a conditional step
+func orderSaga(t *testing.T) *definition.Definition {
+	t.Helper()
+
+	b := definition.New("order_saga").Version(2)
+	b.Step("create_order").Action(noop, emptyRequest).Compensation(noop, emptyRequest)
+	g := b.Parallel()
+	g.Step("process_payment").Action(noop, emptyRequest).Compensation(noop, emptyRequest).Pivot()
+	g.Step("reserve_inventory").Action(noop, emptyRequest).Async(time.Minute)
+	b.Step("confirm_order").Action(noop, emptyRequest).When(definition.Exists("payload.confirm"))
+
+	def, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	return def
+}
+
+func TestRenderDefinition(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			format: FormatMermaid,
+			want: `---
+title: order_saga v2
+---
+flowchart LR
+    start([start])
+    done([done])
+    step_create_order["create_order"]
+    subgraph group_2 ["parallel"]
+        direction TB
+        step_process_payment[["process_payment<br/>pivot"]]
+        step_reserve_inventory["reserve_inventory<br/>async, completes within 1m0s"]
+    end
+    step_confirm_order["confirm_order<br/>conditional"]
+    step_create_order_undo["compensate create_order"]:::compensation
+    step_create_order -. undo .-> step_create_order_undo
+    step_process_payment_undo["compensate process_payment"]:::compensation
+    step_process_payment -. undo .-> step_process_payment_undo
+    start --> step_create_order
+    step_create_order --> step_process_payment
+    step_create_order --> step_reserve_inventory
+    step_process_payment --> step_confirm_order
+    step_reserve_inventory --> step_confirm_order
+    step_confirm_order --> done
+    classDef compensation stroke-dasharray: 5 5
+`,
+		},
+		{
+			format: FormatDOT,
+			want: `digraph saga {
+    label="order_saga v2";
+    labelloc=t;
+    rankdir=LR;
+    node [shape=box, style="rounded,filled", fillcolor="#ffffff", fontname="Helvetica"];
+    start [label="start", shape=circle];
+    done [label="done", shape=doublecircle];
+    step_create_order [label="create_order"];
+    subgraph cluster_group_2 {
+        label="parallel";
+        style=dashed;
+        step_process_payment [label="process_payment\npivot", peripheries=2];
+        step_reserve_inventory [label="reserve_inventory\nasync, completes within 1m0s"];
+    }
+    step_confirm_order [label="confirm_order\nconditional"];
+    step_create_order_undo [label="compensate create_order", style="rounded,dashed"];
+    step_create_order -> step_create_order_undo [style=dashed, label="undo"];
+    step_process_payment_undo [label="compensate process_payment", style="rounded,dashed"];
+    step_process_payment -> step_process_payment_undo [style=dashed, label="undo"];
+    start -> step_create_order;
+    step_create_order -> step_process_payment;
+    step_create_order -> step_reserve_inventory;
+    step_process_payment -> step_confirm_order;
+    step_reserve_inventory -> step_confirm_order;
+    step_confirm_order -> done;
+}
+`,
+		},
+	}
+
+	def := orderSaga(t)
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			got, err := Render(def, nil, tt.format)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSaga(t *testing.T) {
+	saga := &entity.Saga{
+		ID:     "saga-1",
+		Status: entity.SagaStatusCompensating,
+		Steps: []*entity.SagaStep{
+			{Name: "create_order", Status: entity.StepStatusCompensating},
+			{Name: "process_payment", Status: entity.StepStatusFailed},
+			{Name: "reserve_inventory", Status: entity.StepStatusCompensated},
+			// confirm_order was never materialized
+		},
+	}
+
+	tests := []struct {
+		format Format
+		want   []string
+	}{
+		{
+			format: FormatMermaid,
+			want: []string{
+				"title: order_saga v2 - saga saga-1 COMPENSATING",
+				`step_create_order["create_order<br/>COMPENSATING"]`,
+				`step_process_payment[["process_payment<br/>pivot<br/>FAILED"]]`,
+				`step_confirm_order["confirm_order<br/>conditional<br/>PENDING"]`,
+				"classDef compensated fill:#e0e0e0,stroke:#616161\n    class step_reserve_inventory compensated",
+				"classDef compensating fill:#ffe0b2,stroke:#ef6c00\n    class step_create_order compensating",
+				"classDef failed fill:#ffcdd2,stroke:#c62828\n    class step_process_payment failed",
+				"classDef pending fill:#ffffff,stroke:#9e9e9e\n    class step_confirm_order pending",
+			},
+		},
+		{
+			format: FormatDOT,
+			want: []string{
+				`label="order_saga v2 - saga saga-1 COMPENSATING";`,
+				`step_create_order [label="create_order\nCOMPENSATING", fillcolor="#ffe0b2", color="#ef6c00"];`,
+				`step_process_payment [label="process_payment\npivot\nFAILED", peripheries=2, fillcolor="#ffcdd2", color="#c62828"];`,
+				`step_reserve_inventory [label="reserve_inventory\nasync, completes within 1m0s\nCOMPENSATED", fillcolor="#e0e0e0", color="#616161"];`,
+				`step_confirm_order [label="confirm_order\nconditional\nPENDING", fillcolor="#ffffff", color="#9e9e9e"];`,
+			},
+		},
+	}
+
+	def := orderSaga(t)
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			got, err := Render(def, saga, tt.format)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Render() misses %q in\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderEscapes(t *testing.T) {
+	b := definition.New("gift saga")
+	b.Step(`wrap "gift"`).Action(noop, emptyRequest)
+	def, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatMermaid, `step_wrap__gift_["wrap #quot;gift#quot;"]`},
+		{FormatDOT, `step_wrap__gift_ [label="wrap \"gift\""];`},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			got, err := Render(def, nil, tt.format)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Render() misses %q in\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatMermaid, false},
+		{"mermaid", FormatMermaid, false},
+		{"DOT", FormatDOT, false},
+		{"graphviz", FormatDOT, false},
+		{"svg", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package diagram
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dot writes the graph in the Graphviz DOT language
+func (g *graph) dot() string {
+	var b strings.Builder
+	b.WriteString("digraph saga {\n")
+	fmt.Fprintf(&b, "    label=%s;\n", dotString(g.title))
+	b.WriteString("    labelloc=t;\n")
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\", fontname=\"Helvetica\"];\n")
+	b.WriteString("    start [label=\"start\", shape=circle];\n")
+	b.WriteString("    done [label=\"done\", shape=doublecircle];\n")
+
+	for i, group := range g.groups {
+		indent := "    "
+		if len(group) > 1 {
+			fmt.Fprintf(&b, "    subgraph cluster_group_%d {\n", i+1)
+			b.WriteString("        label=\"parallel\";\n")
+			b.WriteString("        style=dashed;\n")
+			indent = "        "
+		}
+		for _, n := range group {
+			attrs := []string{"label=" + dotString(strings.Join(n.label(), "\n"))}
+			if n.pivot {
+				attrs = append(attrs, "peripheries=2")
+			}
+			if n.status != "" {
+				s := styleOf(n.status)
+				attrs = append(attrs, fmt.Sprintf("fillcolor=%q", s.fill), fmt.Sprintf("color=%q", s.stroke))
+			}
+			fmt.Fprintf(&b, "%s%s [%s];\n", indent, n.id, strings.Join(attrs, ", "))
+		}
+		if len(group) > 1 {
+			b.WriteString("    }\n")
+		}
+	}
+
+	for _, group := range g.groups {
+		for _, n := range group {
+			if n.compensation {
+				fmt.Fprintf(&b, "    %s_undo [label=%s, style=\"rounded,dashed\"];\n", n.id, dotString("compensate "+n.name))
+				fmt.Fprintf(&b, "    %s -> %s_undo [style=dashed, label=\"undo\"];\n", n.id, n.id)
+			}
+		}
+	}
+
+	previous := []string{"start"}
+	for _, group := range g.groups {
+		var ids []string
+		for _, n := range group {
+			ids = append(ids, n.id)
+			for _, from := range previous {
+				fmt.Fprintf(&b, "    %s -> %s;\n", from, n.id)
+			}
+		}
+		previous = ids
+	}
+	for _, from := range previous {
+		fmt.Fprintf(&b, "    %s -> done;\n", from)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotString quotes a string; line breaks become DOT line breaks
+func dotString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
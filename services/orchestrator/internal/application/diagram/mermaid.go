@@ -0,0 +1,87 @@
+package diagram
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// mermaid writes the graph as a Mermaid flowchart
+func (g *graph) mermaid() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\ntitle: %s\n---\n", g.title)
+	b.WriteString("flowchart LR\n")
+	b.WriteString("    start([start])\n")
+	b.WriteString("    done([done])\n")
+
+	classes := make(map[style][]string)
+	for i, group := range g.groups {
+		indent := "    "
+		if len(group) > 1 {
+			fmt.Fprintf(&b, "    subgraph group_%d [\"parallel\"]\n", i+1)
+			b.WriteString("        direction TB\n")
+			indent = "        "
+		}
+		for _, n := range group {
+			text := mermaidText(n.label())
+			if n.pivot {
+				// The subroutine shape has a double border
+				fmt.Fprintf(&b, "%s%s[[\"%s\"]]\n", indent, n.id, text)
+			} else {
+				fmt.Fprintf(&b, "%s%s[\"%s\"]\n", indent, n.id, text)
+			}
+			if n.status != "" {
+				s := styleOf(n.status)
+				classes[s] = append(classes[s], n.id)
+			}
+		}
+		if len(group) > 1 {
+			b.WriteString("    end\n")
+		}
+	}
+
+	// Compensations are drawn outside the groups, below their steps
+	for _, group := range g.groups {
+		for _, n := range group {
+			if n.compensation {
+				fmt.Fprintf(&b, "    %s_undo[\"%s\"]:::compensation\n", n.id, mermaidText([]string{"compensate " + n.name}))
+				fmt.Fprintf(&b, "    %s -. undo .-> %s_undo\n", n.id, n.id)
+			}
+		}
+	}
+
+	previous := []string{"start"}
+	for _, group := range g.groups {
+		var ids []string
+		for _, n := range group {
+			ids = append(ids, n.id)
+			for _, from := range previous {
+				fmt.Fprintf(&b, "    %s --> %s\n", from, n.id)
+			}
+		}
+		previous = ids
+	}
+	for _, from := range previous {
+		fmt.Fprintf(&b, "    %s --> done\n", from)
+	}
+
+	b.WriteString("    classDef compensation stroke-dasharray: 5 5\n")
+	styles := make([]style, 0, len(classes))
+	for s := range classes {
+		styles = append(styles, s)
+	}
+	sort.Slice(styles, func(i, j int) bool { return styles[i].class < styles[j].class })
+	for _, s := range styles {
+		fmt.Fprintf(&b, "    classDef %s fill:%s,stroke:%s\n", s.class, s.fill, s.stroke)
+		fmt.Fprintf(&b, "    class %s %s\n", strings.Join(classes[s], ","), s.class)
+	}
+	return b.String()
+}
+
+// mermaidText joins label lines; quotes are written as entities
+func mermaidText(lines []string) string {
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(line, `"`, "#quot;")
+	}
+	return strings.Join(lines, "<br/>")
+}
@@ -0,0 +1,248 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StartSagaRequest is the input for starting a saga
+type StartSagaRequest struct {
+	IdempotencyKey string
+	SagaType       string
+	Payload        json.RawMessage
+}
+
+// ListSagasRequest is the input for listing sagas
+type ListSagasRequest struct {
+	SagaType  string
+	Status    string
+	PageSize  int
+	PageToken string
+}
+
+// ListSagasResponse is one page of sagas, newest first
+type ListSagasResponse struct {
+	Sagas         []SagaResponse
+	NextPageToken string
+}
+
+// InterventionRequest is the input of an operator action on a saga.
+// StepName is only used by actions on one step.
+type InterventionRequest struct {
+	SagaID     string
+	StepName   string
+	OperatorID string
+	Note       string
+}
+
+// SagaResponse is the output after starting/fetching a saga.
+// Steps and interventions are only filled in when a single saga is fetched.
+type SagaResponse struct {
+	ID            string
+	SagaType      string
+	Status        string
+	Payload       json.RawMessage
+	ErrorMessage  string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CompletedAt   *time.Time
+	Steps         []SagaStepDTO
+	Interventions []InterventionDTO
+	// Set on a sub-saga
+	ParentSagaID string
+	ParentStep   string
+	// The version of its definition the saga runs against
+	DefinitionVersion int
+}
+
+// SagaStepDTO represents a saga step in DTOs
+type SagaStepDTO struct {
+	Name          string
+	Order         int
+	Group         int
+	Status        string
+	ErrorMessage  string
+	RetryCount    int
+	Request       json.RawMessage
+	Response      json.RawMessage
+	ExecutedAt    *time.Time
+	CompensatedAt *time.Time
+	WaitUntil     *time.Time
+}
+
+// InterventionDTO is one entry of the audit trail of operator actions
+type InterventionDTO struct {
+	StepName       string
+	Action         string
+	OperatorID     string
+	Note           string
+	PreviousStatus string
+	CreatedAt      time.Time
+}
+
+// SagaTimelineResponse is the event log of a saga, oldest first
+type SagaTimelineResponse struct {
+	SagaID string
+	Events []SagaEventDTO
+}
+
+// SagaEventDTO is one transition of a saga or of one of its steps.
+// Data is the state the transition produced.
+type SagaEventDTO struct {
+	Sequence  int64
+	StepName  string
+	Type      string
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
+
+// ProjectionResponse compares the stored state of a saga with the state
+// rebuilt from its events. Differences is empty when they match.
+type ProjectionResponse struct {
+	Saga        *SagaResponse
+	Differences []string
+	Rebuilt     bool
+}
+
+// DefinitionVersionDTO is one registered or referenced version of a saga
+// definition. A version that is not registered but still has sagas running
+// against it needs to be registered again.
+type DefinitionVersionDTO struct {
+	SagaType   string
+	Version    int
+	Registered bool
+	// Latest is the version new sagas start on
+	Latest bool
+	// Sagas counts the sagas that may still run against the version: those
+	// not finished, and the completed sub-sagas of those
+	Sagas int
+}
+
+// ListDefinitionVersionsResponse lists the versions of every saga type, by
+// type then version
+type ListDefinitionVersionsResponse struct {
+	Versions []DefinitionVersionDTO
+}
+
+// RenderDiagramRequest selects what to draw: a saga, colored by the status
+// of its steps, or the definition of a saga type, its latest version when
+// Version is 0. Format is mermaid (the default) or dot.
+type RenderDiagramRequest struct {
+	SagaID   string
+	SagaType string
+	Version  int
+	Format   string
+}
+
+// DiagramResponse is a diagram in the format it was rendered in
+type DiagramResponse struct {
+	Format  string
+	Diagram string
+}
+
+// ChoreographyEventRequest is a domain event observed on the bus
+type ChoreographyEventRequest struct {
+	CorrelationID string
+	Type          string
+	MessageID     string
+	Payload       json.RawMessage
+	PublishedAt   time.Time
+}
+
+// ChoreographyResponse is the observed progress of a choreographed saga
+type ChoreographyResponse struct {
+	CorrelationID string
+	SagaType      string
+	Status        string
+	ErrorMessage  string
+	StartedAt     time.Time
+	UpdatedAt     time.Time
+	CompletedAt   *time.Time
+	Events        []ChoreographyEventDTO
+}
+
+// ChoreographyEventDTO is one domain event of a choreographed saga
+type ChoreographyEventDTO struct {
+	Type        string
+	MessageID   string
+	Payload     json.RawMessage
+	PublishedAt time.Time
+	ReceivedAt  time.Time
+}
+
+// Where the completion of a step comes from
+const (
+	CompletionSourceCallback = "callback"
+	CompletionSourceEvent    = "event"
+)
+
+// CompleteStepRequest reports the outcome of the command of an asynchronous
+// step. ErrorMessage is set when the command failed.
+type CompleteStepRequest struct {
+	SagaID       string
+	StepName     string
+	Payload      json.RawMessage
+	ErrorMessage string
+	Source       string
+}
+
+// CompleteStepResponse tells whether the completion was recorded or the
+// step already had one
+type CompleteStepResponse struct {
+	SagaID    string
+	StepName  string
+	Duplicate bool
+}
+
+// SignalSagaRequest pushes data into a running saga. Deliveries with the
+// same idempotency key are deduplicated.
+type SignalSagaRequest struct {
+	SagaID         string
+	SignalName     string
+	IdempotencyKey string
+	Payload        json.RawMessage
+}
+
+// SignalSagaResponse tells whether the signal was recorded or had already
+// been delivered
+type SignalSagaResponse struct {
+	SagaID     string
+	SignalName string
+	ReceivedAt time.Time
+	Duplicate  bool
+}
+
+// ListApprovalsRequest is the input for listing the pending approvals
+type ListApprovalsRequest struct {
+	SagaType string
+	PageSize int
+}
+
+// ListApprovalsResponse is the work queue of approvers, oldest request first
+type ListApprovalsResponse struct {
+	Approvals []ApprovalDTO
+}
+
+// DecideApprovalRequest approves or rejects an approval step
+type DecideApprovalRequest struct {
+	SagaID     string
+	StepName   string
+	Approved   bool
+	ApproverID string
+	Comment    string
+}
+
+// ApprovalDTO is the approval requested by a step. The saga type and payload
+// are only set in the work queue.
+type ApprovalDTO struct {
+	SagaID      string
+	StepName    string
+	SagaType    string
+	SagaPayload json.RawMessage
+	Status      string
+	ApproverID  string
+	Comment     string
+	RequestedAt time.Time
+	ExpiresAt   time.Time
+	DecidedAt   *time.Time
+}
@@ -0,0 +1,1185 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/lock"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// Executor drives one saga forward group by group, the steps of a group
+// concurrently, and when a step fails, compensates the steps that already
+// succeeded in reverse order.
+// Every transition is persisted before the next call is made, so a saga can
+// be resumed from its last persisted step status.
+// Waits are never held in memory: a saga that has to wait is parked, its
+// lease released, and a durable timer resumes it. This includes asynchronous
+// steps waiting for their completion, for a signal, an approval or a sub-saga.
+type Executor struct {
+	repo        repository.SagaRepository
+	timers      repository.TimerRepository
+	completions repository.CompletionRepository
+	signals     repository.SignalRepository
+	approvals   repository.ApprovalRepository
+	locks       *lock.Manager
+	registry    *definition.Registry
+	stepTimeout time.Duration
+	logger      *logger.Logger
+}
+
+// NewExecutor creates an executor for the saga types known to the registry
+func NewExecutor(repo repository.SagaRepository, timers repository.TimerRepository, completions repository.CompletionRepository, signals repository.SignalRepository, approvals repository.ApprovalRepository, locks *lock.Manager, registry *definition.Registry, stepTimeout time.Duration, log *logger.Logger) *Executor {
+	return &Executor{
+		repo:        repo,
+		timers:      timers,
+		completions: completions,
+		signals:     signals,
+		approvals:   approvals,
+		locks:       locks,
+		registry:    registry,
+		stepTimeout: stepTimeout,
+		logger:      log,
+	}
+}
+
+// run is one execution of a saga under a lease. All writes are fenced with it.
+type run struct {
+	*Executor
+	lease  *entity.Lease
+	saga   *entity.Saga
+	steps  []definition.Step
+	groups [][]int
+	pivot  int
+}
+
+// errParked ends a run that waits for a timer. It is not an error for Run.
+var errParked = errors.New("saga parked until a timer fires")
+
+// failedError is the error of a step that failed for good, e.g. a call that
+// failed with no retry left. Any other error is one of the executor itself,
+// such as a lost lease, a shutdown or errParked.
+type failedError struct {
+	cause error
+}
+
+func (e *failedError) Error() string { return e.cause.Error() }
+func (e *failedError) Unwrap() error { return e.cause }
+
+// asFailed returns the cause of the error of a step that failed for good
+func asFailed(err error) (error, bool) {
+	var f *failedError
+	if errors.As(err, &f) {
+		return f.cause, true
+	}
+	return nil, false
+}
+
+// childPollInterval is how often a saga compensating a sub-saga looks at it
+// again when no wake up came
+const childPollInterval = time.Minute
+
+// Run executes or compensates the saga until it reaches a terminal status.
+// It does nothing when another instance holds the saga lease.
+func (e *Executor) Run(ctx context.Context, sagaID string) error {
+	lease, err := e.locks.Acquire(ctx, sagaID)
+	if err != nil {
+		return err
+	}
+	if lease == nil {
+		return nil
+	}
+	defer e.locks.Release(lease)
+
+	ctx, stop := e.locks.KeepAlive(ctx, lease)
+	defer stop()
+
+	// Read the saga only once the lease is held, so the state is not stale
+	saga, err := e.repo.FindByID(ctx, sagaID)
+	if err != nil {
+		return err
+	}
+
+	r := &run{Executor: e, lease: lease, saga: saga}
+
+	if _, err := e.registry.Get(saga.Type); err != nil {
+		saga.Fail(err.Error())
+		return e.repo.UpdateSaga(ctx, lease, saga)
+	}
+
+	// An unregistered version is a deployment mistake: the saga is left as
+	// is and resumes once its version is registered again
+	def, err := r.definition(ctx)
+	if err != nil {
+		return err
+	}
+	r.steps = def.Steps
+	r.groups = def.Groups()
+	r.pivot = def.PivotGroup()
+
+	// Steps are materialized the first time the saga is picked up
+	if len(saga.Steps) == 0 {
+		for i, step := range r.steps {
+			saga.Steps = append(saga.Steps, entity.NewSagaStep(saga.ID, step.Name, i+1, step.Group))
+		}
+		if err := e.repo.CreateSteps(ctx, lease, saga.Steps); err != nil {
+			return err
+		}
+	}
+
+	if len(saga.Steps) != len(r.steps) {
+		saga.Fail(fmt.Sprintf("saga has %d steps, definition %s has %d", len(saga.Steps), saga.Type, len(r.steps)))
+		return e.repo.UpdateSaga(ctx, lease, saga)
+	}
+
+	// Scheduled on every run of a running saga: the first schedule is kept,
+	// so a crash between starting the saga and scheduling cannot lose it
+	if def.Deadline > 0 && (saga.Status == entity.SagaStatusPending || saga.Status == entity.SagaStatusExecuting) {
+		timer := entity.NewSagaTimer(saga.ID, entity.TimerKindSagaDeadline, saga.CreatedAt.Add(def.Deadline))
+		if err := e.timers.Schedule(ctx, timer); err != nil {
+			return err
+		}
+	}
+
+	switch saga.Status {
+	case entity.SagaStatusPending:
+		saga.Start()
+		if err := e.repo.UpdateSaga(ctx, lease, saga); err != nil {
+			return err
+		}
+		return r.finish(ctx, r.execute(ctx))
+	case entity.SagaStatusExecuting, entity.SagaStatusForwardRecovering:
+		return r.finish(ctx, r.execute(ctx))
+	case entity.SagaStatusCompensating:
+		return r.finish(ctx, r.compensate(ctx))
+	default:
+		return nil
+	}
+}
+
+// definition returns the definition the saga runs against: the version it
+// was created with, or the latest version the later ones migrate it to
+func (r *run) definition(ctx context.Context) (*definition.Definition, error) {
+	upgrades := r.registry.Upgrades(r.saga.Type, r.saga.DefinitionVersion)
+	if len(upgrades) > 0 && !r.saga.IsTerminal() {
+		if err := r.upgrade(ctx, upgrades); err != nil {
+			return nil, err
+		}
+	}
+	return r.registry.GetVersion(r.saga.Type, r.saga.DefinitionVersion)
+}
+
+// upgrade migrates the saga one version at a time. It stops at a version
+// that dropped a step which may have taken effect, or whose migration
+// fails: the saga keeps running against the version it reached.
+func (r *run) upgrade(ctx context.Context, upgrades []*definition.Definition) error {
+	for _, next := range upgrades {
+		steps, payload, err := r.migrate(next)
+		if err != nil {
+			r.logger.WarnWithTrace(ctx).
+				Err(err).
+				Str("saga_id", r.saga.ID).
+				Int("version", r.saga.DefinitionVersion).
+				Int("target_version", next.Version).
+				Msg("Saga not upgraded")
+			return nil
+		}
+
+		from := r.saga.DefinitionVersion
+		r.saga.Upgrade(next.Version, payload, steps)
+		if err := r.repo.Upgrade(ctx, r.lease, r.saga, from); err != nil {
+			return err
+		}
+
+		r.logger.InfoWithTrace(ctx).
+			Str("saga_id", r.saga.ID).
+			Str("saga_type", r.saga.Type).
+			Int("from_version", from).
+			Int("version", next.Version).
+			Msg("Saga upgraded")
+	}
+	return nil
+}
+
+// migrate returns the steps and payload of the saga under the next version.
+// Steps are matched by name and laid out in the order of the new version;
+// a step the saga does not have yet starts PENDING.
+func (r *run) migrate(next *definition.Definition) ([]*entity.SagaStep, json.RawMessage, error) {
+	for _, step := range r.saga.Steps {
+		if _, ok := next.Step(step.Name); !ok && step.Status != entity.StepStatusPending && step.Status != entity.StepStatusSkipped {
+			return nil, nil, fmt.Errorf("version %d drops step %s, which is %s", next.Version, step.Name, step.Status)
+		}
+	}
+
+	payload, err := next.Migrate(r.saga.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &object); err != nil || object == nil {
+		return nil, nil, fmt.Errorf("migrated payload is not a JSON object")
+	}
+
+	// Steps are only materialized once the saga runs
+	if len(r.saga.Steps) == 0 {
+		return nil, payload, nil
+	}
+
+	steps := make([]*entity.SagaStep, len(next.Steps))
+	for i, s := range next.Steps {
+		step, ok := r.saga.Step(s.Name)
+		if !ok {
+			step = entity.NewSagaStep(r.saga.ID, s.Name, i+1, s.Group)
+		}
+		step.Order = i + 1
+		step.Group = s.Group
+		steps[i] = step
+	}
+	return steps, payload, nil
+}
+
+// finish ends a run. A parked saga is resumed by its timer; the timers of a
+// finished saga are not needed any more.
+func (r *run) finish(ctx context.Context, err error) error {
+	if errors.Is(err, errParked) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if r.saga.IsTerminal() {
+		return r.timers.DeleteBySaga(ctx, r.saga.ID)
+	}
+	return nil
+}
+
+func (r *run) execute(ctx context.Context) error {
+	for _, group := range r.groups {
+		proceed, err := r.executeGroup(ctx, group)
+		if err != nil || !proceed {
+			return err
+		}
+	}
+
+	r.saga.Complete()
+	if err := r.repo.UpdateSaga(ctx, r.lease, r.saga); err != nil {
+		return err
+	}
+
+	r.logger.InfoWithTrace(ctx).
+		Str("saga_id", r.saga.ID).
+		Str("saga_type", r.saga.Type).
+		Msg("Saga completed")
+
+	return nil
+}
+
+// stepCall is one forward call of a group, sent concurrently with its siblings
+type stepCall struct {
+	step     *entity.SagaStep
+	def      definition.Step
+	request  json.RawMessage
+	response json.RawMessage
+	err      error
+}
+
+// stepFailure is a step whose forward call failed for good. A step that
+// timed out waiting for its completion is compensated.
+type stepFailure struct {
+	step     *entity.SagaStep
+	cause    error
+	timedOut bool
+}
+
+// executeGroup sends the calls of one group concurrently and waits for all of
+// them. It reports false when the saga does not proceed to the next group:
+// it was parked, interrupted or switched to compensation.
+// Past the pivot, failed steps are retried forward instead of compensated.
+func (r *run) executeGroup(ctx context.Context, group []int) (bool, error) {
+	var pending []int
+	for _, i := range group {
+		if !r.saga.Steps[i].IsDone() {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return true, nil
+	}
+
+	forward := r.irreversible()
+
+	// A cancellation or deadline is honoured only while no call of the
+	// group is in flight, otherwise its effect could not be compensated.
+	// Past the pivot the saga cannot be compensated at all.
+	if !forward && !r.inFlight(pending) {
+		reason, cancelled, err := r.repo.FindCancelRequest(ctx, r.saga.ID)
+		if err != nil {
+			return false, err
+		}
+		if cancelled {
+			return false, r.cancel(ctx, r.waiting(pending), reason)
+		}
+	}
+
+	// Requests are built one by one before any call is sent: they read the
+	// saga state, which the calls update
+	var calls []*stepCall
+	var failures []stepFailure
+	parked := false
+	for _, i := range pending {
+		step, def := r.saga.Steps[i], r.steps[i]
+
+		if step.Status == entity.StepStatusPending && def.When != nil {
+			holds, err := def.When.Evaluate(r.state())
+			if err != nil {
+				failures = append(failures, stepFailure{step: step, cause: err})
+				continue
+			}
+			if !holds {
+				step.Skip()
+				if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+					return false, err
+				}
+
+				r.logger.InfoWithTrace(ctx).
+					Str("saga_id", r.saga.ID).
+					Str("step", step.Name).
+					Msg("Step skipped")
+				continue
+			}
+		}
+
+		if step.Status == entity.StepStatusWaiting && step.NextRetryAt == nil {
+			failure, err := r.await(ctx, step, def)
+			switch {
+			case errors.Is(err, errParked):
+				parked = true
+			case err != nil:
+				return false, err
+			case failure != nil:
+				failures = append(failures, *failure)
+			}
+			continue
+		}
+
+		if step.RetryPending() {
+			if err := r.scheduleRetry(ctx, step); err != nil {
+				return false, err
+			}
+			parked = true
+			continue
+		}
+
+		if step.Status == entity.StepStatusPending && def.Deadline > 0 {
+			timer := entity.NewStepTimer(step, entity.TimerKindStepDeadline, time.Now().Add(def.Deadline))
+			if err := r.timers.Schedule(ctx, timer); err != nil {
+				return false, err
+			}
+		}
+
+		if !def.HasAction() {
+			// The request of a sub-saga step is the payload of the child
+			var request json.RawMessage
+			if def.StartsSubSaga() {
+				var err error
+				request, err = r.request(step, def)
+				if err != nil {
+					failures = append(failures, stepFailure{step: step, cause: err})
+					continue
+				}
+			}
+
+			step.Start(request)
+			step.Wait(nil, time.Now().Add(def.Wait))
+			if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+				return false, err
+			}
+
+			switch {
+			case def.AwaitsApproval():
+				r.logger.InfoWithTrace(ctx).
+					Str("saga_id", r.saga.ID).
+					Str("step", step.Name).
+					Dur("expiry", def.Wait).
+					Msg("Step waiting for approval")
+			case def.StartsSubSaga():
+				r.logger.InfoWithTrace(ctx).
+					Str("saga_id", r.saga.ID).
+					Str("step", step.Name).
+					Str("sub_saga_type", def.SubSaga).
+					Dur("timeout", def.Wait).
+					Msg("Step waiting for sub-saga")
+			default:
+				r.logger.InfoWithTrace(ctx).
+					Str("saga_id", r.saga.ID).
+					Str("step", step.Name).
+					Str("signal", def.Signal).
+					Dur("timeout", def.Wait).
+					Msg("Step waiting for signal")
+			}
+
+			failure, err := r.await(ctx, step, def)
+			switch {
+			case errors.Is(err, errParked):
+				parked = true
+			case err != nil:
+				return false, err
+			case failure != nil:
+				failures = append(failures, *failure)
+			}
+			continue
+		}
+
+		request, err := r.request(step, def)
+		if err != nil {
+			failures = append(failures, stepFailure{step: step, cause: err})
+			continue
+		}
+
+		step.Start(request)
+		if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+			return false, err
+		}
+
+		calls = append(calls, &stepCall{step: step, def: def, request: request})
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range calls {
+		wg.Add(1)
+		go func(c *stepCall) {
+			defer wg.Done()
+			c.response, c.err = r.attempt(ctx, c.step, c.def.Retry, c.def.Action, c.step.IdempotencyKey, c.request)
+		}(c)
+	}
+	wg.Wait()
+
+	// Record every outcome before acting on any of them, so the succeeded
+	// siblings of a failed step are known to the compensation
+	var interrupted error
+	for _, c := range calls {
+		cause, failed := asFailed(c.err)
+		switch {
+		case failed:
+			failures = append(failures, stepFailure{step: c.step, cause: cause})
+		case errors.Is(c.err, errParked):
+			parked = true
+		case c.err != nil:
+			if interrupted == nil {
+				interrupted = c.err
+			}
+		case c.def.IsAsync():
+			// The command was accepted, its outcome arrives later
+			c.step.Wait(c.response, time.Now().Add(c.def.Wait))
+			if err := r.repo.UpdateStep(ctx, r.lease, c.step); err != nil {
+				return false, err
+			}
+
+			r.logger.InfoWithTrace(ctx).
+				Str("saga_id", r.saga.ID).
+				Str("step", c.step.Name).
+				Dur("timeout", c.def.Wait).
+				Msg("Step waiting for completion")
+
+			failure, err := r.await(ctx, c.step, c.def)
+			switch {
+			case errors.Is(err, errParked):
+				parked = true
+			case err != nil:
+				return false, err
+			case failure != nil:
+				failures = append(failures, *failure)
+			}
+		default:
+			output, err := c.def.MapResponse(c.response)
+			if err != nil {
+				failures = append(failures, stepFailure{step: c.step, cause: err})
+				continue
+			}
+
+			c.step.Succeed(output)
+			if err := r.repo.UpdateStep(ctx, r.lease, c.step); err != nil {
+				return false, err
+			}
+
+			r.logger.InfoWithTrace(ctx).
+				Str("saga_id", r.saga.ID).
+				Str("step", c.step.Name).
+				Msg("Step succeeded")
+		}
+	}
+
+	switch {
+	case interrupted != nil:
+		return false, interrupted
+	case len(failures) > 0 && forward:
+		return false, r.recover(ctx, failures)
+	case len(failures) > 0:
+		return false, r.abort(ctx, failures, r.waiting(pending))
+	case parked:
+		return false, errParked
+	default:
+		return true, nil
+	}
+}
+
+// irreversible reports whether the saga passed its pivot
+func (r *run) irreversible() bool {
+	return r.pivot > 0 && r.saga.GroupsDone(r.pivot)
+}
+
+// inFlight reports whether a call of one of the steps may be in flight
+func (r *run) inFlight(indexes []int) bool {
+	for _, i := range indexes {
+		step := r.saga.Steps[i]
+		if step.Status == entity.StepStatusExecuting && step.NextRetryAt == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// waiting returns the steps that wait for a retry or for their completion
+func (r *run) waiting(indexes []int) []*entity.SagaStep {
+	var steps []*entity.SagaStep
+	for _, i := range indexes {
+		step := r.saga.Steps[i]
+		if (step.Status == entity.StepStatusExecuting && step.NextRetryAt != nil) || step.Status == entity.StepStatusWaiting {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// await resolves a WAITING step from its completion, signal, approval or
+// sub-saga, or parks the saga until it arrives or the step times out. A
+// timeout is retried under the policy of the step: the command is sent again
+// under the same idempotency key, a signal or sub-saga is waited for again.
+// failure is returned once no retry is left. An approval is never retried, it
+// expires as rejected.
+func (r *run) await(ctx context.Context, step *entity.SagaStep, def definition.Step) (*stepFailure, error) {
+	// The wait timer is scheduled before looking for the completion: a
+	// decision received later finds the timer and makes it fire now
+	if step.WaitUntil != nil && !step.WaitExpired() {
+		timer := entity.NewStepTimer(step, entity.TimerKindStepWait, *step.WaitUntil)
+		if err := r.timers.Schedule(ctx, timer); err != nil {
+			return nil, err
+		}
+	}
+
+	completion, err := r.completion(ctx, step, def)
+	if err != nil {
+		return nil, err
+	}
+
+	if completion != nil {
+		if !completion.Succeeded() {
+			return &stepFailure{step: step, cause: errors.New(completion.ErrorMessage)}, nil
+		}
+
+		output, err := def.MapResponse(completion.Payload)
+		if err != nil {
+			return &stepFailure{step: step, cause: err}, nil
+		}
+
+		step.Succeed(output)
+		if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+			return nil, err
+		}
+
+		r.logger.InfoWithTrace(ctx).
+			Str("saga_id", r.saga.ID).
+			Str("step", step.Name).
+			Str("source", completion.Source).
+			Msg("Step completed")
+		return nil, nil
+	}
+
+	if !step.WaitExpired() {
+		return nil, errParked
+	}
+
+	if def.AwaitsApproval() {
+		// A decision made meanwhile wins over the expiry
+		expired, err := r.approvals.Expire(ctx, r.saga.ID, step.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !expired {
+			return r.await(ctx, step, def)
+		}
+		return &stepFailure{step: step, cause: fmt.Errorf("approval expired after %s", def.Wait)}, nil
+	}
+
+	timeout := fmt.Errorf("no completion within %s: %w", def.Wait, context.DeadlineExceeded)
+	switch {
+	case def.AwaitsSignal():
+		timeout = fmt.Errorf("no signal %s within %s: %w", def.Signal, def.Wait, context.DeadlineExceeded)
+	case def.StartsSubSaga():
+		timeout = fmt.Errorf("sub-saga %s not finished within %s: %w", def.SubSaga, def.Wait, context.DeadlineExceeded)
+	}
+	if !def.Retry.ShouldRetry(timeout, step.RetryCount) {
+		return &stepFailure{step: step, cause: timeout, timedOut: true}, nil
+	}
+
+	backoff := def.Retry.Backoff(step.RetryCount + 1)
+	step.ScheduleRetry(timeout.Error(), time.Now().Add(backoff))
+	if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+		return nil, err
+	}
+
+	r.logger.WarnWithTrace(ctx).
+		Str("saga_id", r.saga.ID).
+		Str("step", step.Name).
+		Int("retry", step.RetryCount).
+		Dur("backoff", backoff).
+		Msg("Step timed out waiting for completion, retrying")
+
+	if err := r.scheduleRetry(ctx, step); err != nil {
+		return nil, err
+	}
+	return nil, errParked
+}
+
+// completion returns what a WAITING step waits for: the completion of its
+// command, the signal it consumed, the decision on its approval or the
+// outcome of its sub-saga, nil when none arrived yet
+func (r *run) completion(ctx context.Context, step *entity.SagaStep, def definition.Step) (*entity.StepCompletion, error) {
+	if def.AwaitsApproval() {
+		return r.approval(ctx, step)
+	}
+	if def.StartsSubSaga() {
+		return r.child(ctx, step, def)
+	}
+	if !def.AwaitsSignal() {
+		return r.completions.Find(ctx, r.saga.ID, step.Name)
+	}
+
+	signal, err := r.signals.Claim(ctx, r.saga.ID, def.Signal, step.Name)
+	if err != nil || signal == nil {
+		return nil, err
+	}
+	return &entity.StepCompletion{
+		SagaID:     r.saga.ID,
+		StepName:   step.Name,
+		Payload:    signal.Payload,
+		Source:     "signal",
+		ReceivedAt: signal.ReceivedAt,
+	}, nil
+}
+
+// approval requests the approval of a step the first time, and again after a
+// crash in between, then turns its decision into a completion
+func (r *run) approval(ctx context.Context, step *entity.SagaStep) (*entity.StepCompletion, error) {
+	approval, err := r.approvals.Find(ctx, r.saga.ID, step.Name)
+	if err != nil {
+		return nil, err
+	}
+	if approval == nil {
+		expiresAt := time.Now()
+		if step.WaitUntil != nil {
+			expiresAt = *step.WaitUntil
+		}
+		return nil, r.approvals.Request(ctx, entity.NewApproval(r.saga.ID, step.Name, expiresAt))
+	}
+
+	completion := &entity.StepCompletion{
+		SagaID:     r.saga.ID,
+		StepName:   step.Name,
+		Source:     "approval",
+		ReceivedAt: approval.RequestedAt,
+	}
+	switch {
+	case approval.Status == entity.ApprovalStatusPending:
+		return nil, nil
+	case approval.Approved():
+		completion.Payload = approval.Output()
+	default:
+		completion.ErrorMessage = approval.Reason()
+	}
+	if approval.DecidedAt != nil {
+		completion.ReceivedAt = *approval.DecidedAt
+	}
+	return completion, nil
+}
+
+// child starts the sub-saga of a step the first time, and again after a
+// crash in between, then turns its terminal status into a completion. The
+// child outputs become the output of the step; a child that did not complete
+// fails the step.
+func (r *run) child(ctx context.Context, step *entity.SagaStep, def definition.Step) (*entity.StepCompletion, error) {
+	child, err := r.repo.FindChild(ctx, r.saga.ID, step.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	completion := &entity.StepCompletion{
+		SagaID:     r.saga.ID,
+		StepName:   step.Name,
+		Source:     "sub_saga",
+		ReceivedAt: time.Now(),
+	}
+	if child == nil {
+		if err := r.startChild(ctx, step, def); err != nil {
+			var e *pErrors.Error
+			if errors.As(err, &e) && e.Code == pErrors.Invalid {
+				completion.ErrorMessage = err.Error()
+				return completion, nil
+			}
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	completion.ReceivedAt = child.UpdatedAt
+	switch child.Status {
+	case entity.SagaStatusCompleted:
+		outputs := make(map[string]json.RawMessage, len(child.Steps))
+		for _, s := range child.Steps {
+			if len(s.ResponsePayload) > 0 {
+				outputs[s.Name] = s.ResponsePayload
+			}
+		}
+		// Raw messages only, it cannot fail
+		completion.Payload, _ = json.Marshal(map[string]any{"saga_id": child.ID, "outputs": outputs})
+	case entity.SagaStatusCompensated, entity.SagaStatusFailed:
+		completion.ErrorMessage = fmt.Sprintf("sub-saga %s %s %s: %s", child.Type, child.ID, child.Status, child.ErrorMessage)
+	default:
+		return nil, nil
+	}
+	return completion, nil
+}
+
+// startChild creates the sub-saga of a step with the persisted request as
+// its payload. The pending poller runs it like any other saga.
+func (r *run) startChild(ctx context.Context, step *entity.SagaStep, def definition.Step) error {
+	childDef, err := r.registry.Get(def.SubSaga)
+	if err != nil {
+		return pErrors.E(pErrors.Invalid, err.Error(), err)
+	}
+
+	child, err := entity.NewSubSaga(def.SubSaga, childDef.Version, step.RequestPayload, r.saga.ID, step.Name)
+	if err != nil {
+		return err
+	}
+
+	// The step key is only ever used for its child: a conflict means the
+	// child was created meanwhile and is found on the next run
+	child, err = r.repo.Create(ctx, child, step.IdempotencyKey)
+	var e *pErrors.Error
+	if errors.As(err, &e) && e.Code == pErrors.Conflict {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	r.logger.InfoWithTrace(ctx).
+		Str("saga_id", r.saga.ID).
+		Str("step", step.Name).
+		Str("sub_saga_id", child.ID).
+		Str("sub_saga_type", child.Type).
+		Msg("Sub-saga started")
+
+	return nil
+}
+
+// abort marks the failed steps as failed, gives up the siblings still waiting
+// for a retry and switches the saga to compensation
+func (r *run) abort(ctx context.Context, failures []stepFailure, abandoned []*entity.SagaStep) error {
+	for _, f := range failures {
+		r.logger.WarnWithTrace(ctx).
+			Err(f.cause).
+			Str("saga_id", r.saga.ID).
+			Str("step", f.step.Name).
+			Msg("Step failed, compensating saga")
+
+		if f.timedOut {
+			f.step.TimeOut(f.cause.Error())
+		} else {
+			f.step.Fail(f.cause.Error())
+		}
+		if err := r.repo.UpdateStep(ctx, r.lease, f.step); err != nil {
+			return err
+		}
+	}
+
+	failed := make(map[*entity.SagaStep]bool, len(failures))
+	for _, f := range failures {
+		failed[f.step] = true
+	}
+
+	first := failures[0]
+	reason := fmt.Sprintf("step %s failed: %v", first.step.Name, first.cause)
+	for _, step := range abandoned {
+		// A waiting step may be the one that failed
+		if failed[step] {
+			continue
+		}
+		step.Abandon("abandoned: " + reason)
+		if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+			return err
+		}
+	}
+
+	r.saga.StartCompensation(reason)
+	if err := r.repo.UpdateSaga(ctx, r.lease, r.saga); err != nil {
+		return err
+	}
+
+	return r.compensate(ctx)
+}
+
+// recover schedules another attempt of the failed steps of a saga past its
+// pivot and parks it as FORWARD_RECOVERING. It never gives up: the steps are
+// retried until they succeed or an operator intervenes.
+func (r *run) recover(ctx context.Context, failures []stepFailure) error {
+	for _, f := range failures {
+		def := r.steps[f.step.Order-1]
+		backoff := def.Retry.RecoveryBackoff(f.step.RetryCount + 1)
+		f.step.ScheduleRetry(f.cause.Error(), time.Now().Add(backoff))
+		if err := r.repo.UpdateStep(ctx, r.lease, f.step); err != nil {
+			return err
+		}
+
+		// The failed completion was handled, the command sent again gets
+		// a new one; a step waiting again for a signal takes the next one
+		if f.step.Status == entity.StepStatusWaiting {
+			discard := r.completions.Discard
+			if def.AwaitsSignal() {
+				discard = r.signals.Release
+			}
+			if err := discard(ctx, r.saga.ID, f.step.Name); err != nil {
+				return err
+			}
+		}
+
+		r.logger.WarnWithTrace(ctx).
+			Err(f.cause).
+			Str("saga_id", r.saga.ID).
+			Str("step", f.step.Name).
+			Int("retry", f.step.RetryCount).
+			Dur("backoff", backoff).
+			Msg("Step failed after pivot, recovering forward")
+
+		if err := r.scheduleRetry(ctx, f.step); err != nil {
+			return err
+		}
+	}
+
+	first := failures[0]
+	r.saga.StartForwardRecovery(fmt.Sprintf("step %s failed: %v", first.step.Name, first.cause))
+	if err := r.repo.UpdateSaga(ctx, r.lease, r.saga); err != nil {
+		return err
+	}
+
+	return errParked
+}
+
+// cancel switches a saga cancelled through the API or past a deadline to
+// compensation. Steps waiting for a retry are given up as failed, steps
+// waiting for their completion as timed out.
+func (r *run) cancel(ctx context.Context, waiting []*entity.SagaStep, reason string) error {
+	r.logger.InfoWithTrace(ctx).
+		Str("saga_id", r.saga.ID).
+		Str("reason", reason).
+		Msg("Saga cancelled, compensating")
+
+	message := reason
+	if message == "" {
+		message = "saga cancelled"
+	}
+
+	for _, step := range waiting {
+		step.Abandon(message)
+		if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+			return err
+		}
+	}
+
+	r.saga.StartCompensation(message)
+	if err := r.repo.UpdateSaga(ctx, r.lease, r.saga); err != nil {
+		return err
+	}
+
+	return r.compensate(ctx)
+}
+
+func (r *run) compensate(ctx context.Context) error {
+	for i := len(r.saga.Steps) - 1; i >= 0; i-- {
+		step := r.saga.Steps[i]
+		if !step.NeedsCompensation() {
+			continue
+		}
+
+		def := r.steps[i]
+		if def.StartsSubSaga() {
+			if err := r.compensateChild(ctx, step); err != nil {
+				if cause, ok := asFailed(err); ok {
+					return r.stuck(ctx, step, cause)
+				}
+				return err
+			}
+			continue
+		}
+
+		if !def.HasCompensation() {
+			continue
+		}
+
+		if step.RetryPending() {
+			if err := r.scheduleRetry(ctx, step); err != nil {
+				return err
+			}
+			return errParked
+		}
+
+		request, err := def.CompensationRequest(r.state())
+		if err != nil {
+			return r.stuck(ctx, step, err)
+		}
+
+		step.StartCompensation()
+		if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+			return err
+		}
+
+		if _, err := r.attempt(ctx, step, def.Retry, def.Compensation, step.CompensationKey(), request); err != nil {
+			if cause, ok := asFailed(err); ok {
+				return r.stuck(ctx, step, cause)
+			}
+			return err
+		}
+
+		step.Compensated()
+		if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+			return err
+		}
+
+		r.logger.InfoWithTrace(ctx).
+			Str("saga_id", r.saga.ID).
+			Str("step", step.Name).
+			Msg("Step compensated")
+	}
+
+	r.saga.Compensated()
+	if err := r.repo.UpdateSaga(ctx, r.lease, r.saga); err != nil {
+		return err
+	}
+
+	r.logger.InfoWithTrace(ctx).
+		Str("saga_id", r.saga.ID).
+		Str("saga_type", r.saga.Type).
+		Msg("Saga compensated")
+
+	return nil
+}
+
+// compensateChild compensates the sub-saga of a step with its parent: a
+// running child is cancelled, a completed one reverted. The parent is parked
+// until the child is compensated, which wakes it up. A failedError is
+// returned when the child cannot be compensated automatically.
+func (r *run) compensateChild(ctx context.Context, step *entity.SagaStep) error {
+	// Scheduled before looking at the child, so that a child finishing in
+	// between finds the timer and makes it fire now
+	timer := entity.NewStepTimer(step, entity.TimerKindStepWait, time.Now().Add(childPollInterval))
+	if err := r.timers.Schedule(ctx, timer); err != nil {
+		return err
+	}
+
+	child, err := r.repo.FindChild(ctx, r.saga.ID, step.Name)
+	if err != nil {
+		return err
+	}
+
+	reason := fmt.Sprintf("parent saga %s compensating: %s", r.saga.ID, r.saga.ErrorMessage)
+	if child != nil {
+		switch child.Status {
+		case entity.SagaStatusCompensated, entity.SagaStatusCompensating:
+		case entity.SagaStatusFailed:
+			return &failedError{cause: fmt.Errorf("sub-saga %s failed: %s", child.ID, child.ErrorMessage)}
+		case entity.SagaStatusCompleted:
+			if err := r.revert(ctx, child, reason); err != nil {
+				return err
+			}
+		default:
+			// A child past its pivot refuses, it is reverted once completed
+			err := r.repo.RequestCancel(ctx, child.ID, reason)
+			var e *pErrors.Error
+			if err != nil && !(errors.As(err, &e) && e.Code == pErrors.Conflict) {
+				return err
+			}
+		}
+	}
+
+	if child == nil || child.Status == entity.SagaStatusCompensated {
+		step.Compensated()
+		if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+			return err
+		}
+
+		r.logger.InfoWithTrace(ctx).
+			Str("saga_id", r.saga.ID).
+			Str("step", step.Name).
+			Msg("Step compensated")
+		return nil
+	}
+
+	if step.Status != entity.StepStatusCompensating {
+		step.StartCompensation()
+		if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+			return err
+		}
+	}
+	return errParked
+}
+
+// revert hands a completed sub-saga back to the recovery sweeper as
+// COMPENSATING. It waits for the next poll while the child is being run.
+// A child that cannot be reverted is returned as a failedError.
+func (r *run) revert(ctx context.Context, child *entity.Saga, reason string) error {
+	def, err := r.registry.GetVersion(child.Type, child.DefinitionVersion)
+	if err != nil {
+		return &failedError{cause: err}
+	}
+	if def.PivotGroup() > 0 {
+		return &failedError{cause: fmt.Errorf("sub-saga %s passed its pivot and cannot be compensated", child.ID)}
+	}
+
+	lease, err := r.locks.Acquire(ctx, child.ID)
+	if err != nil || lease == nil {
+		return err
+	}
+	defer r.locks.Release(lease)
+
+	// Read again under the lease, so the status is not stale
+	child, err = r.repo.FindByID(ctx, child.ID)
+	if err != nil {
+		return err
+	}
+	if child.Status != entity.SagaStatusCompleted {
+		return nil
+	}
+
+	child.Revert(reason)
+	if err := r.repo.UpdateSaga(ctx, lease, child); err != nil {
+		return err
+	}
+
+	r.logger.InfoWithTrace(ctx).
+		Str("saga_id", r.saga.ID).
+		Str("sub_saga_id", child.ID).
+		Msg("Sub-saga reverted")
+
+	return nil
+}
+
+// stuck marks a compensation as failed. The saga cannot be rolled back
+// automatically any more and is parked as FAILED.
+func (r *run) stuck(ctx context.Context, step *entity.SagaStep, cause error) error {
+	r.logger.ErrorWithTrace(ctx).
+		Err(cause).
+		Str("saga_id", r.saga.ID).
+		Str("step", step.Name).
+		Msg("Compensation failed")
+
+	step.CompensationFailed(cause.Error())
+	if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+		return err
+	}
+
+	r.saga.Fail(fmt.Sprintf("compensation of step %s failed: %v", step.Name, cause))
+	return r.repo.UpdateSaga(ctx, r.lease, r.saga)
+}
+
+// request returns the forward request of a step. A step left EXECUTING by a
+// crashed owner or a failed call, or WAITING for a completion that did not
+// come, may already have been applied downstream, so it is re-sent exactly
+// as persisted, under the same idempotency key.
+func (r *run) request(step *entity.SagaStep, def definition.Step) (json.RawMessage, error) {
+	resend := step.Status == entity.StepStatusExecuting || step.Status == entity.StepStatusWaiting
+	if resend && len(step.RequestPayload) > 0 {
+		r.logger.Info().
+			Str("saga_id", r.saga.ID).
+			Str("step", step.Name).
+			Int("retry", step.RetryCount).
+			Msg("Re-sending step request")
+		return step.RequestPayload, nil
+	}
+	return def.Request(r.state())
+}
+
+// attempt sends one call of the step. A retryable failure is persisted with
+// its backoff and the saga is parked until the retry timer fires, so a
+// restarted orchestrator continues the schedule instead of resetting it.
+// The error of the call is returned as a failedError once no retry is left.
+func (r *run) attempt(ctx context.Context, step *entity.SagaStep, policy definition.RetryPolicy, action definition.Action, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	response, callErr := r.call(ctx, action, idempotencyKey, request)
+	if callErr == nil {
+		return response, nil
+	}
+
+	// Shutting down or lease lost: leave the step as is so the call is
+	// re-sent with the same idempotency key when the saga is resumed
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if !policy.ShouldRetry(callErr, step.RetryCount) {
+		return nil, &failedError{cause: callErr}
+	}
+
+	backoff := policy.Backoff(step.RetryCount + 1)
+	step.ScheduleRetry(callErr.Error(), time.Now().Add(backoff))
+	if err := r.repo.UpdateStep(ctx, r.lease, step); err != nil {
+		return nil, err
+	}
+
+	r.logger.WarnWithTrace(ctx).
+		Err(callErr).
+		Str("saga_id", r.saga.ID).
+		Str("step", step.Name).
+		Int("retry", step.RetryCount).
+		Dur("backoff", backoff).
+		Msg("Step call failed, retrying")
+
+	if err := r.scheduleRetry(ctx, step); err != nil {
+		return nil, err
+	}
+	return nil, errParked
+}
+
+// scheduleRetry schedules the timer that resumes the saga for the retry of
+// the step. Scheduling is repeated when a parked saga is woken up early, e.g.
+// by the recovery sweeper after a crash between persisting the retry and
+// scheduling it.
+func (r *run) scheduleRetry(ctx context.Context, step *entity.SagaStep) error {
+	timer := entity.NewStepTimer(step, entity.TimerKindStepRetry, *step.NextRetryAt)
+	return r.timers.Schedule(ctx, timer)
+}
+
+func (r *run) call(ctx context.Context, action definition.Action, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.stepTimeout)
+	defer cancel()
+
+	return action(ctx, idempotencyKey, request)
+}
+
+// state collects the saga input and the responses of the succeeded steps
+func (r *run) state() definition.State {
+	outputs := make(map[string]json.RawMessage, len(r.saga.Steps))
+	for _, step := range r.saga.Steps {
+		if len(step.ResponsePayload) > 0 {
+			outputs[step.Name] = step.ResponsePayload
+		}
+	}
+	return definition.State{Payload: r.saga.Payload, Outputs: outputs}
+}
@@ -0,0 +1,1162 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/lock"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// store keeps sagas, leases, timers, signals and approvals in memory. Sagas
+// are copied in and out, so only what the executor wrote is seen by the test.
+type store struct {
+	mu        sync.Mutex
+	sagas     map[string]*entity.Saga
+	tokens    map[string]int64
+	held      map[string]bool
+	cancels   map[string]string
+	timers    []*entity.Timer
+	deleted   []string
+	signals   *signals
+	approvals *approvals
+}
+
+func newStore(sagas ...*entity.Saga) *store {
+	s := &store{
+		sagas:     make(map[string]*entity.Saga),
+		tokens:    make(map[string]int64),
+		held:      make(map[string]bool),
+		cancels:   make(map[string]string),
+		signals:   &signals{},
+		approvals: &approvals{stored: make(map[string]*entity.Approval)},
+	}
+	for _, saga := range sagas {
+		s.sagas[saga.ID] = clone(saga)
+	}
+	return s
+}
+
+func clone(saga *entity.Saga) *entity.Saga {
+	c := *saga
+	c.Steps = make([]*entity.SagaStep, len(saga.Steps))
+	for i, step := range saga.Steps {
+		s := *step
+		c.Steps[i] = &s
+	}
+	return &c
+}
+
+func (s *store) saga(id string) *entity.Saga {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return clone(s.sagas[id])
+}
+
+// steal hands the lease of a saga to another owner
+func (s *store) steal(sagaID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[sagaID]++
+}
+
+func (s *store) fence(lease *entity.Lease) error {
+	if lease.Token != s.tokens[lease.SagaID] {
+		return pErrors.E(pErrors.Conflict, "saga lease lost", nil)
+	}
+	return nil
+}
+
+func (s *store) timerKinds() []entity.TimerKind {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var kinds []entity.TimerKind
+	for _, t := range s.timers {
+		kinds = append(kinds, t.Kind)
+	}
+	return kinds
+}
+
+// LockRepository
+
+func (s *store) Acquire(ctx context.Context, sagaID, ownerID string, ttl time.Duration) (*entity.Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.held[sagaID] {
+		return nil, nil
+	}
+	s.tokens[sagaID]++
+	return &entity.Lease{SagaID: sagaID, OwnerID: ownerID, Token: s.tokens[sagaID], ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+func (s *store) Renew(ctx context.Context, lease *entity.Lease, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fence(lease)
+}
+
+func (s *store) Release(ctx context.Context, lease *entity.Lease) error { return nil }
+
+// SagaRepository
+
+func (s *store) Create(ctx context.Context, saga *entity.Saga, idempotencyKey string) (*entity.Saga, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saga.ID = fmt.Sprintf("saga-%d", len(s.sagas)+1)
+	s.sagas[saga.ID] = clone(saga)
+	return saga, nil
+}
+
+func (s *store) CheckIdempotency(ctx context.Context, key string) (*entity.Saga, error) {
+	return nil, nil
+}
+
+func (s *store) List(ctx context.Context, filter repository.SagaFilter) ([]*entity.Saga, error) {
+	return nil, nil
+}
+
+func (s *store) RequestCancel(ctx context.Context, sagaID string, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancels[sagaID] = reason
+	return nil
+}
+
+func (s *store) FindCancelRequest(ctx context.Context, sagaID string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reason, ok := s.cancels[sagaID]
+	return reason, ok, nil
+}
+
+func (s *store) FindPending(ctx context.Context, limit int) ([]string, error) { return nil, nil }
+
+func (s *store) FindStuck(ctx context.Context, limit int) ([]string, error) { return nil, nil }
+
+func (s *store) FindByID(ctx context.Context, id string) (*entity.Saga, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saga, ok := s.sagas[id]
+	if !ok {
+		return nil, pErrors.E(pErrors.NotFound, "saga not found", nil)
+	}
+	return clone(saga), nil
+}
+
+func (s *store) FindChild(ctx context.Context, parentSagaID, stepName string) (*entity.Saga, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, saga := range s.sagas {
+		if saga.ParentSagaID == parentSagaID && saga.ParentStep == stepName {
+			return clone(saga), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *store) VersionsInUse(ctx context.Context) ([]repository.VersionUsage, error) {
+	return nil, nil
+}
+
+func (s *store) CreateSteps(ctx context.Context, lease *entity.Lease, steps []*entity.SagaStep) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.fence(lease); err != nil {
+		return err
+	}
+	saga := s.sagas[lease.SagaID]
+	for _, step := range steps {
+		c := *step
+		saga.Steps = append(saga.Steps, &c)
+	}
+	return nil
+}
+
+func (s *store) UpdateSaga(ctx context.Context, lease *entity.Lease, saga *entity.Saga) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.fence(lease); err != nil {
+		return err
+	}
+	steps := s.sagas[saga.ID].Steps
+	c := *saga
+	c.Steps = steps
+	s.sagas[saga.ID] = &c
+	return nil
+}
+
+func (s *store) UpdateStep(ctx context.Context, lease *entity.Lease, step *entity.SagaStep) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.fence(lease); err != nil {
+		return err
+	}
+	for i, stored := range s.sagas[step.SagaID].Steps {
+		if stored.ID == step.ID {
+			c := *step
+			s.sagas[step.SagaID].Steps[i] = &c
+			return nil
+		}
+	}
+	return pErrors.E(pErrors.NotFound, "step not found", nil)
+}
+
+func (s *store) Upgrade(ctx context.Context, lease *entity.Lease, saga *entity.Saga, fromVersion int) error {
+	return errors.New("not implemented")
+}
+
+func (s *store) Restore(ctx context.Context, lease *entity.Lease, saga *entity.Saga, sequence int64) error {
+	return errors.New("not implemented")
+}
+
+// TimerRepository
+
+// Schedule keeps one timer per saga, step and kind: a rearmable timer is
+// moved and armed again, any other is kept as first scheduled
+func (s *store) Schedule(ctx context.Context, timer *entity.Timer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.timers {
+		if t.SagaID == timer.SagaID && t.StepID == timer.StepID && t.Kind == timer.Kind {
+			if timer.Rearmable() {
+				t.FireAt = timer.FireAt
+				t.FiredAt = nil
+			}
+			return nil
+		}
+	}
+	c := *timer
+	s.timers = append(s.timers, &c)
+	return nil
+}
+
+// FireDue fires the due timers. A deadline requests cancellation of a saga
+// still running forward, keeping the reason of an earlier request.
+func (s *store) FireDue(ctx context.Context, limit int) ([]*entity.Timer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var fired []*entity.Timer
+	for _, t := range s.timers {
+		if len(fired) == limit || t.FiredAt != nil || t.FireAt.After(now) {
+			continue
+		}
+		t.FiredAt = &now
+		fired = append(fired, t)
+
+		saga := s.sagas[t.SagaID]
+		if _, cancelled := s.cancels[t.SagaID]; cancelled {
+			continue
+		}
+		switch t.Kind {
+		case entity.TimerKindSagaDeadline:
+			if saga.Status == entity.SagaStatusPending || saga.Status == entity.SagaStatusExecuting {
+				s.cancels[t.SagaID] = "saga deadline exceeded"
+			}
+		case entity.TimerKindStepDeadline:
+			for _, step := range saga.Steps {
+				running := step.Status == entity.StepStatusPending || step.Status == entity.StepStatusExecuting || step.Status == entity.StepStatusWaiting
+				if step.ID == t.StepID && running && saga.Status == entity.SagaStatusExecuting {
+					s.cancels[t.SagaID] = "step " + step.Name + " deadline exceeded"
+				}
+			}
+		}
+	}
+	return fired, nil
+}
+
+// elapse makes the timers of a kind due now
+func (s *store) elapse(kind entity.TimerKind) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.timers {
+		if t.Kind == kind {
+			t.FireAt = time.Now().Add(-time.Second)
+		}
+	}
+}
+
+func (s *store) timer(kind entity.TimerKind) *entity.Timer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.timers {
+		if t.Kind == kind {
+			c := *t
+			return &c
+		}
+	}
+	return nil
+}
+
+func (s *store) DeleteBySaga(ctx context.Context, sagaID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = append(s.deleted, sagaID)
+	return nil
+}
+
+// CompletionRepository: no completion ever arrives
+
+func (s *store) Record(ctx context.Context, completion *entity.StepCompletion) (bool, error) {
+	return true, nil
+}
+
+func (s *store) Find(ctx context.Context, sagaID, stepName string) (*entity.StepCompletion, error) {
+	return nil, nil
+}
+
+func (s *store) Discard(ctx context.Context, sagaID, stepName string) error { return nil }
+
+// signals keeps the signals pushed into sagas in the order they arrived.
+// onClaim, when set, is called once a signal is consumed.
+type signals struct {
+	mu      sync.Mutex
+	stored  []*entity.Signal
+	onClaim func()
+}
+
+// push records a signal of the saga
+func (s *signals) push(t *testing.T, sagaID, name, payload string) {
+	t.Helper()
+	signal, err := entity.NewSignal(sagaID, name, fmt.Sprintf("key-%d", len(s.stored)+1), json.RawMessage(payload))
+	if err != nil {
+		t.Fatalf("NewSignal() error = %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stored = append(s.stored, signal)
+}
+
+// consumed returns the payloads of the consumed signals
+func (s *signals) consumed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var payloads []string
+	for _, signal := range s.stored {
+		if signal.ConsumedAt != nil {
+			payloads = append(payloads, string(signal.Payload))
+		}
+	}
+	return payloads
+}
+
+func (s *signals) Record(ctx context.Context, signal *entity.Signal) (bool, error) {
+	return false, errors.New("not implemented")
+}
+
+func (s *signals) FindByKey(ctx context.Context, sagaID, idempotencyKey string) (*entity.Signal, error) {
+	return nil, errors.New("not implemented")
+}
+
+// Claim returns the signal the step consumed, or consumes the oldest one
+// nobody consumed yet
+func (s *signals) Claim(ctx context.Context, sagaID, name, stepName string) (*entity.Signal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, signal := range s.stored {
+		if signal.SagaID == sagaID && signal.ConsumedBy == stepName {
+			c := *signal
+			return &c, nil
+		}
+	}
+	for _, signal := range s.stored {
+		if signal.SagaID == sagaID && signal.Name == name && signal.ConsumedAt == nil {
+			now := time.Now()
+			signal.ConsumedBy = stepName
+			signal.ConsumedAt = &now
+			if s.onClaim != nil {
+				s.onClaim()
+			}
+			c := *signal
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// Release lets go of the signal, which stays consumed
+func (s *signals) Release(ctx context.Context, sagaID, stepName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, signal := range s.stored {
+		if signal.SagaID == sagaID && signal.ConsumedBy == stepName {
+			signal.ConsumedBy = ""
+		}
+	}
+	return nil
+}
+
+// approvals keeps the approvals requested by steps
+type approvals struct {
+	mu     sync.Mutex
+	stored map[string]*entity.Approval
+}
+
+// decide records the decision of an approver on the approval of a step
+func (a *approvals) decide(t *testing.T, sagaID, stepName string, approved bool) {
+	t.Helper()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	approval, ok := a.stored[sagaID+"/"+stepName]
+	if !ok {
+		t.Fatalf("step %s did not request an approval", stepName)
+	}
+	if err := approval.Decide(approved, "alice", "checked"); err != nil {
+		t.Fatalf("Decide() error = %v", err)
+	}
+}
+
+func (a *approvals) Request(ctx context.Context, approval *entity.Approval) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.stored[approval.SagaID+"/"+approval.StepName]; !ok {
+		a.stored[approval.SagaID+"/"+approval.StepName] = approval
+	}
+	return nil
+}
+
+func (a *approvals) Find(ctx context.Context, sagaID, stepName string) (*entity.Approval, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	approval, ok := a.stored[sagaID+"/"+stepName]
+	if !ok {
+		return nil, nil
+	}
+	c := *approval
+	return &c, nil
+}
+
+func (a *approvals) Decide(ctx context.Context, approval *entity.Approval) error {
+	return errors.New("not implemented")
+}
+
+// Expire rejects the approval unless it was decided
+func (a *approvals) Expire(ctx context.Context, sagaID, stepName string) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	approval, ok := a.stored[sagaID+"/"+stepName]
+	if !ok || approval.Status != entity.ApprovalStatusPending {
+		return false, nil
+	}
+	approval.Status = entity.ApprovalStatusExpired
+	return true, nil
+}
+
+func (a *approvals) ListPending(ctx context.Context, filter repository.ApprovalFilter) ([]*entity.Approval, error) {
+	return nil, nil
+}
+
+// calls records the actions called by the executor
+type calls struct {
+	mu    sync.Mutex
+	names []string
+}
+
+// action returns an action named name that fails with err
+func (c *calls) action(name string, err error) definition.Action {
+	return func(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.names = append(c.names, name)
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(`{"id": "` + name + `"}`), nil
+	}
+}
+
+func emptyRequest(state definition.State) (json.RawMessage, error) {
+	return json.RawMessage(`{}`), nil
+}
+
+var (
+	rejected    = status.Error(codes.InvalidArgument, "card declined")
+	unavailable = status.Error(codes.Unavailable, "payment service unavailable")
+)
+
+func newSaga(t *testing.T, sagaType string) *entity.Saga {
+	t.Helper()
+	saga, err := entity.NewSaga(sagaType, 1, json.RawMessage(`{"customer_id": "c-1"}`))
+	if err != nil {
+		t.Fatalf("NewSaga() error = %v", err)
+	}
+	saga.ID = "saga-" + sagaType
+	return saga
+}
+
+func newExecutor(t *testing.T, s *store, defs ...*definition.Builder) *Executor {
+	t.Helper()
+
+	registry := definition.NewRegistry()
+	for _, b := range defs {
+		def, err := b.Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if err := registry.Register(def); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+
+	log := logger.New("orchestrator-test")
+	locks := lock.NewManager(s, "test", time.Minute, log)
+	return NewExecutor(s, s, s, s.signals, s.approvals, locks, registry, time.Second, log)
+}
+
+func stepStatuses(saga *entity.Saga) []entity.StepStatus {
+	var statuses []entity.StepStatus
+	for _, step := range saga.Steps {
+		statuses = append(statuses, step.Status)
+	}
+	return statuses
+}
+
+func TestRun(t *testing.T) {
+	retry := definition.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Minute,
+		Multiplier:     1,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}
+
+	tests := []struct {
+		name       string
+		build      func(b *definition.Builder, c *calls)
+		cancel     bool
+		wantStatus entity.SagaStatus
+		wantSteps  []entity.StepStatus
+		wantCalls  []string
+		wantTimers []entity.TimerKind
+	}{
+		{
+			name: "completes",
+			build: func(b *definition.Builder, c *calls) {
+				b.Step("create_order").Action(c.action("create_order", nil), emptyRequest).Compensation(c.action("cancel_order", nil), emptyRequest)
+				b.Step("process_payment").Action(c.action("process_payment", nil), emptyRequest)
+			},
+			wantStatus: entity.SagaStatusCompleted,
+			wantSteps:  []entity.StepStatus{entity.StepStatusSucceeded, entity.StepStatusSucceeded},
+			wantCalls:  []string{"create_order", "process_payment"},
+		},
+		{
+			name: "compensates the steps before a failed one",
+			build: func(b *definition.Builder, c *calls) {
+				b.Step("create_order").Action(c.action("create_order", nil), emptyRequest).Compensation(c.action("cancel_order", nil), emptyRequest)
+				b.Step("process_payment").Action(c.action("process_payment", rejected), emptyRequest)
+			},
+			wantStatus: entity.SagaStatusCompensated,
+			wantSteps:  []entity.StepStatus{entity.StepStatusCompensated, entity.StepStatusFailed},
+			wantCalls:  []string{"create_order", "process_payment", "cancel_order"},
+		},
+		{
+			name: "fails when a compensation fails",
+			build: func(b *definition.Builder, c *calls) {
+				b.Step("create_order").Action(c.action("create_order", nil), emptyRequest).Compensation(c.action("cancel_order", rejected), emptyRequest)
+				b.Step("process_payment").Action(c.action("process_payment", rejected), emptyRequest)
+			},
+			wantStatus: entity.SagaStatusFailed,
+			wantSteps:  []entity.StepStatus{entity.StepStatusCompensationFailed, entity.StepStatusFailed},
+			wantCalls:  []string{"create_order", "process_payment", "cancel_order"},
+		},
+		{
+			name: "parks a retryable failure until its timer",
+			build: func(b *definition.Builder, c *calls) {
+				b.Step("create_order").Action(c.action("create_order", nil), emptyRequest).Compensation(c.action("cancel_order", nil), emptyRequest)
+				b.Step("process_payment").Action(c.action("process_payment", unavailable), emptyRequest).Retry(retry)
+			},
+			wantStatus: entity.SagaStatusExecuting,
+			wantSteps:  []entity.StepStatus{entity.StepStatusSucceeded, entity.StepStatusExecuting},
+			wantCalls:  []string{"create_order", "process_payment"},
+			wantTimers: []entity.TimerKind{entity.TimerKindStepRetry},
+		},
+		{
+			name: "recovers forward past the pivot",
+			build: func(b *definition.Builder, c *calls) {
+				b.Step("create_order").Action(c.action("create_order", nil), emptyRequest).Compensation(c.action("cancel_order", nil), emptyRequest)
+				b.Step("process_payment").Action(c.action("process_payment", nil), emptyRequest).Pivot()
+				b.Step("confirm_order").Action(c.action("confirm_order", rejected), emptyRequest)
+			},
+			wantStatus: entity.SagaStatusForwardRecovering,
+			wantSteps:  []entity.StepStatus{entity.StepStatusSucceeded, entity.StepStatusSucceeded, entity.StepStatusExecuting},
+			wantCalls:  []string{"create_order", "process_payment", "confirm_order"},
+			wantTimers: []entity.TimerKind{entity.TimerKindStepRetry},
+		},
+		{
+			name: "skips a step whose condition does not hold",
+			build: func(b *definition.Builder, c *calls) {
+				b.Step("create_order").Action(c.action("create_order", nil), emptyRequest)
+				b.Step("wrap_gift").Action(c.action("wrap_gift", nil), emptyRequest).When(definition.Exists("payload.gift"))
+			},
+			wantStatus: entity.SagaStatusCompleted,
+			wantSteps:  []entity.StepStatus{entity.StepStatusSucceeded, entity.StepStatusSkipped},
+			wantCalls:  []string{"create_order"},
+		},
+		{
+			name: "cancelled before the first call",
+			build: func(b *definition.Builder, c *calls) {
+				b.Step("create_order").Action(c.action("create_order", nil), emptyRequest).Compensation(c.action("cancel_order", nil), emptyRequest)
+			},
+			cancel:     true,
+			wantStatus: entity.SagaStatusCompensated,
+			wantSteps:  []entity.StepStatus{entity.StepStatusPending},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &calls{}
+			b := definition.New("order_saga")
+			tt.build(b, c)
+
+			saga := newSaga(t, "order_saga")
+			s := newStore(saga)
+			if tt.cancel {
+				s.cancels[saga.ID] = "cancelled by customer"
+			}
+
+			if err := newExecutor(t, s, b).Run(context.Background(), saga.ID); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			got := s.saga(saga.ID)
+			if got.Status != tt.wantStatus {
+				t.Errorf("saga status = %s (%s), want %s", got.Status, got.ErrorMessage, tt.wantStatus)
+			}
+			if fmt.Sprint(stepStatuses(got)) != fmt.Sprint(tt.wantSteps) {
+				t.Errorf("step statuses = %v, want %v", stepStatuses(got), tt.wantSteps)
+			}
+			if fmt.Sprint(c.names) != fmt.Sprint(tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", c.names, tt.wantCalls)
+			}
+			if fmt.Sprint(s.timerKinds()) != fmt.Sprint(tt.wantTimers) {
+				t.Errorf("timers = %v, want %v", s.timerKinds(), tt.wantTimers)
+			}
+			// The timers of a finished saga are dropped
+			if got.IsTerminal() != (len(s.deleted) == 1) {
+				t.Errorf("timers deleted for %v, saga %s", s.deleted, got.Status)
+			}
+		})
+	}
+}
+
+func TestRunResumesRetryUnderSameKey(t *testing.T) {
+	// The first call fails, the retry succeeds
+	var keys []string
+	b := definition.New("order_saga")
+	b.Step("process_payment").
+		Action(func(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+			keys = append(keys, idempotencyKey)
+			if len(keys) == 1 {
+				return nil, unavailable
+			}
+			return json.RawMessage(`{}`), nil
+		}, emptyRequest).
+		Retry(definition.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Minute, Multiplier: 1, RetryableCodes: []codes.Code{codes.Unavailable}})
+
+	saga := newSaga(t, "order_saga")
+	s := newStore(saga)
+	e := newExecutor(t, s, b)
+
+	if err := e.Run(context.Background(), saga.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	step := s.sagas[saga.ID].Steps[0]
+	if step.RetryCount != 1 || step.NextRetryAt == nil {
+		t.Fatalf("step = %s, %d retries, next at %v, want a retry scheduled", step.Status, step.RetryCount, step.NextRetryAt)
+	}
+
+	// The retry timer fired
+	past := time.Now().Add(-time.Second)
+	step.NextRetryAt = &past
+	if err := e.Run(context.Background(), saga.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := s.saga(saga.ID); got.Status != entity.SagaStatusCompleted {
+		t.Fatalf("saga status = %s (%s), want COMPLETED", got.Status, got.ErrorMessage)
+	}
+	if len(keys) != 2 || keys[0] != keys[1] {
+		t.Errorf("idempotency keys = %v, want the same key twice", keys)
+	}
+}
+
+func TestRunSkipsSagaLeasedElsewhere(t *testing.T) {
+	c := &calls{}
+	b := definition.New("order_saga")
+	b.Step("create_order").Action(c.action("create_order", nil), emptyRequest)
+
+	saga := newSaga(t, "order_saga")
+	s := newStore(saga)
+	s.held[saga.ID] = true
+
+	if err := newExecutor(t, s, b).Run(context.Background(), saga.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := s.saga(saga.ID); got.Status != entity.SagaStatusPending || len(c.names) > 0 {
+		t.Errorf("saga %s with calls %v, want it untouched", got.Status, c.names)
+	}
+}
+
+func TestRunFencedAfterLeaseLost(t *testing.T) {
+	saga := newSaga(t, "order_saga")
+	s := newStore(saga)
+
+	b := definition.New("order_saga")
+	b.Step("create_order").Action(func(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+		// Another instance took the saga over while the call was in flight
+		s.steal(saga.ID)
+		return json.RawMessage(`{}`), nil
+	}, emptyRequest)
+
+	err := newExecutor(t, s, b).Run(context.Background(), saga.ID)
+	var e *pErrors.Error
+	if !errors.As(err, &e) || e.Code != pErrors.Conflict {
+		t.Fatalf("Run() error = %v, want a Conflict", err)
+	}
+
+	// The step stays in flight, so the new owner re-sends it under its key
+	got := s.saga(saga.ID)
+	if got.Status != entity.SagaStatusExecuting || got.Steps[0].Status != entity.StepStatusExecuting {
+		t.Errorf("saga %s, step %s, want EXECUTING", got.Status, got.Steps[0].Status)
+	}
+}
+
+// fire fires the due timers through the timer poller, which runs their sagas
+func fire(e *Executor, s *store) {
+	NewTimerPoller(s, e, time.Second, 10, e.logger).poll(context.Background())
+}
+
+func TestTimers(t *testing.T) {
+	retry := definition.RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Minute,
+		Multiplier:     1,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}
+
+	tests := []struct {
+		name  string
+		build func(b *definition.Builder, c *calls)
+		// timer is the kind of timer that becomes due after the first run
+		timer      entity.TimerKind
+		wantStatus entity.SagaStatus
+		wantReason string
+		wantSteps  []entity.StepStatus
+		wantCalls  []string
+	}{
+		{
+			name: "step deadline times a waiting step out",
+			build: func(b *definition.Builder, c *calls) {
+				b.Step("create_order").Action(c.action("create_order", nil), emptyRequest).Compensation(c.action("cancel_order", nil), emptyRequest)
+				b.Step("ship_order").Action(c.action("ship_order", nil), emptyRequest).Async(time.Hour).Deadline(time.Minute)
+			},
+			timer:      entity.TimerKindStepDeadline,
+			wantStatus: entity.SagaStatusCompensated,
+			wantReason: "step ship_order deadline exceeded",
+			wantSteps:  []entity.StepStatus{entity.StepStatusCompensated, entity.StepStatusTimedOut},
+			wantCalls:  []string{"create_order", "ship_order", "cancel_order"},
+		},
+		{
+			name: "saga deadline compensates a parked saga",
+			build: func(b *definition.Builder, c *calls) {
+				b.Deadline(time.Minute)
+				b.Step("create_order").Action(c.action("create_order", nil), emptyRequest).Compensation(c.action("cancel_order", nil), emptyRequest)
+				b.Step("process_payment").Action(c.action("process_payment", unavailable), emptyRequest).Retry(retry)
+			},
+			timer:      entity.TimerKindSagaDeadline,
+			wantStatus: entity.SagaStatusCompensated,
+			wantReason: "saga deadline exceeded",
+			wantSteps:  []entity.StepStatus{entity.StepStatusCompensated, entity.StepStatusFailed},
+			wantCalls:  []string{"create_order", "process_payment", "cancel_order"},
+		},
+		{
+			name: "step deadline after the step succeeded",
+			build: func(b *definition.Builder, c *calls) {
+				b.Step("create_order").Action(c.action("create_order", nil), emptyRequest).Deadline(time.Minute)
+				b.Step("process_payment").Action(c.action("process_payment", unavailable), emptyRequest).Retry(retry)
+			},
+			timer:      entity.TimerKindStepDeadline,
+			wantStatus: entity.SagaStatusExecuting,
+			wantReason: "",
+			wantSteps:  []entity.StepStatus{entity.StepStatusSucceeded, entity.StepStatusExecuting},
+			wantCalls:  []string{"create_order", "process_payment"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &calls{}
+			b := definition.New("order_saga")
+			tt.build(b, c)
+
+			saga := newSaga(t, "order_saga")
+			s := newStore(saga)
+			e := newExecutor(t, s, b)
+
+			if err := e.Run(context.Background(), saga.ID); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got := s.saga(saga.ID); got.Status != entity.SagaStatusExecuting {
+				t.Fatalf("saga status = %s (%s), want it parked as EXECUTING", got.Status, got.ErrorMessage)
+			}
+			if s.timer(tt.timer) == nil {
+				t.Fatalf("timers = %v, want %s scheduled", s.timerKinds(), tt.timer)
+			}
+
+			s.elapse(tt.timer)
+			fire(e, s)
+
+			got := s.saga(saga.ID)
+			if got.Status != tt.wantStatus || got.ErrorMessage != tt.wantReason {
+				t.Errorf("saga = %s (%q), want %s (%q)", got.Status, got.ErrorMessage, tt.wantStatus, tt.wantReason)
+			}
+			if fmt.Sprint(stepStatuses(got)) != fmt.Sprint(tt.wantSteps) {
+				t.Errorf("step statuses = %v, want %v", stepStatuses(got), tt.wantSteps)
+			}
+			if fmt.Sprint(c.names) != fmt.Sprint(tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", c.names, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTimerRearmed(t *testing.T) {
+	c := &calls{}
+	b := definition.New("order_saga")
+	b.Step("process_payment").
+		Action(c.action("process_payment", unavailable), emptyRequest).
+		Retry(definition.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Minute, Multiplier: 1, RetryableCodes: []codes.Code{codes.Unavailable}})
+
+	saga := newSaga(t, "order_saga")
+	s := newStore(saga)
+	e := newExecutor(t, s, b)
+
+	if err := e.Run(context.Background(), saga.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The backoff elapsed
+	s.elapse(entity.TimerKindStepRetry)
+	past := time.Now().Add(-time.Second)
+	s.sagas[saga.ID].Steps[0].NextRetryAt = &past
+	fire(e, s)
+
+	step := s.saga(saga.ID).Steps[0]
+	if step.RetryCount != 2 || len(c.names) != 2 {
+		t.Fatalf("step retried %d times with %d calls, want 2 retries after 2 calls", step.RetryCount, len(c.names))
+	}
+	timer := s.timer(entity.TimerKindStepRetry)
+	if len(s.timerKinds()) != 1 || timer.FiredAt != nil || !timer.FireAt.After(time.Now()) {
+		t.Errorf("retry timers = %v, fired at %v, due %v, want the one timer armed again", s.timerKinds(), timer.FiredAt, timer.FireAt)
+	}
+}
+
+func TestCompensateChild(t *testing.T) {
+	tests := []struct {
+		name string
+		// child is the status of the sub-saga, empty when none was started
+		child      entity.SagaStatus
+		childPivot bool
+		wantErr    bool
+		wantStatus entity.SagaStatus
+		wantStep   entity.StepStatus
+		wantChild  entity.SagaStatus
+		wantCancel bool
+	}{
+		{
+			name:       "no sub-saga started",
+			wantStatus: entity.SagaStatusCompensated,
+			wantStep:   entity.StepStatusCompensated,
+		},
+		{
+			name:       "compensated sub-saga",
+			child:      entity.SagaStatusCompensated,
+			wantStatus: entity.SagaStatusCompensated,
+			wantStep:   entity.StepStatusCompensated,
+			wantChild:  entity.SagaStatusCompensated,
+		},
+		{
+			name:       "running sub-saga is cancelled",
+			child:      entity.SagaStatusExecuting,
+			wantStatus: entity.SagaStatusCompensating,
+			wantStep:   entity.StepStatusCompensating,
+			wantChild:  entity.SagaStatusExecuting,
+			wantCancel: true,
+		},
+		{
+			name:       "completed sub-saga is reverted",
+			child:      entity.SagaStatusCompleted,
+			wantStatus: entity.SagaStatusCompensating,
+			wantStep:   entity.StepStatusCompensating,
+			wantChild:  entity.SagaStatusCompensating,
+		},
+		{
+			name:       "completed sub-saga past its pivot",
+			child:      entity.SagaStatusCompleted,
+			childPivot: true,
+			wantStatus: entity.SagaStatusFailed,
+			wantStep:   entity.StepStatusCompensationFailed,
+			wantChild:  entity.SagaStatusCompleted,
+		},
+		{
+			name:       "failed sub-saga",
+			child:      entity.SagaStatusFailed,
+			wantStatus: entity.SagaStatusFailed,
+			wantStep:   entity.StepStatusCompensationFailed,
+			wantChild:  entity.SagaStatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &calls{}
+			parentDef := definition.New("order_saga")
+			parentDef.Step("reserve_stock").SubSaga("reservation", emptyRequest, time.Minute)
+			parentDef.Step("process_payment").Action(c.action("process_payment", rejected), emptyRequest)
+
+			childDef := definition.New("reservation")
+			reserve := childDef.Step("reserve").Action(c.action("reserve", nil), emptyRequest)
+			if tt.childPivot {
+				reserve.Pivot()
+			}
+
+			parent := newSaga(t, "order_saga")
+			parent.StartCompensation("step process_payment failed")
+			reserveStock := entity.NewSagaStep(parent.ID, "reserve_stock", 1, 1)
+			reserveStock.Succeed(json.RawMessage(`{}`))
+			payment := entity.NewSagaStep(parent.ID, "process_payment", 2, 2)
+			payment.Fail("card declined")
+			parent.Steps = []*entity.SagaStep{reserveStock, payment}
+
+			sagas := []*entity.Saga{parent}
+			child := newSaga(t, "reservation")
+			if tt.child != "" {
+				child.ParentSagaID = parent.ID
+				child.ParentStep = "reserve_stock"
+				child.Status = tt.child
+				sagas = append(sagas, child)
+			}
+			s := newStore(sagas...)
+
+			if err := newExecutor(t, s, parentDef, childDef).Run(context.Background(), parent.ID); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			got := s.saga(parent.ID)
+			if got.Status != tt.wantStatus || got.Steps[0].Status != tt.wantStep {
+				t.Errorf("saga %s, step %s (%s), want %s, step %s", got.Status, got.Steps[0].Status, got.ErrorMessage, tt.wantStatus, tt.wantStep)
+			}
+			if tt.child != "" {
+				if got := s.saga(child.ID).Status; got != tt.wantChild {
+					t.Errorf("sub-saga status = %s, want %s", got, tt.wantChild)
+				}
+			}
+			if _, cancelled := s.cancels[child.ID]; cancelled != tt.wantCancel {
+				t.Errorf("sub-saga cancel requested = %v, want %v", cancelled, tt.wantCancel)
+			}
+			if len(c.names) > 0 {
+				t.Errorf("calls = %v, want none", c.names)
+			}
+		})
+	}
+}
+
+func TestAsFailed(t *testing.T) {
+	cause := errors.New("card declined")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantFailed bool
+	}{
+		{"failed step", &failedError{cause: cause}, true},
+		{"wrapped failed step", fmt.Errorf("compensate: %w", &failedError{cause: cause}), true},
+		{"parked", errParked, false},
+		{"lease lost", context.Canceled, false},
+		{"no error", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, failed := asFailed(tt.err)
+			if failed != tt.wantFailed {
+				t.Fatalf("asFailed() failed = %v, want %v", failed, tt.wantFailed)
+			}
+			if failed && got != cause {
+				t.Errorf("asFailed() cause = %v, want %v", got, cause)
+			}
+			if failed && !strings.Contains(tt.err.Error(), cause.Error()) {
+				t.Errorf("error %q does not name its cause", tt.err)
+			}
+		})
+	}
+}
+
+func TestSignalClaimed(t *testing.T) {
+	// Reviews are only cleared by a signal that says so
+	review := func(response json.RawMessage) (json.RawMessage, error) {
+		var signal struct {
+			Cleared bool `json:"cleared"`
+		}
+		if err := json.Unmarshal(response, &signal); err != nil || !signal.Cleared {
+			return nil, errors.New("order not cleared")
+		}
+		return response, nil
+	}
+
+	t.Run("step resumed after a crash takes the signal it consumed", func(t *testing.T) {
+		c := &calls{}
+		b := definition.New("order_saga")
+		b.Step("create_order").Action(c.action("create_order", nil), emptyRequest).Compensation(c.action("cancel_order", nil), emptyRequest)
+		b.Step("fraud_review").AwaitSignal("fraud_review", time.Hour).Response(review)
+
+		saga := newSaga(t, "order_saga")
+		s := newStore(saga)
+		s.signals.push(t, saga.ID, "fraud_review", `{"cleared":true,"analyst":"first"}`)
+		s.signals.push(t, saga.ID, "fraud_review", `{"cleared":true,"analyst":"second"}`)
+		// The owner crashed once the signal was consumed, before the step
+		// was written
+		var crashed bool
+		s.signals.onClaim = func() {
+			if !crashed {
+				crashed = true
+				s.steal(saga.ID)
+			}
+		}
+		e := newExecutor(t, s, b)
+
+		if err := e.Run(context.Background(), saga.ID); err == nil {
+			t.Fatal("Run() succeeded after the lease was lost")
+		}
+		if err := e.Run(context.Background(), saga.ID); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+
+		got := s.saga(saga.ID)
+		if got.Status != entity.SagaStatusCompleted {
+			t.Fatalf("saga status = %s (%s), want COMPLETED", got.Status, got.ErrorMessage)
+		}
+		if output := string(got.Steps[1].ResponsePayload); !strings.Contains(output, "first") {
+			t.Errorf("step output = %s, want the first signal", output)
+		}
+		if consumed := s.signals.consumed(); len(consumed) != 1 {
+			t.Errorf("consumed signals = %v, want only the first", consumed)
+		}
+	})
+
+	t.Run("step waiting again takes the next signal", func(t *testing.T) {
+		c := &calls{}
+		b := definition.New("order_saga")
+		b.Step("process_payment").Action(c.action("process_payment", nil), emptyRequest).Pivot()
+		b.Step("fraud_review").AwaitSignal("fraud_review", time.Hour).Response(review)
+
+		saga := newSaga(t, "order_saga")
+		s := newStore(saga)
+		s.signals.push(t, saga.ID, "fraud_review", `{"cleared":false}`)
+		s.signals.push(t, saga.ID, "fraud_review", `{"cleared":true}`)
+		e := newExecutor(t, s, b)
+
+		// The first signal fails the step past the pivot, it waits again
+		if err := e.Run(context.Background(), saga.ID); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if got := s.saga(saga.ID); got.Status != entity.SagaStatusForwardRecovering {
+			t.Fatalf("saga status = %s (%s), want FORWARD_RECOVERING", got.Status, got.ErrorMessage)
+		}
+
+		s.elapse(entity.TimerKindStepRetry)
+		past := time.Now().Add(-time.Second)
+		s.sagas[saga.ID].Steps[1].NextRetryAt = &past
+		fire(e, s)
+
+		got := s.saga(saga.ID)
+		if got.Status != entity.SagaStatusCompleted {
+			t.Fatalf("saga status = %s (%s), want COMPLETED", got.Status, got.ErrorMessage)
+		}
+		if output := string(got.Steps[1].ResponsePayload); output != `{"cleared":true}` {
+			t.Errorf("step output = %s, want the second signal", output)
+		}
+		if consumed := s.signals.consumed(); len(consumed) != 2 {
+			t.Errorf("consumed signals = %v, want both", consumed)
+		}
+	})
+}
+
+func TestApprovalDecided(t *testing.T) {
+	tests := []struct {
+		name       string
+		approved   bool
+		wantStatus entity.SagaStatus
+		wantSteps  []entity.StepStatus
+		wantCalls  []string
+		wantError  string
+	}{
+		{
+			name:       "approved proceeds",
+			approved:   true,
+			wantStatus: entity.SagaStatusCompleted,
+			wantSteps:  []entity.StepStatus{entity.StepStatusSucceeded, entity.StepStatusSucceeded, entity.StepStatusSucceeded},
+			wantCalls:  []string{"create_order", "process_payment"},
+		},
+		{
+			name:       "rejected compensates",
+			wantStatus: entity.SagaStatusCompensated,
+			wantSteps:  []entity.StepStatus{entity.StepStatusCompensated, entity.StepStatusFailed, entity.StepStatusPending},
+			wantCalls:  []string{"create_order", "cancel_order"},
+			wantError:  "rejected by alice: checked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &calls{}
+			b := definition.New("order_saga")
+			b.Step("create_order").Action(c.action("create_order", nil), emptyRequest).Compensation(c.action("cancel_order", nil), emptyRequest)
+			b.Step("fraud_review").Approval(time.Hour)
+			b.Step("process_payment").Action(c.action("process_payment", nil), emptyRequest)
+
+			saga := newSaga(t, "order_saga")
+			s := newStore(saga)
+			e := newExecutor(t, s, b)
+
+			if err := e.Run(context.Background(), saga.ID); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got := s.saga(saga.ID); got.Status != entity.SagaStatusExecuting || got.Steps[1].Status != entity.StepStatusWaiting {
+				t.Fatalf("saga = %s %v, want EXECUTING waiting for the approval", got.Status, stepStatuses(got))
+			}
+
+			s.approvals.decide(t, saga.ID, "fraud_review", tt.approved)
+			if err := e.Run(context.Background(), saga.ID); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			got := s.saga(saga.ID)
+			if got.Status != tt.wantStatus {
+				t.Errorf("saga status = %s (%s), want %s", got.Status, got.ErrorMessage, tt.wantStatus)
+			}
+			if fmt.Sprint(stepStatuses(got)) != fmt.Sprint(tt.wantSteps) {
+				t.Errorf("step statuses = %v, want %v", stepStatuses(got), tt.wantSteps)
+			}
+			if fmt.Sprint(c.names) != fmt.Sprint(tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", c.names, tt.wantCalls)
+			}
+			if tt.wantError != "" && got.Steps[1].ErrorMessage != tt.wantError {
+				t.Errorf("step error = %q, want %q", got.Steps[1].ErrorMessage, tt.wantError)
+			}
+		})
+	}
+}
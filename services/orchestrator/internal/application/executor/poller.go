@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// Finder returns up to limit ids of sagas that need to be run
+type Finder func(ctx context.Context, limit int) ([]string, error)
+
+// Poller periodically looks for sagas without a valid lease and hands them to
+// the executor, which claims the lease before running them.
+type Poller struct {
+	name      string
+	find      Finder
+	executor  *Executor
+	interval  time.Duration
+	batchSize int
+	logger    *logger.Logger
+}
+
+// NewPendingPoller starts new sagas.
+// A saga is started by inserting a PENDING row into the sagas table.
+func NewPendingPoller(repo repository.SagaRepository, executor *Executor, interval time.Duration, batchSize int, log *logger.Logger) *Poller {
+	return &Poller{
+		name:      "pending",
+		find:      repo.FindPending,
+		executor:  executor,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    log,
+	}
+}
+
+// NewRecoverySweeper resumes EXECUTING and COMPENSATING sagas whose owner
+// crashed (its lease expired) or gave up (its lease was released). The
+// executor continues from the last persisted step status and re-sends the
+// same idempotency keys, so downstream services deduplicate the calls that
+// were in flight when the owner died.
+func NewRecoverySweeper(repo repository.SagaRepository, executor *Executor, interval time.Duration, batchSize int, log *logger.Logger) *Poller {
+	return &Poller{
+		name:      "recovery",
+		find:      repo.FindStuck,
+		executor:  executor,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    log,
+	}
+}
+
+// NewTimerPoller fires due saga timers and resumes their sagas: parked
+// retries are re-sent and sagas past a deadline are compensated.
+func NewTimerPoller(timers repository.TimerRepository, executor *Executor, interval time.Duration, batchSize int, log *logger.Logger) *Poller {
+	return &Poller{
+		name: "timer",
+		find: func(ctx context.Context, limit int) ([]string, error) {
+			fired, err := timers.FireDue(ctx, limit)
+			if err != nil {
+				return nil, err
+			}
+
+			// Several timers of one saga may fire together, it is run once
+			var ids []string
+			seen := make(map[string]bool, len(fired))
+			for _, timer := range fired {
+				log.Info().
+					Str("saga_id", timer.SagaID).
+					Str("kind", string(timer.Kind)).
+					Msg("Saga timer fired")
+
+				if !seen[timer.SagaID] {
+					seen[timer.SagaID] = true
+					ids = append(ids, timer.SagaID)
+				}
+			}
+			return ids, nil
+		},
+		executor:  executor,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    log,
+	}
+}
+
+// Run polls until ctx is cancelled. Sagas of one batch run concurrently and
+// the next batch is fetched once they all returned.
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.poll(ctx)
+		}
+	}
+}
+
+func (p *Poller) poll(ctx context.Context) {
+	ids, err := p.find(ctx, p.batchSize)
+	if err != nil {
+		p.logger.Error().Err(err).Str("poller", p.name).Msg("Failed to find sagas")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			if err := p.executor.Run(ctx, id); err != nil {
+				p.logger.Error().Err(err).Str("poller", p.name).Str("saga_id", id).Msg("Saga execution interrupted")
+			}
+		}(id)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,109 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// Manager hands out saga leases to this orchestrator instance and keeps them
+// alive with a heartbeat, so several replicas can run side by side without
+// driving the same saga twice.
+type Manager struct {
+	repo    repository.LockRepository
+	ownerID string
+	ttl     time.Duration
+	logger  *logger.Logger
+
+	mu   sync.Mutex
+	lost map[*entity.Lease]bool
+}
+
+func NewManager(repo repository.LockRepository, ownerID string, ttl time.Duration, log *logger.Logger) *Manager {
+	return &Manager{
+		repo:    repo,
+		ownerID: ownerID,
+		ttl:     ttl,
+		logger:  log,
+		lost:    make(map[*entity.Lease]bool),
+	}
+}
+
+// Acquire claims the lease of a saga. It returns nil when another
+// instance currently holds it.
+func (m *Manager) Acquire(ctx context.Context, sagaID string) (*entity.Lease, error) {
+	return m.repo.Acquire(ctx, sagaID, m.ownerID, m.ttl)
+}
+
+// KeepAlive renews the lease in the background until stop is called.
+// The returned context is cancelled as soon as the lease is lost, which
+// aborts any step still in flight.
+func (m *Manager) KeepAlive(ctx context.Context, lease *entity.Lease) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		// Renew well before expiry so a slow round trip does not lose the lease
+		ticker := time.NewTicker(m.ttl / 3)
+		defer ticker.Stop()
+
+		expiresAt := lease.ExpiresAt
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := m.repo.Renew(ctx, lease, m.ttl)
+				if err == nil {
+					expiresAt = time.Now().Add(m.ttl)
+					continue
+				}
+				if ctx.Err() != nil {
+					return
+				}
+
+				// A transient database error is retried on the next tick as
+				// long as the lease has not expired yet
+				var e *pErrors.Error
+				if errors.As(err, &e) && e.Code != pErrors.Conflict && time.Now().Before(expiresAt) {
+					m.logger.Warn().Err(err).Str("saga_id", lease.SagaID).Msg("Failed to renew saga lease")
+					continue
+				}
+
+				m.logger.Warn().Err(err).Str("saga_id", lease.SagaID).Msg("Saga lease lost")
+				m.mu.Lock()
+				m.lost[lease] = true
+				m.mu.Unlock()
+				cancel()
+				return
+			}
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// Release drops the lease. It runs on its own short timeout so a lease is
+// still handed back when the caller's context is already cancelled. A lease
+// lost meanwhile may be held by another owner already and is left alone.
+func (m *Manager) Release(lease *entity.Lease) {
+	m.mu.Lock()
+	lost := m.lost[lease]
+	delete(m.lost, lease)
+	m.mu.Unlock()
+	if lost {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.repo.Release(ctx, lease); err != nil {
+		m.logger.Warn().Err(err).Str("saga_id", lease.SagaID).Msg("Failed to release saga lease")
+	}
+}
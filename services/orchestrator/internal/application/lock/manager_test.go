@@ -0,0 +1,119 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+// locks is a LockRepository whose renewals fail with renewErr
+type locks struct {
+	mu       sync.Mutex
+	renewErr error
+	renewals int
+	released int
+}
+
+func (l *locks) Acquire(ctx context.Context, sagaID, ownerID string, ttl time.Duration) (*entity.Lease, error) {
+	return &entity.Lease{SagaID: sagaID, OwnerID: ownerID, Token: 1, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+func (l *locks) Renew(ctx context.Context, lease *entity.Lease, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.renewals++
+	return l.renewErr
+}
+
+func (l *locks) Release(ctx context.Context, lease *entity.Lease) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released++
+	return nil
+}
+
+func (l *locks) counts() (renewals, released int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.renewals, l.released
+}
+
+func TestKeepAlive(t *testing.T) {
+	const ttl = 30 * time.Millisecond
+	log := logger.New("orchestrator-test")
+
+	tests := []struct {
+		name     string
+		renewErr error
+		// expiresIn is how long the lease is valid when acquired
+		expiresIn time.Duration
+		wantLost  bool
+	}{
+		{
+			name:      "renewed",
+			expiresIn: ttl,
+		},
+		{
+			name:      "lost to another owner",
+			renewErr:  pErrors.E(pErrors.Conflict, "saga lease lost", nil),
+			expiresIn: ttl,
+			wantLost:  true,
+		},
+		{
+			name:      "transient failure before expiry",
+			renewErr:  pErrors.E(pErrors.Internal, "failed to renew saga lock", nil),
+			expiresIn: time.Hour,
+		},
+		{
+			name:      "transient failure past expiry",
+			renewErr:  pErrors.E(pErrors.Internal, "failed to renew saga lock", nil),
+			expiresIn: 0,
+			wantLost:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &locks{renewErr: tt.renewErr}
+			m := NewManager(repo, "orchestrator-1", ttl, log)
+
+			lease, err := m.Acquire(context.Background(), "saga-1")
+			if err != nil {
+				t.Fatalf("Acquire() error = %v", err)
+			}
+			lease.ExpiresAt = time.Now().Add(tt.expiresIn)
+
+			ctx, stop := m.KeepAlive(context.Background(), lease)
+
+			select {
+			case <-ctx.Done():
+				if !tt.wantLost {
+					t.Fatal("run context cancelled, want the lease kept")
+				}
+			case <-time.After(5 * ttl):
+				if tt.wantLost {
+					t.Fatal("run context not cancelled after the lease was lost")
+				}
+			}
+			stop()
+
+			if renewals, _ := repo.counts(); renewals == 0 {
+				t.Error("lease never renewed")
+			}
+
+			m.Release(lease)
+			wantReleased := 1
+			if tt.wantLost {
+				wantReleased = 0
+			}
+			if _, released := repo.counts(); released != wantReleased {
+				t.Errorf("Release() released %d leases, want %d", released, wantReleased)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package saga
+
+import (
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+)
+
+// The checkout composed of reusable sagas:
+// create order -> charge customer and allocate stock as sub-sagas
+// concurrently -> confirm order
+//
+// It takes the payload of OrderSagaType. When the checkout is compensated,
+// the sub-sagas that completed are compensated too, so none of them has a
+// pivot.
+const (
+	CheckoutSagaType       = "checkout"
+	ChargeCustomerSagaType = "charge_customer"
+	AllocateStockSagaType  = "allocate_stock"
+)
+
+// subSagaTimeout bounds how long the checkout waits for one of its sub-sagas
+const subSagaTimeout = time.Minute
+
+// ChargeCustomerSaga declares the payment of an order. Its payload is
+//
+//	{"order_id": "...", "customer_id": "...", "amount": 9.99}
+func ChargeCustomerSaga(payments PaymentService) (*definition.Definition, error) {
+	b := definition.New(ChargeCustomerSagaType).Deadline(time.Minute)
+
+	b.Step("process_payment").
+		ActionTemplate(payments.ProcessPayment, definition.Template{
+			"order_id":    definition.Ref("payload.order_id"),
+			"customer_id": definition.Ref("payload.customer_id"),
+			"amount":      definition.Ref("payload.amount"),
+		}).
+		CompensationTemplate(payments.RefundPayment, definition.Template{
+			"payment_id": definition.Ref("steps.process_payment.payment_id"),
+		}).
+		Retry(definition.DefaultRetryPolicy)
+
+	return b.Build()
+}
+
+// AllocateStockSaga declares the inventory reservation of an order. Its
+// payload is
+//
+//	{"order_id": "...", "items": [{"product_id": "...", "quantity": 1}]}
+func AllocateStockSaga(inventory InventoryService) (*definition.Definition, error) {
+	b := definition.New(AllocateStockSagaType).Deadline(time.Minute)
+
+	b.Step("reserve_inventory").
+		ActionTemplate(inventory.ReserveInventory, definition.Template{
+			"order_id": definition.Ref("payload.order_id"),
+			"items":    definition.Ref("payload.items"),
+		}).
+		CompensationTemplate(inventory.ReleaseInventory, definition.Template{
+			"order_id": definition.Ref("payload.order_id"),
+		}).
+		Retry(definition.DefaultRetryPolicy)
+
+	return b.Build()
+}
+
+// CheckoutSaga declares the checkout from the charge_customer and
+// allocate_stock sagas, which must be registered as well
+func CheckoutSaga(orders OrderService) (*definition.Definition, error) {
+	b := definition.New(CheckoutSagaType).Deadline(3 * time.Minute)
+
+	b.Step("create_order").
+		ActionTemplate(orders.CreateOrder, definition.Template{
+			"customer_id": definition.Ref("payload.customer_id"),
+			"items": definition.ForEach("payload.items", definition.Template{
+				"product_id": definition.Ref("item.product_id"),
+				"quantity":   definition.Ref("item.quantity"),
+				"price":      definition.Ref("item.price"),
+			}),
+			"total_amount": definition.Ref("payload.total_amount"),
+		}).
+		CompensationTemplate(orders.CancelOrder, definition.Template{
+			"order_id": definition.Ref("steps.create_order.order_id"),
+		}).
+		Retry(definition.DefaultRetryPolicy)
+
+	g := b.Parallel()
+
+	g.Step("charge_customer").
+		SubSagaTemplate(ChargeCustomerSagaType, definition.Template{
+			"order_id":    definition.Ref("steps.create_order.order_id"),
+			"customer_id": definition.Ref("payload.customer_id"),
+			"amount":      definition.Ref("payload.total_amount"),
+		}, subSagaTimeout).
+		When(definition.GreaterThan("payload.total_amount", 0))
+
+	g.Step("allocate_stock").
+		SubSagaTemplate(AllocateStockSagaType, definition.Template{
+			"order_id": definition.Ref("steps.create_order.order_id"),
+			"items": definition.ForEach("payload.items", definition.Template{
+				"product_id": definition.Ref("item.product_id"),
+				"quantity":   definition.Ref("item.quantity"),
+			}),
+		}, subSagaTimeout).
+		When(definition.NotEquals("payload.digital", true))
+
+	b.Step("confirm_order").
+		ActionTemplate(orders.ConfirmOrder, definition.Template{
+			"order_id": definition.Ref("steps.create_order.order_id"),
+		}).
+		Retry(definition.DefaultRetryPolicy)
+
+	return b.Build()
+}
@@ -0,0 +1,24 @@
+package saga
+
+import "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+
+// OrderChoreography declares the checkout flow when the services run it
+// themselves from each other's events:
+//
+//	OrderCreated -> InventoryReserved -> PaymentProcessed -> OrderConfirmed
+//
+// A failed reservation cancels the order; a failed payment releases the
+// stock and cancels the order. The events are keyed by the order id, which
+// is the correlation id of the run.
+func OrderChoreography() *definition.Choreography {
+	return &definition.Choreography{
+		Type:     OrderSagaType,
+		Start:    "OrderCreated",
+		Complete: "OrderConfirmed",
+		Failures: []string{"InventoryReservationFailed", "PaymentFailed"},
+		Compensations: map[string]string{
+			"OrderCreated":      "OrderCancelled",
+			"InventoryReserved": "InventoryReleased",
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+)
+
+// OrderSagaType is the saga_type of the checkout flow:
+// create order -> fraud review -> process payment and reserve inventory
+// concurrently -> confirm order
+//
+// The saga payload is
+//
+//	{
+//	  "customer_id": "...",
+//	  "items": [{"product_id": "...", "quantity": 1, "price": 9.99}],
+//	  "total_amount": 9.99,
+//	  "digital": false
+//	}
+//
+// Zero-amount orders skip the payment, digital orders (digital: true) skip
+// the inventory reservation. Orders above the fraud review threshold wait for
+// an analyst to approve them; a rejected order is cancelled. Payment is the pivot: once it is captured and
+// the inventory reserved, the order is confirmed however long it takes.
+//
+// Sagas keep running against the version of the definition they started
+// with: a change to the steps is registered as a new version (Builder.Version)
+// next to the old one, which stays registered until ListDefinitionVersions
+// reports no saga left on it.
+const OrderSagaType = "order_saga"
+
+// FraudReview sends orders above Threshold to a fraud analyst before they
+// are paid. An order nobody reviewed within Expiry is rejected.
+type FraudReview struct {
+	Threshold float64
+	Expiry    time.Duration
+}
+
+// OrderService is the subset of the order service used by the saga
+type OrderService interface {
+	CreateOrder(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error)
+	CancelOrder(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error)
+	ConfirmOrder(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error)
+}
+
+// PaymentService is the subset of the payment service used by the saga
+type PaymentService interface {
+	ProcessPayment(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error)
+	RefundPayment(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error)
+}
+
+// InventoryService is the subset of the inventory service used by the saga
+type InventoryService interface {
+	ReserveInventory(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error)
+	ReleaseInventory(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error)
+}
+
+// OrderSaga declares the checkout flow
+func OrderSaga(orders OrderService, payments PaymentService, inventory InventoryService, review FraudReview) (*definition.Definition, error) {
+	// The deadline leaves the analysts the whole review to decide
+	b := definition.New(OrderSagaType).Deadline(2*time.Minute + review.Expiry)
+
+	b.Step("create_order").
+		ActionTemplate(orders.CreateOrder, definition.Template{
+			"customer_id": definition.Ref("payload.customer_id"),
+			"items": definition.ForEach("payload.items", definition.Template{
+				"product_id": definition.Ref("item.product_id"),
+				"quantity":   definition.Ref("item.quantity"),
+				"price":      definition.Ref("item.price"),
+			}),
+			"total_amount": definition.Ref("payload.total_amount"),
+		}).
+		CompensationTemplate(orders.CancelOrder, definition.Template{
+			"order_id": definition.Ref("steps.create_order.order_id"),
+		}).
+		Retry(definition.DefaultRetryPolicy)
+
+	b.Step("fraud_review").
+		Approval(review.Expiry).
+		When(definition.GreaterThan("payload.total_amount", review.Threshold))
+
+	// Payment and inventory only need the order, they run concurrently
+	g := b.Parallel()
+
+	g.Step("process_payment").
+		ActionTemplate(payments.ProcessPayment, definition.Template{
+			"order_id":    definition.Ref("steps.create_order.order_id"),
+			"customer_id": definition.Ref("payload.customer_id"),
+			"amount":      definition.Ref("payload.total_amount"),
+		}).
+		CompensationTemplate(payments.RefundPayment, definition.Template{
+			"payment_id": definition.Ref("steps.process_payment.payment_id"),
+		}).
+		When(definition.GreaterThan("payload.total_amount", 0)).
+		Retry(definition.DefaultRetryPolicy).
+		Pivot()
+
+	g.Step("reserve_inventory").
+		ActionTemplate(inventory.ReserveInventory, definition.Template{
+			"order_id": definition.Ref("steps.create_order.order_id"),
+			"items": definition.ForEach("payload.items", definition.Template{
+				"product_id": definition.Ref("item.product_id"),
+				"quantity":   definition.Ref("item.quantity"),
+			}),
+		}).
+		CompensationTemplate(inventory.ReleaseInventory, definition.Template{
+			"order_id": definition.Ref("steps.create_order.order_id"),
+		}).
+		When(definition.NotEquals("payload.digital", true)).
+		Retry(definition.DefaultRetryPolicy)
+
+	b.Step("confirm_order").
+		ActionTemplate(orders.ConfirmOrder, definition.Template{
+			"order_id": definition.Ref("steps.create_order.order_id"),
+		}).
+		Retry(definition.DefaultRetryPolicy)
+
+	return b.Build()
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// CancelSagaUseCase requests cancellation of a running saga. The executor
+// holding the saga lease honours it before the next step and compensates
+// the steps that already succeeded. A saga past its pivot step can no longer
+// be cancelled.
+type CancelSagaUseCase struct {
+	repo     repository.SagaRepository
+	registry *definition.Registry
+	logger   *logger.Logger
+}
+
+// NewCancelSagaUseCase creates a new use case
+func NewCancelSagaUseCase(repo repository.SagaRepository, registry *definition.Registry, logger *logger.Logger) *CancelSagaUseCase {
+	return &CancelSagaUseCase{
+		repo:     repo,
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// Execute runs the use case
+func (uc *CancelSagaUseCase) Execute(ctx context.Context, sagaID string, reason string) (*dto.SagaResponse, error) {
+	saga, err := uc.repo.FindByID(ctx, sagaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if saga.IsTerminal() {
+		return nil, pErrors.E(pErrors.Conflict, "saga already finished with status "+string(saga.Status), nil)
+	}
+
+	if uc.irreversible(saga) {
+		return nil, pErrors.E(pErrors.Conflict, "saga passed its pivot step and can only move forward", nil)
+	}
+
+	// The reason becomes the error message of the compensated saga
+	message := "saga cancelled"
+	if reason != "" {
+		message = "saga cancelled: " + reason
+	}
+
+	if err := uc.repo.RequestCancel(ctx, sagaID, message); err != nil {
+		return nil, err
+	}
+
+	uc.logger.InfoWithTrace(ctx).
+		Str("saga_id", sagaID).
+		Str("reason", reason).
+		Msg("Saga cancellation requested")
+
+	return toSagaDTO(saga), nil
+}
+
+// irreversible reports whether the saga passed the pivot of its definition.
+// A saga of an unknown type is left to the executor, which fails it.
+func (uc *CancelSagaUseCase) irreversible(saga *entity.Saga) bool {
+	if saga.Status == entity.SagaStatusForwardRecovering {
+		return true
+	}
+	def, err := uc.registry.GetVersion(saga.Type, saga.DefinitionVersion)
+	if err != nil {
+		return false
+	}
+	pivot := def.PivotGroup()
+	return pivot > 0 && saga.GroupsDone(pivot)
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// CompleteStepUseCase records the completion of an asynchronous step,
+// reported by a callback or an event. The executor picks it up when it
+// resumes the saga, which the completion wakes up.
+type CompleteStepUseCase struct {
+	repo        repository.SagaRepository
+	completions repository.CompletionRepository
+	registry    *definition.Registry
+	logger      *logger.Logger
+}
+
+// NewCompleteStepUseCase creates a new use case
+func NewCompleteStepUseCase(repo repository.SagaRepository, completions repository.CompletionRepository, registry *definition.Registry, log *logger.Logger) *CompleteStepUseCase {
+	return &CompleteStepUseCase{repo: repo, completions: completions, registry: registry, logger: log}
+}
+
+// Execute runs the use case. A completion may arrive before the command
+// returned; it is refused once the step no longer waits for one.
+func (uc *CompleteStepUseCase) Execute(ctx context.Context, req dto.CompleteStepRequest) (*dto.CompleteStepResponse, error) {
+	completion, err := entity.NewStepCompletion(req.SagaID, req.StepName, req.Payload, req.ErrorMessage, req.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	saga, err := uc.repo.FindByID(ctx, req.SagaID)
+	if err != nil {
+		return nil, err
+	}
+
+	def, err := uc.registry.GetVersion(saga.Type, saga.DefinitionVersion)
+	if err != nil {
+		return nil, err
+	}
+	stepDef, ok := def.Step(req.StepName)
+	if !ok {
+		return nil, pErrors.E(pErrors.NotFound, "saga "+saga.Type+" has no step "+req.StepName, nil)
+	}
+	if !stepDef.IsAsync() {
+		return nil, pErrors.E(pErrors.Invalid, "step "+req.StepName+" is not asynchronous", nil)
+	}
+
+	// Steps are only materialized once the saga runs
+	if step, ok := saga.Step(req.StepName); ok {
+		switch step.Status {
+		case entity.StepStatusPending, entity.StepStatusExecuting, entity.StepStatusWaiting:
+		default:
+			// A callback delivered again after its step finished is a duplicate
+			existing, err := uc.completions.Find(ctx, req.SagaID, req.StepName)
+			if err != nil {
+				return nil, err
+			}
+			if existing == nil {
+				return nil, pErrors.E(pErrors.Conflict, "step "+req.StepName+" is "+string(step.Status)+", it does not wait for a completion", nil)
+			}
+			return &dto.CompleteStepResponse{SagaID: req.SagaID, StepName: req.StepName, Duplicate: true}, nil
+		}
+	}
+
+	recorded, err := uc.completions.Record(ctx, completion)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.InfoWithTrace(ctx).
+		Str("saga_id", req.SagaID).
+		Str("step", req.StepName).
+		Str("source", req.Source).
+		Bool("succeeded", completion.Succeeded()).
+		Bool("duplicate", !recorded).
+		Msg("Step completion received")
+
+	return &dto.CompleteStepResponse{
+		SagaID:    req.SagaID,
+		StepName:  req.StepName,
+		Duplicate: !recorded,
+	}, nil
+}
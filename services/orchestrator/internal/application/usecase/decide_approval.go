@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// DecideApprovalUseCase records the decision of an approver on an approval
+// step. The executor picks it up when it resumes the saga, which the
+// decision wakes up: an approval lets the saga proceed, a rejection
+// compensates it.
+type DecideApprovalUseCase struct {
+	repo      repository.SagaRepository
+	approvals repository.ApprovalRepository
+	logger    *logger.Logger
+}
+
+// NewDecideApprovalUseCase creates a new use case
+func NewDecideApprovalUseCase(repo repository.SagaRepository, approvals repository.ApprovalRepository, log *logger.Logger) *DecideApprovalUseCase {
+	return &DecideApprovalUseCase{repo: repo, approvals: approvals, logger: log}
+}
+
+// Execute runs the use case
+func (uc *DecideApprovalUseCase) Execute(ctx context.Context, req dto.DecideApprovalRequest) (*dto.ApprovalDTO, error) {
+	saga, err := uc.repo.FindByID(ctx, req.SagaID)
+	if err != nil {
+		return nil, err
+	}
+
+	approval, err := uc.approvals.Find(ctx, req.SagaID, req.StepName)
+	if err != nil {
+		return nil, err
+	}
+	if approval == nil {
+		return nil, pErrors.E(pErrors.NotFound, "step "+req.StepName+" did not request an approval", nil)
+	}
+
+	// A step given up by a cancellation takes no decision any more
+	if step, ok := saga.Step(req.StepName); ok && step.Status != entity.StepStatusWaiting && approval.Status == entity.ApprovalStatusPending {
+		return nil, pErrors.E(pErrors.Conflict, "step "+req.StepName+" is "+string(step.Status)+", it does not wait for an approval", nil)
+	}
+
+	if err := approval.Decide(req.Approved, req.ApproverID, req.Comment); err != nil {
+		return nil, err
+	}
+	if err := uc.approvals.Decide(ctx, approval); err != nil {
+		return nil, err
+	}
+
+	uc.logger.InfoWithTrace(ctx).
+		Str("saga_id", req.SagaID).
+		Str("step", req.StepName).
+		Str("approver_id", req.ApproverID).
+		Str("status", string(approval.Status)).
+		Msg("Approval decided")
+
+	resp := toApprovalDTO(approval)
+	return &resp, nil
+}
+
+func toApprovalDTO(approval *entity.Approval) dto.ApprovalDTO {
+	return dto.ApprovalDTO{
+		SagaID:      approval.SagaID,
+		StepName:    approval.StepName,
+		SagaType:    approval.SagaType,
+		SagaPayload: approval.SagaPayload,
+		Status:      string(approval.Status),
+		ApproverID:  approval.ApproverID,
+		Comment:     approval.Comment,
+		RequestedAt: approval.RequestedAt,
+		ExpiresAt:   approval.ExpiresAt,
+		DecidedAt:   approval.DecidedAt,
+	}
+}
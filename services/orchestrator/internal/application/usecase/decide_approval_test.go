@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// approvals keeps the approvals of steps in memory and the decisions stored
+type approvals struct {
+	mu      sync.Mutex
+	stored  map[string]*entity.Approval
+	decided []*entity.Approval
+}
+
+func (a *approvals) Request(ctx context.Context, approval *entity.Approval) error {
+	return nil
+}
+
+func (a *approvals) Find(ctx context.Context, sagaID, stepName string) (*entity.Approval, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	approval, ok := a.stored[sagaID+"/"+stepName]
+	if !ok {
+		return nil, nil
+	}
+	c := *approval
+	return &c, nil
+}
+
+func (a *approvals) Decide(ctx context.Context, approval *entity.Approval) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.decided = append(a.decided, approval)
+	return nil
+}
+
+func (a *approvals) Expire(ctx context.Context, sagaID, stepName string) (bool, error) {
+	return false, nil
+}
+
+func (a *approvals) ListPending(ctx context.Context, filter repository.ApprovalFilter) ([]*entity.Approval, error) {
+	return nil, nil
+}
+
+func TestDecideApproval(t *testing.T) {
+	log := logger.New("test")
+
+	tests := []struct {
+		name       string
+		step       entity.StepStatus
+		approval   func(approval *entity.Approval) // nil when none was requested
+		approverID string
+		wantCode   pErrors.Code
+		wantStatus entity.ApprovalStatus
+	}{
+		{
+			name:       "approved",
+			step:       entity.StepStatusWaiting,
+			approval:   func(approval *entity.Approval) {},
+			approverID: "alice",
+			wantStatus: entity.ApprovalStatusApproved,
+		},
+		{
+			name:     "no approval requested",
+			step:     entity.StepStatusWaiting,
+			wantCode: pErrors.NotFound,
+		},
+		{
+			name:     "approver missing",
+			step:     entity.StepStatusWaiting,
+			approval: func(approval *entity.Approval) {},
+			wantCode: pErrors.Invalid,
+		},
+		{
+			name: "decided after the approval expired",
+			step: entity.StepStatusWaiting,
+			approval: func(approval *entity.Approval) {
+				approval.ExpiresAt = time.Now().Add(-time.Minute)
+			},
+			approverID: "alice",
+			wantCode:   pErrors.Conflict,
+		},
+		{
+			name: "already decided",
+			step: entity.StepStatusWaiting,
+			approval: func(approval *entity.Approval) {
+				approval.Decide(false, "bob", "too risky")
+			},
+			approverID: "alice",
+			wantCode:   pErrors.Conflict,
+		},
+		{
+			name:       "step given up by a cancellation",
+			step:       entity.StepStatusCompensated,
+			approval:   func(approval *entity.Approval) {},
+			approverID: "alice",
+			wantCode:   pErrors.Conflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(newSaga(t, entity.SagaStatusExecuting, entity.StepStatusSucceeded, tt.step))
+			repo := &approvals{stored: make(map[string]*entity.Approval)}
+			if tt.approval != nil {
+				approval := entity.NewApproval("saga-1", "charge", time.Now().Add(time.Hour))
+				tt.approval(approval)
+				repo.stored["saga-1/charge"] = approval
+			}
+			uc := NewDecideApprovalUseCase(s, repo, log)
+
+			resp, err := uc.Execute(context.Background(), dto.DecideApprovalRequest{
+				SagaID:     "saga-1",
+				StepName:   "charge",
+				Approved:   true,
+				ApproverID: tt.approverID,
+			})
+			wantCode(t, err, tt.wantCode)
+			if err != nil {
+				if len(repo.decided) != 0 {
+					t.Errorf("stored %d decisions, want none", len(repo.decided))
+				}
+				return
+			}
+			if resp.Status != string(tt.wantStatus) || resp.ApproverID != tt.approverID {
+				t.Errorf("response = %s by %q, want %s by %q", resp.Status, resp.ApproverID, tt.wantStatus, tt.approverID)
+			}
+			if len(repo.decided) != 1 {
+				t.Errorf("stored %d decisions, want 1", len(repo.decided))
+			}
+		})
+	}
+}
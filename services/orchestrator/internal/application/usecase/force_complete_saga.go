@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/lock"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// ForceCompleteSagaUseCase ends a saga as COMPLETED once an operator resolved
+// it by hand: a FAILED saga, or a FORWARD_RECOVERING one whose failing step
+// will never succeed. The steps are left as they are.
+type ForceCompleteSagaUseCase struct {
+	intervener
+	timers repository.TimerRepository
+	logger *logger.Logger
+}
+
+// NewForceCompleteSagaUseCase creates a new use case
+func NewForceCompleteSagaUseCase(repo repository.SagaRepository, interventions repository.InterventionRepository, timers repository.TimerRepository, locks *lock.Manager, logger *logger.Logger) *ForceCompleteSagaUseCase {
+	return &ForceCompleteSagaUseCase{
+		intervener: intervener{repo: repo, interventions: interventions, locks: locks},
+		timers:     timers,
+		logger:     logger,
+	}
+}
+
+// Execute runs the use case
+func (uc *ForceCompleteSagaUseCase) Execute(ctx context.Context, req dto.InterventionRequest) (*dto.SagaResponse, error) {
+	result, err := uc.apply(ctx, req, entity.InterventionForceComplete, func(saga *entity.Saga) ([]*entity.SagaStep, error) {
+		if saga.Status != entity.SagaStatusFailed && saga.Status != entity.SagaStatusForwardRecovering {
+			return nil, pErrors.E(pErrors.Conflict, "saga is "+string(saga.Status)+", only FAILED and FORWARD_RECOVERING sagas can be force-completed", nil)
+		}
+
+		// Deleted under the lease: once it is released, a pending retry
+		// of a forward recovering saga would run it again. A saga left
+		// without its timers by a failed change is resumed by the recovery
+		// sweeper.
+		if err := uc.timers.DeleteBySaga(ctx, saga.ID); err != nil {
+			return nil, err
+		}
+
+		saga.Complete()
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.WarnWithTrace(ctx).
+		Str("saga_id", req.SagaID).
+		Str("operator_id", req.OperatorID).
+		Msg("Saga force-completed by operator")
+
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// GetChoreographyUseCase returns the observed progress of a choreographed
+// saga with the events seen for it
+type GetChoreographyUseCase struct {
+	repo repository.ChoreographyRepository
+}
+
+// NewGetChoreographyUseCase creates a new use case
+func NewGetChoreographyUseCase(repo repository.ChoreographyRepository) *GetChoreographyUseCase {
+	return &GetChoreographyUseCase{repo: repo}
+}
+
+// Execute runs the use case
+func (uc *GetChoreographyUseCase) Execute(ctx context.Context, correlationID string) (*dto.ChoreographyResponse, error) {
+	run, err := uc.repo.FindByCorrelationID(ctx, correlationID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ChoreographyResponse{
+		CorrelationID: run.CorrelationID,
+		SagaType:      run.Type,
+		Status:        string(run.Status),
+		ErrorMessage:  run.ErrorMessage,
+		StartedAt:     run.StartedAt,
+		UpdatedAt:     run.UpdatedAt,
+		CompletedAt:   run.CompletedAt,
+		Events:        make([]dto.ChoreographyEventDTO, len(run.Events)),
+	}
+	for i, event := range run.Events {
+		resp.Events[i] = dto.ChoreographyEventDTO{
+			Type:        event.Type,
+			MessageID:   event.MessageID,
+			Payload:     event.Payload,
+			PublishedAt: event.PublishedAt,
+			ReceivedAt:  event.ReceivedAt,
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// GetSagaUseCase returns a saga with the status of each of its steps and the
+// operator interventions on it
+type GetSagaUseCase struct {
+	repo          repository.SagaRepository
+	interventions repository.InterventionRepository
+}
+
+// NewGetSagaUseCase creates a new use case
+func NewGetSagaUseCase(repo repository.SagaRepository, interventions repository.InterventionRepository) *GetSagaUseCase {
+	return &GetSagaUseCase{repo: repo, interventions: interventions}
+}
+
+// Execute runs the use case
+func (uc *GetSagaUseCase) Execute(ctx context.Context, sagaID string) (*dto.SagaResponse, error) {
+	saga, err := uc.repo.FindByID(ctx, sagaID)
+	if err != nil {
+		return nil, err
+	}
+
+	interventions, err := uc.interventions.FindBySaga(ctx, sagaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return withInterventions(toSagaDTO(saga), interventions), nil
+}
+
+// toSagaDTO converts domain entity to DTO
+func toSagaDTO(saga *entity.Saga) *dto.SagaResponse {
+	steps := make([]dto.SagaStepDTO, len(saga.Steps))
+	for i, step := range saga.Steps {
+		steps[i] = dto.SagaStepDTO{
+			Name:          step.Name,
+			Order:         step.Order,
+			Group:         step.Group,
+			Status:        string(step.Status),
+			ErrorMessage:  step.ErrorMessage,
+			RetryCount:    step.RetryCount,
+			Request:       step.RequestPayload,
+			Response:      step.ResponsePayload,
+			ExecutedAt:    step.ExecutedAt,
+			CompensatedAt: step.CompensatedAt,
+			WaitUntil:     step.WaitUntil,
+		}
+	}
+
+	return &dto.SagaResponse{
+		ID:                saga.ID,
+		SagaType:          saga.Type,
+		Status:            string(saga.Status),
+		Payload:           saga.Payload,
+		ErrorMessage:      saga.ErrorMessage,
+		CreatedAt:         saga.CreatedAt,
+		UpdatedAt:         saga.UpdatedAt,
+		CompletedAt:       saga.CompletedAt,
+		Steps:             steps,
+		ParentSagaID:      saga.ParentSagaID,
+		ParentStep:        saga.ParentStep,
+		DefinitionVersion: saga.DefinitionVersion,
+	}
+}
+
+// withInterventions adds the audit trail to a saga DTO
+func withInterventions(saga *dto.SagaResponse, interventions []*entity.Intervention) *dto.SagaResponse {
+	saga.Interventions = make([]dto.InterventionDTO, len(interventions))
+	for i, intervention := range interventions {
+		saga.Interventions[i] = dto.InterventionDTO{
+			StepName:       intervention.StepName,
+			Action:         string(intervention.Action),
+			OperatorID:     intervention.OperatorID,
+			Note:           intervention.Note,
+			PreviousStatus: string(intervention.PreviousStatus),
+			CreatedAt:      intervention.CreatedAt,
+		}
+	}
+	return saga
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// GetSagaTimelineUseCase returns every recorded transition of a saga and of
+// its steps, oldest first
+type GetSagaTimelineUseCase struct {
+	repo   repository.SagaRepository
+	events repository.EventRepository
+}
+
+// NewGetSagaTimelineUseCase creates a new use case
+func NewGetSagaTimelineUseCase(repo repository.SagaRepository, events repository.EventRepository) *GetSagaTimelineUseCase {
+	return &GetSagaTimelineUseCase{repo: repo, events: events}
+}
+
+// Execute runs the use case
+func (uc *GetSagaTimelineUseCase) Execute(ctx context.Context, sagaID string) (*dto.SagaTimelineResponse, error) {
+	// Fails with NotFound for an unknown saga rather than returning no events
+	if _, err := uc.repo.FindByID(ctx, sagaID); err != nil {
+		return nil, err
+	}
+
+	events, err := uc.events.FindBySaga(ctx, sagaID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.SagaTimelineResponse{
+		SagaID: sagaID,
+		Events: make([]dto.SagaEventDTO, len(events)),
+	}
+	for i, event := range events {
+		resp.Events[i] = dto.SagaEventDTO{
+			Sequence:  event.Sequence,
+			StepName:  event.StepName,
+			Type:      string(event.Type),
+			Data:      event.Data,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/lock"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// intervener applies operator actions to sagas. It holds the saga lease while
+// it does, so an action never races with an executor, and records every
+// action in the audit trail together with its changes.
+type intervener struct {
+	repo          repository.SagaRepository
+	interventions repository.InterventionRepository
+	locks         *lock.Manager
+}
+
+// change applies an action to the saga and returns the steps it changed
+type change func(saga *entity.Saga) ([]*entity.SagaStep, error)
+
+func (i intervener) apply(ctx context.Context, req dto.InterventionRequest, action entity.InterventionAction, fn change) (*dto.SagaResponse, error) {
+	if req.OperatorID == "" {
+		return nil, pErrors.E(pErrors.Invalid, "operator id is required", nil)
+	}
+
+	// Fails with NotFound before a lease is taken for an unknown saga
+	if _, err := i.repo.FindByID(ctx, req.SagaID); err != nil {
+		return nil, err
+	}
+
+	lease, err := i.locks.Acquire(ctx, req.SagaID)
+	if err != nil {
+		return nil, err
+	}
+	if lease == nil {
+		return nil, pErrors.E(pErrors.Conflict, "saga is being run, try again later", nil)
+	}
+	defer i.locks.Release(lease)
+
+	// Read again under the lease, so the checks see the latest state
+	saga, err := i.repo.FindByID(ctx, req.SagaID)
+	if err != nil {
+		return nil, err
+	}
+
+	intervention := entity.NewIntervention(saga, req.StepName, action, req.OperatorID, req.Note)
+	steps, err := fn(saga)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.interventions.Record(ctx, lease, saga, steps, intervention); err != nil {
+		return nil, err
+	}
+
+	interventions, err := i.interventions.FindBySaga(ctx, saga.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return withInterventions(toSagaDTO(saga), interventions), nil
+}
+
+// requireFailed checks that the saga was parked as FAILED
+func requireFailed(saga *entity.Saga) error {
+	if saga.Status != entity.SagaStatusFailed {
+		return pErrors.E(pErrors.Conflict, "saga is "+string(saga.Status)+", not FAILED", nil)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/lock"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+func request(step string) dto.InterventionRequest {
+	return dto.InterventionRequest{SagaID: "saga-1", StepName: step, OperatorID: "alice", Note: "refunded by hand"}
+}
+
+// wantAudit checks the only entry of the audit trail
+func wantAudit(t *testing.T, s *store, resp *dto.SagaResponse, action entity.InterventionAction, step string, previous entity.SagaStatus) {
+	t.Helper()
+	if len(s.interventions) != 1 {
+		t.Fatalf("recorded %d interventions, want 1", len(s.interventions))
+	}
+	got := s.interventions[0]
+	if got.Action != action || got.StepName != step || got.OperatorID != "alice" || got.PreviousStatus != previous {
+		t.Errorf("intervention = %s on %q by %s from %s, want %s on %q by alice from %s",
+			got.Action, got.StepName, got.OperatorID, got.PreviousStatus, action, step, previous)
+	}
+	if len(resp.Interventions) != 1 {
+		t.Errorf("response lists %d interventions, want 1", len(resp.Interventions))
+	}
+}
+
+func TestForceCompleteSaga(t *testing.T) {
+	log := logger.New("test")
+
+	tests := []struct {
+		name     string
+		status   entity.SagaStatus
+		held     bool
+		operator string
+		wantCode pErrors.Code
+	}{
+		{name: "failed", status: entity.SagaStatusFailed, operator: "alice"},
+		{name: "forward recovering", status: entity.SagaStatusForwardRecovering, operator: "alice"},
+		{name: "compensating", status: entity.SagaStatusCompensating, operator: "alice", wantCode: pErrors.Conflict},
+		{name: "completed", status: entity.SagaStatusCompleted, operator: "alice", wantCode: pErrors.Conflict},
+		{name: "no operator", status: entity.SagaStatusFailed, wantCode: pErrors.Invalid},
+		{name: "being run", status: entity.SagaStatusFailed, held: true, operator: "alice", wantCode: pErrors.Conflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(newSaga(t, tt.status, entity.StepStatusSucceeded, entity.StepStatusFailed))
+			s.held["saga-1"] = tt.held
+			locks := lock.NewManager(s, "operator", time.Second, log)
+			uc := NewForceCompleteSagaUseCase(s, s, s, locks, log)
+
+			req := request("")
+			req.OperatorID = tt.operator
+			resp, err := uc.Execute(context.Background(), req)
+			wantCode(t, err, tt.wantCode)
+
+			if tt.wantCode != "" {
+				if got := s.saga("saga-1").Status; got != tt.status {
+					t.Errorf("saga status = %s, want %s unchanged", got, tt.status)
+				}
+				if len(s.deleted) != 0 || len(s.interventions) != 0 {
+					t.Errorf("deleted timers of %v and recorded %d interventions, want none", s.deleted, len(s.interventions))
+				}
+				return
+			}
+
+			saga := s.saga("saga-1")
+			if saga.Status != entity.SagaStatusCompleted {
+				t.Errorf("saga status = %s, want COMPLETED", saga.Status)
+			}
+			if got := saga.Steps[1].Status; got != entity.StepStatusFailed {
+				t.Errorf("step charge = %s, want FAILED left as it is", got)
+			}
+			if len(s.deleted) != 1 || s.deleted[0] != "saga-1" {
+				t.Errorf("deleted timers of %v, want [saga-1]", s.deleted)
+			}
+			wantAudit(t, s, resp, entity.InterventionForceComplete, "", tt.status)
+		})
+	}
+}
+
+func TestRetryCompensation(t *testing.T) {
+	log := logger.New("test")
+
+	tests := []struct {
+		name      string
+		saga      func(t *testing.T) *entity.Saga
+		wantCode  pErrors.Code
+		wantSteps []entity.StepStatus
+	}{
+		{
+			name: "failed compensation",
+			saga: func(t *testing.T) *entity.Saga {
+				return newSaga(t, entity.SagaStatusFailed, entity.StepStatusCompensationFailed, entity.StepStatusCompensated)
+			},
+			wantSteps: []entity.StepStatus{entity.StepStatusCompensating, entity.StepStatusCompensated},
+		},
+		{
+			name: "no failed compensation",
+			saga: func(t *testing.T) *entity.Saga {
+				return newSaga(t, entity.SagaStatusFailed, entity.StepStatusCompensated)
+			},
+			wantCode: pErrors.Conflict,
+		},
+		{
+			name: "not failed",
+			saga: func(t *testing.T) *entity.Saga {
+				return newSaga(t, entity.SagaStatusCompensating, entity.StepStatusCompensationFailed)
+			},
+			wantCode: pErrors.Conflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(tt.saga(t))
+			uc := NewRetryCompensationUseCase(s, s, lock.NewManager(s, "operator", time.Second, log), log)
+
+			resp, err := uc.Execute(context.Background(), request(""))
+			wantCode(t, err, tt.wantCode)
+			if tt.wantCode != "" {
+				if len(s.interventions) != 0 {
+					t.Errorf("recorded %d interventions, want none", len(s.interventions))
+				}
+				return
+			}
+
+			saga := s.saga("saga-1")
+			if saga.Status != entity.SagaStatusCompensating {
+				t.Errorf("saga status = %s, want COMPENSATING", saga.Status)
+			}
+			for i, want := range tt.wantSteps {
+				if got := saga.Steps[i].Status; got != want {
+					t.Errorf("step %s = %s, want %s", saga.Steps[i].Name, got, want)
+				}
+			}
+			wantAudit(t, s, resp, entity.InterventionRetryCompensation, "", entity.SagaStatusFailed)
+		})
+	}
+}
+
+func TestMarkStepCompensated(t *testing.T) {
+	log := logger.New("test")
+
+	tests := []struct {
+		name       string
+		steps      []entity.StepStatus
+		step       string
+		wantCode   pErrors.Code
+		wantStatus entity.SagaStatus
+	}{
+		{
+			name:       "last failed compensation",
+			steps:      []entity.StepStatus{entity.StepStatusCompensationFailed, entity.StepStatusCompensated},
+			step:       "reserve",
+			wantStatus: entity.SagaStatusCompensating,
+		},
+		{
+			name:       "another compensation still failed",
+			steps:      []entity.StepStatus{entity.StepStatusCompensationFailed, entity.StepStatusCompensationFailed},
+			step:       "charge",
+			wantStatus: entity.SagaStatusFailed,
+		},
+		{
+			name:     "step compensated already",
+			steps:    []entity.StepStatus{entity.StepStatusCompensationFailed, entity.StepStatusCompensated},
+			step:     "charge",
+			wantCode: pErrors.Conflict,
+		},
+		{
+			name:     "unknown step",
+			steps:    []entity.StepStatus{entity.StepStatusCompensationFailed},
+			step:     "refund",
+			wantCode: pErrors.NotFound,
+		},
+		{
+			name:     "no step",
+			steps:    []entity.StepStatus{entity.StepStatusCompensationFailed},
+			wantCode: pErrors.Invalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(newSaga(t, entity.SagaStatusFailed, tt.steps...))
+			uc := NewMarkStepCompensatedUseCase(s, s, lock.NewManager(s, "operator", time.Second, log), log)
+
+			resp, err := uc.Execute(context.Background(), request(tt.step))
+			wantCode(t, err, tt.wantCode)
+			if tt.wantCode != "" {
+				if len(s.interventions) != 0 {
+					t.Errorf("recorded %d interventions, want none", len(s.interventions))
+				}
+				return
+			}
+
+			saga := s.saga("saga-1")
+			if saga.Status != tt.wantStatus {
+				t.Errorf("saga status = %s, want %s", saga.Status, tt.wantStatus)
+			}
+			if step, _ := saga.Step(tt.step); step.Status != entity.StepStatusCompensated {
+				t.Errorf("step %s = %s, want COMPENSATED", step.Name, step.Status)
+			}
+			wantAudit(t, s, resp, entity.InterventionMarkCompensated, tt.step, entity.SagaStatusFailed)
+		})
+	}
+}
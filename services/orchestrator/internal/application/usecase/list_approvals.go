@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// ListApprovalsUseCase returns the work queue of approvers: the approval
+// steps still waiting for a decision, oldest request first
+type ListApprovalsUseCase struct {
+	approvals repository.ApprovalRepository
+}
+
+// NewListApprovalsUseCase creates a new use case
+func NewListApprovalsUseCase(approvals repository.ApprovalRepository) *ListApprovalsUseCase {
+	return &ListApprovalsUseCase{approvals: approvals}
+}
+
+// Execute runs the use case
+func (uc *ListApprovalsUseCase) Execute(ctx context.Context, req dto.ListApprovalsRequest) (*dto.ListApprovalsResponse, error) {
+	pageSize := req.PageSize
+	if pageSize < 0 {
+		return nil, pErrors.E(pErrors.Invalid, "page size must not be negative", nil)
+	}
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	approvals, err := uc.approvals.ListPending(ctx, repository.ApprovalFilter{SagaType: req.SagaType, Limit: pageSize})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ListApprovalsResponse{Approvals: make([]dto.ApprovalDTO, len(approvals))}
+	for i, approval := range approvals {
+		resp.Approvals[i] = toApprovalDTO(approval)
+	}
+	return resp, nil
+}
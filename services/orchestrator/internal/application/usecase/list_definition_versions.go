@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// ListDefinitionVersionsUseCase reports which definition versions are
+// registered and which ones sagas still run against, so that an old version
+// is only removed once nothing references it
+type ListDefinitionVersionsUseCase struct {
+	repo     repository.SagaRepository
+	registry *definition.Registry
+}
+
+// NewListDefinitionVersionsUseCase creates a new use case
+func NewListDefinitionVersionsUseCase(repo repository.SagaRepository, registry *definition.Registry) *ListDefinitionVersionsUseCase {
+	return &ListDefinitionVersionsUseCase{repo: repo, registry: registry}
+}
+
+// Execute runs the use case
+func (uc *ListDefinitionVersionsUseCase) Execute(ctx context.Context) (*dto.ListDefinitionVersionsResponse, error) {
+	usage, err := uc.repo.VersionsInUse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		sagaType string
+		version  int
+	}
+	versions := make(map[key]*dto.DefinitionVersionDTO)
+	for _, sagaType := range uc.registry.Types() {
+		registered := uc.registry.Versions(sagaType)
+		for i, version := range registered {
+			versions[key{sagaType, version}] = &dto.DefinitionVersionDTO{
+				SagaType:   sagaType,
+				Version:    version,
+				Registered: true,
+				Latest:     i == len(registered)-1,
+			}
+		}
+	}
+	for _, u := range usage {
+		v, ok := versions[key{u.SagaType, u.Version}]
+		if !ok {
+			v = &dto.DefinitionVersionDTO{SagaType: u.SagaType, Version: u.Version}
+			versions[key{u.SagaType, u.Version}] = v
+		}
+		v.Sagas = u.Sagas
+	}
+
+	resp := &dto.ListDefinitionVersionsResponse{Versions: make([]dto.DefinitionVersionDTO, 0, len(versions))}
+	for _, v := range versions {
+		resp.Versions = append(resp.Versions, *v)
+	}
+	sort.Slice(resp.Versions, func(i, j int) bool {
+		a, b := resp.Versions[i], resp.Versions[j]
+		if a.SagaType != b.SagaType {
+			return a.SagaType < b.SagaType
+		}
+		return a.Version < b.Version
+	})
+	return resp, nil
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// ListSagasUseCase pages through sagas, newest first
+type ListSagasUseCase struct {
+	repo repository.SagaRepository
+}
+
+// NewListSagasUseCase creates a new use case
+func NewListSagasUseCase(repo repository.SagaRepository) *ListSagasUseCase {
+	return &ListSagasUseCase{repo: repo}
+}
+
+// Execute runs the use case
+func (uc *ListSagasUseCase) Execute(ctx context.Context, req dto.ListSagasRequest) (*dto.ListSagasResponse, error) {
+	pageSize := req.PageSize
+	if pageSize < 0 {
+		return nil, pErrors.E(pErrors.Invalid, "page size must not be negative", nil)
+	}
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	filter := repository.SagaFilter{
+		Type:   req.SagaType,
+		Status: req.Status,
+		// One extra row tells whether there is a next page
+		Limit: pageSize + 1,
+	}
+	if req.PageToken != "" {
+		createdAt, id, err := decodePageToken(req.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		filter.AfterCreatedAt = &createdAt
+		filter.AfterID = id
+	}
+
+	sagas, err := uc.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ListSagasResponse{}
+	if len(sagas) > pageSize {
+		sagas = sagas[:pageSize]
+		last := sagas[pageSize-1]
+		resp.NextPageToken = encodePageToken(last.CreatedAt, last.ID)
+	}
+
+	resp.Sagas = make([]dto.SagaResponse, len(sagas))
+	for i, saga := range sagas {
+		resp.Sagas[i] = *toSagaDTO(saga)
+	}
+
+	return resp, nil
+}
+
+// The page token is the position of the last saga of the previous page
+func encodePageToken(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.Format(time.RFC3339Nano) + "|" + id))
+}
+
+func decodePageToken(token string) (time.Time, string, error) {
+	invalid := pErrors.E(pErrors.Invalid, "invalid page token", nil)
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return time.Time{}, "", invalid
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return time.Time{}, "", invalid
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, "", invalid
+	}
+	return t, id, nil
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/lock"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// MarkStepCompensatedUseCase records that an operator undid a step by hand,
+// e.g. refunded a payment from the payment provider's console. Once no failed
+// compensation is left, the saga goes back to COMPENSATING and the executor
+// compensates the steps before it.
+type MarkStepCompensatedUseCase struct {
+	intervener
+	logger *logger.Logger
+}
+
+// NewMarkStepCompensatedUseCase creates a new use case
+func NewMarkStepCompensatedUseCase(repo repository.SagaRepository, interventions repository.InterventionRepository, locks *lock.Manager, logger *logger.Logger) *MarkStepCompensatedUseCase {
+	return &MarkStepCompensatedUseCase{
+		intervener: intervener{repo: repo, interventions: interventions, locks: locks},
+		logger:     logger,
+	}
+}
+
+// Execute runs the use case
+func (uc *MarkStepCompensatedUseCase) Execute(ctx context.Context, req dto.InterventionRequest) (*dto.SagaResponse, error) {
+	if req.StepName == "" {
+		return nil, pErrors.E(pErrors.Invalid, "step name is required", nil)
+	}
+
+	result, err := uc.apply(ctx, req, entity.InterventionMarkCompensated, func(saga *entity.Saga) ([]*entity.SagaStep, error) {
+		if err := requireFailed(saga); err != nil {
+			return nil, err
+		}
+
+		step, ok := saga.Step(req.StepName)
+		if !ok {
+			return nil, pErrors.E(pErrors.NotFound, "saga has no step "+req.StepName, nil)
+		}
+		if step.Status != entity.StepStatusCompensationFailed {
+			return nil, pErrors.E(pErrors.Conflict, "step "+step.Name+" is "+string(step.Status)+", not COMPENSATION_FAILED", nil)
+		}
+
+		step.Compensated()
+		if len(saga.FailedCompensations()) == 0 {
+			saga.ResumeCompensation()
+		}
+		return []*entity.SagaStep{step}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.InfoWithTrace(ctx).
+		Str("saga_id", req.SagaID).
+		Str("step", req.StepName).
+		Str("operator_id", req.OperatorID).
+		Msg("Saga step marked compensated by operator")
+
+	return result, nil
+}
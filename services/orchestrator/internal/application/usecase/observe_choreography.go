@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// ObserveChoreographyUseCase records a domain event of a choreographed saga
+// and derives the status of its run from the events seen so far
+type ObserveChoreographyUseCase struct {
+	repo         repository.ChoreographyRepository
+	choreography *definition.Choreography
+	logger       *logger.Logger
+}
+
+// NewObserveChoreographyUseCase creates a new use case
+func NewObserveChoreographyUseCase(repo repository.ChoreographyRepository, choreography *definition.Choreography, log *logger.Logger) *ObserveChoreographyUseCase {
+	return &ObserveChoreographyUseCase{repo: repo, choreography: choreography, logger: log}
+}
+
+// Execute runs the use case. It fails with Conflict when another observer
+// updated the run concurrently; the event is then redelivered and the
+// status derived again.
+func (uc *ObserveChoreographyUseCase) Execute(ctx context.Context, req dto.ChoreographyEventRequest) error {
+	if req.CorrelationID == "" {
+		return pErrors.E(pErrors.Invalid, "event "+req.Type+" has no correlation id", nil)
+	}
+
+	event := &entity.ChoreographyEvent{
+		CorrelationID: req.CorrelationID,
+		Type:          req.Type,
+		MessageID:     req.MessageID,
+		Payload:       req.Payload,
+		PublishedAt:   req.PublishedAt,
+		ReceivedAt:    time.Now(),
+	}
+	if _, err := uc.repo.AddEvent(ctx, uc.choreography.Type, event); err != nil {
+		return err
+	}
+
+	// Derived even for a redelivered event, whose earlier delivery may have
+	// failed before the status was written
+	run, err := uc.repo.FindByCorrelationID(ctx, req.CorrelationID)
+	if err != nil {
+		return err
+	}
+
+	status, errorMessage := uc.progress(run)
+	if status == run.Status && errorMessage == run.ErrorMessage {
+		return nil
+	}
+
+	previous := run.Status
+	run.Advance(status, errorMessage)
+	if err := uc.repo.Update(ctx, run); err != nil {
+		return err
+	}
+
+	uc.logger.InfoWithTrace(ctx).
+		Str("correlation_id", run.CorrelationID).
+		Str("event", req.Type).
+		Str("previous_status", string(previous)).
+		Str("status", string(run.Status)).
+		Msg("Choreography progressed")
+	return nil
+}
+
+// progress derives the status of a run: completed once the complete event
+// was seen, compensated once a failure was seen and every forward event was
+// undone, executing otherwise
+func (uc *ObserveChoreographyUseCase) progress(run *entity.Choreography) (entity.SagaStatus, string) {
+	if run.Seen(uc.choreography.Complete) {
+		return entity.SagaStatusCompleted, ""
+	}
+
+	for _, event := range run.Events {
+		if !uc.choreography.IsFailure(event.Type) {
+			continue
+		}
+
+		errorMessage := event.Type + ": " + failureReason(event.Payload)
+		if uc.choreography.Compensated(run.Seen) {
+			return entity.SagaStatusCompensated, errorMessage
+		}
+		return entity.SagaStatusCompensating, errorMessage
+	}
+
+	return entity.SagaStatusExecuting, ""
+}
+
+// failureReason reads the reason field of a failure event, if any
+func failureReason(payload json.RawMessage) string {
+	var event struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(payload, &event)
+	if event.Reason == "" {
+		return "no reason given"
+	}
+	return event.Reason
+}
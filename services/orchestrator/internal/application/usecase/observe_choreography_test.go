@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+func TestObserveChoreographyProgress(t *testing.T) {
+	checkout := &definition.Choreography{
+		Type:     "order",
+		Start:    "OrderCreated",
+		Complete: "OrderConfirmed",
+		Failures: []string{"InventoryReservationFailed", "PaymentFailed"},
+		Compensations: map[string]string{
+			"OrderCreated":      "OrderCancelled",
+			"InventoryReserved": "InventoryReleased",
+		},
+	}
+	uc := NewObserveChoreographyUseCase(nil, checkout, logger.New("test"))
+	declined := `{"reason":"card declined"}`
+
+	tests := []struct {
+		name       string
+		events     [][2]string
+		wantStatus entity.SagaStatus
+		wantError  string
+	}{
+		{
+			name:       "started",
+			events:     [][2]string{{"OrderCreated"}},
+			wantStatus: entity.SagaStatusExecuting,
+		},
+		{
+			name:       "reservation seen before the order",
+			events:     [][2]string{{"InventoryReserved"}, {"OrderCreated"}},
+			wantStatus: entity.SagaStatusExecuting,
+		},
+		{
+			name:       "confirmation seen before the payment",
+			events:     [][2]string{{"OrderCreated"}, {"InventoryReserved"}, {"OrderConfirmed"}, {"PaymentProcessed"}},
+			wantStatus: entity.SagaStatusCompleted,
+		},
+		{
+			name:       "payment failed",
+			events:     [][2]string{{"OrderCreated"}, {"InventoryReserved"}, {"PaymentFailed", declined}},
+			wantStatus: entity.SagaStatusCompensating,
+			wantError:  "PaymentFailed: card declined",
+		},
+		{
+			name:       "payment failed, stock released",
+			events:     [][2]string{{"OrderCreated"}, {"InventoryReserved"}, {"PaymentFailed", declined}, {"InventoryReleased"}},
+			wantStatus: entity.SagaStatusCompensating,
+			wantError:  "PaymentFailed: card declined",
+		},
+		{
+			name:       "payment failed and undone",
+			events:     [][2]string{{"OrderCreated"}, {"InventoryReserved"}, {"PaymentFailed", declined}, {"InventoryReleased"}, {"OrderCancelled"}},
+			wantStatus: entity.SagaStatusCompensated,
+			wantError:  "PaymentFailed: card declined",
+		},
+		{
+			name:       "undone before the failure is seen",
+			events:     [][2]string{{"OrderCancelled"}, {"InventoryReleased"}, {"OrderCreated"}, {"InventoryReserved"}, {"PaymentFailed", declined}},
+			wantStatus: entity.SagaStatusCompensated,
+			wantError:  "PaymentFailed: card declined",
+		},
+		{
+			name:       "reservation failed, nothing reserved to release",
+			events:     [][2]string{{"OrderCreated"}, {"InventoryReservationFailed", `{"reason":"out of stock"}`}, {"OrderCancelled"}},
+			wantStatus: entity.SagaStatusCompensated,
+			wantError:  "InventoryReservationFailed: out of stock",
+		},
+		{
+			name:       "failure without a reason",
+			events:     [][2]string{{"OrderCreated"}, {"PaymentFailed", `{}`}},
+			wantStatus: entity.SagaStatusCompensating,
+			wantError:  "PaymentFailed: no reason given",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &entity.Choreography{CorrelationID: "order-1", Type: "order", Status: entity.SagaStatusExecuting}
+			for _, e := range tt.events {
+				run.Events = append(run.Events, &entity.ChoreographyEvent{CorrelationID: "order-1", Type: e[0], Payload: json.RawMessage(e[1])})
+			}
+
+			status, errorMessage := uc.progress(run)
+			if status != tt.wantStatus || errorMessage != tt.wantError {
+				t.Errorf("progress() = %s %q, want %s %q", status, errorMessage, tt.wantStatus, tt.wantError)
+			}
+		})
+	}
+}
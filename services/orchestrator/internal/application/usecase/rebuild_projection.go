@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/lock"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// RebuildProjectionUseCase repairs the sagas and saga_steps rows of a saga by
+// replaying its events. It holds the saga lease, so no executor writes while
+// the rows are replaced.
+type RebuildProjectionUseCase struct {
+	repo   repository.SagaRepository
+	events repository.EventRepository
+	locks  *lock.Manager
+	logger *logger.Logger
+}
+
+// NewRebuildProjectionUseCase creates a new use case
+func NewRebuildProjectionUseCase(repo repository.SagaRepository, events repository.EventRepository, locks *lock.Manager, logger *logger.Logger) *RebuildProjectionUseCase {
+	return &RebuildProjectionUseCase{repo: repo, events: events, locks: locks, logger: logger}
+}
+
+// Execute runs the use case
+func (uc *RebuildProjectionUseCase) Execute(ctx context.Context, sagaID string) (*dto.ProjectionResponse, error) {
+	// Fails with NotFound before a lease is taken for an unknown saga
+	if _, err := uc.repo.FindByID(ctx, sagaID); err != nil {
+		return nil, err
+	}
+
+	lease, err := uc.locks.Acquire(ctx, sagaID)
+	if err != nil {
+		return nil, err
+	}
+	if lease == nil {
+		return nil, pErrors.E(pErrors.Conflict, "saga is being run, try again later", nil)
+	}
+	defer uc.locks.Release(lease)
+
+	stored, err := uc.repo.FindByID(ctx, sagaID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := uc.events.FindBySaga(ctx, sagaID)
+	if err != nil {
+		return nil, err
+	}
+	// The rows are restored up to the last event, a saga written before the
+	// event log existed has none
+	if len(events) == 0 {
+		return nil, pErrors.E(pErrors.NotFound, "saga has no events", nil)
+	}
+
+	rebuilt, err := entity.Replay(events)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := entity.ProjectionDiff(stored, rebuilt)
+	if len(diff) == 0 {
+		return &dto.ProjectionResponse{Saga: toSagaDTO(stored)}, nil
+	}
+
+	if err := uc.repo.Restore(ctx, lease, rebuilt, events[len(events)-1].Sequence); err != nil {
+		return nil, err
+	}
+
+	uc.logger.WarnWithTrace(ctx).
+		Str("saga_id", sagaID).
+		Str("differences", strings.Join(diff, "; ")).
+		Msg("Saga projection rebuilt from events")
+
+	return &dto.ProjectionResponse{
+		Saga:        toSagaDTO(rebuilt),
+		Differences: diff,
+		Rebuilt:     true,
+	}, nil
+}
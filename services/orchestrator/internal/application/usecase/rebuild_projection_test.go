@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/lock"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+// events is the event log of one saga
+type events []*entity.SagaEvent
+
+func (e events) FindBySaga(ctx context.Context, sagaID string) ([]*entity.SagaEvent, error) {
+	return e, nil
+}
+
+func (e events) FindSagaIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	return nil, nil
+}
+
+func TestRebuildProjection(t *testing.T) {
+	log := logger.New("test")
+
+	// The log of a saga that was created and started
+	saga := newSaga(t, entity.SagaStatusPending)
+	created := entity.NewSagaEvent(saga)
+	created.Sequence = 1
+	saga.Start()
+	started := entity.NewSagaEvent(saga)
+	started.Sequence = 2
+
+	tests := []struct {
+		name         string
+		stored       entity.SagaStatus
+		events       events
+		wantCode     pErrors.Code
+		wantRestored []int64
+	}{
+		{name: "projection up to date", stored: entity.SagaStatusExecuting, events: events{created, started}},
+		{name: "projection behind", stored: entity.SagaStatusPending, events: events{created, started}, wantRestored: []int64{2}},
+		{name: "no events", stored: entity.SagaStatusExecuting, wantCode: pErrors.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := clone(saga)
+			stored.Status = tt.stored
+			s := newStore(stored)
+			uc := NewRebuildProjectionUseCase(s, tt.events, lock.NewManager(s, "operator", time.Second, log), log)
+
+			resp, err := uc.Execute(context.Background(), saga.ID)
+			wantCode(t, err, tt.wantCode)
+			if len(s.restored) != len(tt.wantRestored) || len(s.restored) > 0 && s.restored[0] != tt.wantRestored[0] {
+				t.Fatalf("restored up to %v, want %v", s.restored, tt.wantRestored)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+
+			if resp.Rebuilt != (len(tt.wantRestored) > 0) {
+				t.Errorf("Rebuilt = %v, differences %v", resp.Rebuilt, resp.Differences)
+			}
+			if got := s.saga(saga.ID).Status; got != entity.SagaStatusExecuting {
+				t.Errorf("saga status = %s, want EXECUTING", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/diagram"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// RenderDiagramUseCase draws a saga definition, or a saga over the version
+// of the definition it runs against
+type RenderDiagramUseCase struct {
+	repo     repository.SagaRepository
+	registry *definition.Registry
+}
+
+// NewRenderDiagramUseCase creates a new use case
+func NewRenderDiagramUseCase(repo repository.SagaRepository, registry *definition.Registry) *RenderDiagramUseCase {
+	return &RenderDiagramUseCase{repo: repo, registry: registry}
+}
+
+// Execute runs the use case
+func (uc *RenderDiagramUseCase) Execute(ctx context.Context, req dto.RenderDiagramRequest) (*dto.DiagramResponse, error) {
+	if (req.SagaID == "") == (req.SagaType == "") {
+		return nil, pErrors.E(pErrors.Invalid, "either a saga id or a saga type is required", nil)
+	}
+	if req.SagaID != "" && req.Version != 0 {
+		return nil, pErrors.E(pErrors.Invalid, "a saga is drawn with the version it runs against", nil)
+	}
+
+	format, err := diagram.ParseFormat(req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	var saga *entity.Saga
+	var def *definition.Definition
+	switch {
+	case req.SagaID != "":
+		if saga, err = uc.repo.FindByID(ctx, req.SagaID); err != nil {
+			return nil, err
+		}
+		def, err = uc.registry.GetVersion(saga.Type, saga.DefinitionVersion)
+	case req.Version != 0:
+		def, err = uc.registry.GetVersion(req.SagaType, req.Version)
+	default:
+		def, err = uc.registry.Get(req.SagaType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := diagram.Render(def, saga, format)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.DiagramResponse{Format: string(format), Diagram: rendered}, nil
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/lock"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// RetryCompensationUseCase sends the failed compensation of a FAILED saga
+// again, once an operator fixed its cause. The saga goes back to
+// COMPENSATING and the recovery sweeper resumes it.
+type RetryCompensationUseCase struct {
+	intervener
+	logger *logger.Logger
+}
+
+// NewRetryCompensationUseCase creates a new use case
+func NewRetryCompensationUseCase(repo repository.SagaRepository, interventions repository.InterventionRepository, locks *lock.Manager, logger *logger.Logger) *RetryCompensationUseCase {
+	return &RetryCompensationUseCase{
+		intervener: intervener{repo: repo, interventions: interventions, locks: locks},
+		logger:     logger,
+	}
+}
+
+// Execute runs the use case
+func (uc *RetryCompensationUseCase) Execute(ctx context.Context, req dto.InterventionRequest) (*dto.SagaResponse, error) {
+	result, err := uc.apply(ctx, req, entity.InterventionRetryCompensation, func(saga *entity.Saga) ([]*entity.SagaStep, error) {
+		if err := requireFailed(saga); err != nil {
+			return nil, err
+		}
+
+		steps := saga.FailedCompensations()
+		if len(steps) == 0 {
+			return nil, pErrors.E(pErrors.Conflict, "saga has no failed compensation", nil)
+		}
+
+		for _, step := range steps {
+			step.RetryCompensation()
+		}
+		saga.ResumeCompensation()
+		return steps, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.InfoWithTrace(ctx).
+		Str("saga_id", req.SagaID).
+		Str("operator_id", req.OperatorID).
+		Msg("Saga compensation retried by operator")
+
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// SignalSagaUseCase records a signal pushed into a running saga. The steps
+// waiting for it are woken up and the first one to resume consumes it.
+type SignalSagaUseCase struct {
+	repo    repository.SagaRepository
+	signals repository.SignalRepository
+	logger  *logger.Logger
+}
+
+// NewSignalSagaUseCase creates a new use case
+func NewSignalSagaUseCase(repo repository.SagaRepository, signals repository.SignalRepository, log *logger.Logger) *SignalSagaUseCase {
+	return &SignalSagaUseCase{repo: repo, signals: signals, logger: log}
+}
+
+// Execute runs the use case. A signal may arrive before any step waits for
+// it; it is kept until one does. A finished saga takes no more signals.
+func (uc *SignalSagaUseCase) Execute(ctx context.Context, req dto.SignalSagaRequest) (*dto.SignalSagaResponse, error) {
+	signal, err := entity.NewSignal(req.SagaID, req.SignalName, req.IdempotencyKey, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check idempotency first, a delivery retried after the saga finished
+	// still gets its answer
+	existing, err := uc.signals.FindByKey(ctx, req.SagaID, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return uc.duplicate(ctx, existing, signal)
+	}
+
+	saga, err := uc.repo.FindByID(ctx, req.SagaID)
+	if err != nil {
+		return nil, err
+	}
+	if saga.IsTerminal() {
+		return nil, pErrors.E(pErrors.Conflict, "saga already finished", nil)
+	}
+
+	recorded, err := uc.signals.Record(ctx, signal)
+	if err != nil {
+		return nil, err
+	}
+	if !recorded {
+		// A concurrent delivery with the same key won the race
+		existing, err := uc.signals.FindByKey(ctx, req.SagaID, req.IdempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, pErrors.E(pErrors.Internal, "signal not found after duplicate delivery", nil)
+		}
+		return uc.duplicate(ctx, existing, signal)
+	}
+
+	uc.logger.InfoWithTrace(ctx).
+		Str("saga_id", req.SagaID).
+		Str("signal", req.SignalName).
+		Msg("Saga signal received")
+
+	return &dto.SignalSagaResponse{
+		SagaID:     signal.SagaID,
+		SignalName: signal.Name,
+		ReceivedAt: signal.ReceivedAt,
+	}, nil
+}
+
+// duplicate answers a delivery whose idempotency key was already used. The
+// key must not be reused for a different signal.
+func (uc *SignalSagaUseCase) duplicate(ctx context.Context, existing, signal *entity.Signal) (*dto.SignalSagaResponse, error) {
+	if !existing.Matches(signal) {
+		return nil, pErrors.E(pErrors.Conflict, "idempotency key already used for another signal", nil)
+	}
+
+	uc.logger.InfoWithTrace(ctx).
+		Str("saga_id", existing.SagaID).
+		Str("signal", existing.Name).
+		Msg("Returning existing signal (idempotent)")
+
+	return &dto.SignalSagaResponse{
+		SagaID:     existing.SagaID,
+		SignalName: existing.Name,
+		ReceivedAt: existing.ReceivedAt,
+		Duplicate:  true,
+	}, nil
+}
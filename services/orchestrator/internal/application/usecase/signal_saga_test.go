@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+// signals keeps the signals of sagas in memory. racer, when set, is a
+// concurrent delivery stored under the same key right before Record.
+type signals struct {
+	mu     sync.Mutex
+	stored []*entity.Signal
+	racer  *entity.Signal
+}
+
+func (s *signals) Record(ctx context.Context, signal *entity.Signal) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.racer != nil {
+		s.stored = append(s.stored, s.racer)
+		s.racer = nil
+	}
+	for _, stored := range s.stored {
+		if stored.SagaID == signal.SagaID && stored.IdempotencyKey == signal.IdempotencyKey {
+			return false, nil
+		}
+	}
+	s.stored = append(s.stored, signal)
+	return true, nil
+}
+
+func (s *signals) FindByKey(ctx context.Context, sagaID, idempotencyKey string) (*entity.Signal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.stored {
+		if stored.SagaID == sagaID && stored.IdempotencyKey == idempotencyKey {
+			return stored, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *signals) Claim(ctx context.Context, sagaID, name, stepName string) (*entity.Signal, error) {
+	return nil, nil
+}
+
+func (s *signals) Release(ctx context.Context, sagaID, stepName string) error { return nil }
+
+func TestSignalSaga(t *testing.T) {
+	log := logger.New("test")
+	cleared := json.RawMessage(`{"cleared": true}`)
+
+	signal := func(t *testing.T, payload string) *entity.Signal {
+		t.Helper()
+		signal, err := entity.NewSignal("saga-1", "fraud_review", "key-1", json.RawMessage(payload))
+		if err != nil {
+			t.Fatalf("NewSignal() error = %v", err)
+		}
+		return signal
+	}
+
+	tests := []struct {
+		name          string
+		status        entity.SagaStatus
+		stored        string
+		racer         string
+		wantCode      pErrors.Code
+		wantDuplicate bool
+		wantStored    int
+	}{
+		{name: "recorded", status: entity.SagaStatusExecuting, wantStored: 1},
+		{name: "before any step waits", status: entity.SagaStatusPending, wantStored: 1},
+		{name: "saga finished", status: entity.SagaStatusCompleted, wantCode: pErrors.Conflict},
+		{name: "redelivered", status: entity.SagaStatusExecuting, stored: `{"cleared":true}`, wantDuplicate: true, wantStored: 1},
+		{name: "redelivered after the saga finished", status: entity.SagaStatusCompleted, stored: `{"cleared":true}`, wantDuplicate: true, wantStored: 1},
+		{name: "key reused for another payload", status: entity.SagaStatusExecuting, stored: `{"cleared":false}`, wantCode: pErrors.Conflict, wantStored: 1},
+		{name: "concurrent delivery won", status: entity.SagaStatusExecuting, racer: `{"cleared":true}`, wantDuplicate: true, wantStored: 1},
+		{name: "concurrent delivery of another payload won", status: entity.SagaStatusExecuting, racer: `{"cleared":false}`, wantCode: pErrors.Conflict, wantStored: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(newSaga(t, tt.status))
+			repo := &signals{}
+			if tt.stored != "" {
+				repo.stored = append(repo.stored, signal(t, tt.stored))
+			}
+			if tt.racer != "" {
+				repo.racer = signal(t, tt.racer)
+			}
+			uc := NewSignalSagaUseCase(s, repo, log)
+
+			resp, err := uc.Execute(context.Background(), dto.SignalSagaRequest{
+				SagaID:         "saga-1",
+				SignalName:     "fraud_review",
+				IdempotencyKey: "key-1",
+				Payload:        cleared,
+			})
+			wantCode(t, err, tt.wantCode)
+			if len(repo.stored) != tt.wantStored {
+				t.Errorf("stored %d signals, want %d", len(repo.stored), tt.wantStored)
+			}
+			if err != nil {
+				return
+			}
+			if resp.Duplicate != tt.wantDuplicate || resp.SignalName != "fraud_review" {
+				t.Errorf("response = %+v, want duplicate %v", resp, tt.wantDuplicate)
+			}
+		})
+	}
+}
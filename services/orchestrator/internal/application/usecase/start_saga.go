@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// StartSagaUseCase stores a new PENDING saga, which the pending poller
+// then picks up and runs
+type StartSagaUseCase struct {
+	repo     repository.SagaRepository
+	registry *definition.Registry
+	logger   *logger.Logger
+}
+
+// NewStartSagaUseCase creates a new use case
+func NewStartSagaUseCase(repo repository.SagaRepository, registry *definition.Registry, logger *logger.Logger) *StartSagaUseCase {
+	return &StartSagaUseCase{
+		repo:     repo,
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// Execute runs the use case
+func (uc *StartSagaUseCase) Execute(ctx context.Context, req dto.StartSagaRequest) (*dto.SagaResponse, error) {
+	if req.IdempotencyKey == "" {
+		return nil, pErrors.E(pErrors.Invalid, "idempotency key is required", nil)
+	}
+
+	// Check idempotency first
+	existing, err := uc.repo.CheckIdempotency(ctx, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return uc.existing(ctx, existing), nil
+	}
+
+	// New sagas start on the latest version of their definition
+	def, err := uc.registry.Get(req.SagaType)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Invalid, "unknown saga type: "+req.SagaType, err)
+	}
+
+	saga, err := entity.NewSaga(req.SagaType, def.Version, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := uc.repo.Create(ctx, saga, req.IdempotencyKey)
+	if err != nil {
+		// A concurrent request with the same key won the race
+		var e *pErrors.Error
+		if errors.As(err, &e) && e.Code == pErrors.Conflict {
+			existing, checkErr := uc.repo.CheckIdempotency(ctx, req.IdempotencyKey)
+			if checkErr == nil && existing != nil {
+				return uc.existing(ctx, existing), nil
+			}
+		}
+		return nil, err
+	}
+
+	uc.logger.InfoWithTrace(ctx).
+		Str("saga_id", created.ID).
+		Str("saga_type", created.Type).
+		Msg("Saga started")
+
+	return toSagaDTO(created), nil
+}
+
+func (uc *StartSagaUseCase) existing(ctx context.Context, saga *entity.Saga) *dto.SagaResponse {
+	uc.logger.InfoWithTrace(ctx).
+		Str("saga_id", saga.ID).
+		Msg("Returning existing saga (idempotent)")
+
+	return toSagaDTO(saga)
+}
@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+// store keeps sagas, leases, timers and interventions in memory. Sagas are
+// copied in and out, so only what a use case wrote is seen by the test.
+type store struct {
+	mu            sync.Mutex
+	sagas         map[string]*entity.Saga
+	tokens        map[string]int64
+	held          map[string]bool
+	deleted       []string
+	restored      []int64
+	interventions []*entity.Intervention
+}
+
+func newStore(sagas ...*entity.Saga) *store {
+	s := &store{
+		sagas:  make(map[string]*entity.Saga),
+		tokens: make(map[string]int64),
+		held:   make(map[string]bool),
+	}
+	for _, saga := range sagas {
+		s.sagas[saga.ID] = clone(saga)
+	}
+	return s
+}
+
+func clone(saga *entity.Saga) *entity.Saga {
+	c := *saga
+	c.Steps = make([]*entity.SagaStep, len(saga.Steps))
+	for i, step := range saga.Steps {
+		s := *step
+		c.Steps[i] = &s
+	}
+	return &c
+}
+
+func (s *store) saga(id string) *entity.Saga {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return clone(s.sagas[id])
+}
+
+func (s *store) fence(lease *entity.Lease) error {
+	if lease.Token != s.tokens[lease.SagaID] {
+		return pErrors.E(pErrors.Conflict, "saga lease lost", nil)
+	}
+	return nil
+}
+
+// LockRepository
+
+func (s *store) Acquire(ctx context.Context, sagaID, ownerID string, ttl time.Duration) (*entity.Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.held[sagaID] {
+		return nil, nil
+	}
+	s.tokens[sagaID]++
+	return &entity.Lease{SagaID: sagaID, OwnerID: ownerID, Token: s.tokens[sagaID], ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+func (s *store) Renew(ctx context.Context, lease *entity.Lease, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fence(lease)
+}
+
+func (s *store) Release(ctx context.Context, lease *entity.Lease) error { return nil }
+
+// SagaRepository
+
+func (s *store) Create(ctx context.Context, saga *entity.Saga, idempotencyKey string) (*entity.Saga, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *store) CheckIdempotency(ctx context.Context, key string) (*entity.Saga, error) {
+	return nil, nil
+}
+
+func (s *store) List(ctx context.Context, filter repository.SagaFilter) ([]*entity.Saga, error) {
+	return nil, nil
+}
+
+func (s *store) RequestCancel(ctx context.Context, sagaID string, reason string) error {
+	return errors.New("not implemented")
+}
+
+func (s *store) FindCancelRequest(ctx context.Context, sagaID string) (string, bool, error) {
+	return "", false, nil
+}
+
+func (s *store) FindPending(ctx context.Context, limit int) ([]string, error) { return nil, nil }
+
+func (s *store) FindStuck(ctx context.Context, limit int) ([]string, error) { return nil, nil }
+
+func (s *store) FindByID(ctx context.Context, id string) (*entity.Saga, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saga, ok := s.sagas[id]
+	if !ok {
+		return nil, pErrors.E(pErrors.NotFound, "saga not found", nil)
+	}
+	return clone(saga), nil
+}
+
+func (s *store) FindChild(ctx context.Context, parentSagaID, stepName string) (*entity.Saga, error) {
+	return nil, nil
+}
+
+func (s *store) VersionsInUse(ctx context.Context) ([]repository.VersionUsage, error) {
+	return nil, nil
+}
+
+func (s *store) CreateSteps(ctx context.Context, lease *entity.Lease, steps []*entity.SagaStep) error {
+	return errors.New("not implemented")
+}
+
+func (s *store) UpdateSaga(ctx context.Context, lease *entity.Lease, saga *entity.Saga) error {
+	return errors.New("not implemented")
+}
+
+func (s *store) UpdateStep(ctx context.Context, lease *entity.Lease, step *entity.SagaStep) error {
+	return errors.New("not implemented")
+}
+
+func (s *store) Upgrade(ctx context.Context, lease *entity.Lease, saga *entity.Saga, fromVersion int) error {
+	return errors.New("not implemented")
+}
+
+func (s *store) Restore(ctx context.Context, lease *entity.Lease, saga *entity.Saga, sequence int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.fence(lease); err != nil {
+		return err
+	}
+	s.sagas[saga.ID] = clone(saga)
+	s.restored = append(s.restored, sequence)
+	return nil
+}
+
+// TimerRepository
+
+func (s *store) Schedule(ctx context.Context, timer *entity.Timer) error {
+	return errors.New("not implemented")
+}
+
+func (s *store) FireDue(ctx context.Context, limit int) ([]*entity.Timer, error) { return nil, nil }
+
+func (s *store) DeleteBySaga(ctx context.Context, sagaID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = append(s.deleted, sagaID)
+	return nil
+}
+
+// InterventionRepository
+
+func (s *store) Record(ctx context.Context, lease *entity.Lease, saga *entity.Saga, steps []*entity.SagaStep, intervention *entity.Intervention) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.fence(lease); err != nil {
+		return err
+	}
+
+	stored := clone(s.sagas[saga.ID])
+	for _, step := range steps {
+		for i, old := range stored.Steps {
+			if old.ID == step.ID {
+				c := *step
+				stored.Steps[i] = &c
+			}
+		}
+	}
+	c := *saga
+	c.Steps = stored.Steps
+	s.sagas[saga.ID] = &c
+	s.interventions = append(s.interventions, intervention)
+	return nil
+}
+
+func (s *store) FindBySaga(ctx context.Context, sagaID string) ([]*entity.Intervention, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var interventions []*entity.Intervention
+	for _, intervention := range s.interventions {
+		if intervention.SagaID == sagaID {
+			interventions = append(interventions, intervention)
+		}
+	}
+	return interventions, nil
+}
+
+// newSaga returns a saga in the given status with one step per status
+func newSaga(t *testing.T, status entity.SagaStatus, steps ...entity.StepStatus) *entity.Saga {
+	t.Helper()
+	saga, err := entity.NewSaga("order", 1, json.RawMessage(`{"order_id":"order-1"}`))
+	if err != nil {
+		t.Fatalf("NewSaga() error = %v", err)
+	}
+	saga.ID = "saga-1"
+	saga.Status = status
+	for i, stepStatus := range steps {
+		step := entity.NewSagaStep(saga.ID, stepNames[i], i+1, i+1)
+		step.ID = saga.ID + "-" + step.Name
+		step.Status = stepStatus
+		saga.Steps = append(saga.Steps, step)
+	}
+	return saga
+}
+
+var stepNames = []string{"reserve", "charge", "ship"}
+
+// wantCode fails the test unless err carries the given code, or is nil when
+// code is empty
+func wantCode(t *testing.T, err error, code pErrors.Code) {
+	t.Helper()
+	if code == "" {
+		if err != nil {
+			t.Fatalf("error = %v, want none", err)
+		}
+		return
+	}
+	var e *pErrors.Error
+	if !errors.As(err, &e) || e.Code != code {
+		t.Fatalf("error = %v, want code %s", err, code)
+	}
+}
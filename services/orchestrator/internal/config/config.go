@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// Config holds the orchestrator-only settings. The shared settings (app,
+// gRPC, database) are loaded by platform/config through platform/app.
+type Config struct {
+	Services    ServicesConfig
+	Worker      WorkerConfig
+	Lock        LockConfig
+	Approval    ApprovalConfig
+	Definitions DefinitionsConfig
+}
+
+// =======================
+// Downstream services
+// =======================
+
+type ServicesConfig struct {
+	OrderAddr     string `env:"ORDER_SERVICE_ADDR" env-required:"true"`
+	PaymentAddr   string `env:"PAYMENT_SERVICE_ADDR" env-required:"true"`
+	InventoryAddr string `env:"INVENTORY_SERVICE_ADDR" env-required:"true"`
+}
+
+// =======================
+// Saga worker
+// =======================
+
+type WorkerConfig struct {
+	PollInterval     time.Duration `env:"POLL_INTERVAL" env-default:"1s"`
+	BatchSize        int           `env:"POLL_BATCH_SIZE" env-default:"10"`
+	StepTimeout      time.Duration `env:"STEP_TIMEOUT" env-default:"10s"`
+	RecoveryInterval time.Duration `env:"RECOVERY_INTERVAL" env-default:"10s"`
+	TimerInterval    time.Duration `env:"TIMER_INTERVAL" env-default:"1s"`
+}
+
+// =======================
+// Distributed lock
+// =======================
+
+type LockConfig struct {
+	// InstanceID identifies this replica as the owner of saga leases.
+	// It defaults to the hostname plus a random suffix.
+	InstanceID string        `env:"INSTANCE_ID"`
+	LeaseTTL   time.Duration `env:"LOCK_LEASE_TTL" env-default:"30s"`
+}
+
+// =======================
+// Fraud review
+// =======================
+
+type ApprovalConfig struct {
+	// Orders above the threshold wait for a fraud analyst before payment
+	Threshold float64 `env:"APPROVAL_THRESHOLD" env-default:"1000"`
+	// Orders nobody reviewed in time are rejected
+	Expiry time.Duration `env:"APPROVAL_EXPIRY" env-default:"24h"`
+}
+
+// =======================
+// Definition files
+// =======================
+
+type DefinitionsConfig struct {
+	// Dir holds saga definitions declared in YAML or JSON files; empty means none
+	Dir string `env:"SAGA_DEFINITIONS_DIR"`
+	// ResolveTimeout bounds the lookup, at startup, of the methods they call
+	ResolveTimeout time.Duration `env:"SAGA_DEFINITIONS_RESOLVE_TIMEOUT" env-default:"10s"`
+}
+
+func Load() (*Config, error) {
+	var cfg Config
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("read env: %w", err)
+	}
+
+	if cfg.Worker.PollInterval <= 0 {
+		return nil, fmt.Errorf("POLL_INTERVAL must be > 0")
+	}
+
+	if cfg.Worker.BatchSize <= 0 {
+		return nil, fmt.Errorf("POLL_BATCH_SIZE must be > 0")
+	}
+
+	if cfg.Worker.StepTimeout <= 0 {
+		return nil, fmt.Errorf("STEP_TIMEOUT must be > 0")
+	}
+
+	if cfg.Worker.RecoveryInterval <= 0 {
+		return nil, fmt.Errorf("RECOVERY_INTERVAL must be > 0")
+	}
+
+	if cfg.Worker.TimerInterval <= 0 {
+		return nil, fmt.Errorf("TIMER_INTERVAL must be > 0")
+	}
+
+	if cfg.Lock.LeaseTTL <= 0 {
+		return nil, fmt.Errorf("LOCK_LEASE_TTL must be > 0")
+	}
+
+	if cfg.Approval.Threshold < 0 {
+		return nil, fmt.Errorf("APPROVAL_THRESHOLD must be >= 0")
+	}
+
+	if cfg.Approval.Expiry <= 0 {
+		return nil, fmt.Errorf("APPROVAL_EXPIRY must be > 0")
+	}
+
+	if cfg.Definitions.ResolveTimeout <= 0 {
+		return nil, fmt.Errorf("SAGA_DEFINITIONS_RESOLVE_TIMEOUT must be > 0")
+	}
+
+	if cfg.Lock.InstanceID == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("resolve hostname: %w", err)
+		}
+		cfg.Lock.InstanceID = host + "-" + uuid.New().String()[:8]
+	}
+
+	return &cfg, nil
+}
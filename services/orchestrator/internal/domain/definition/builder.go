@@ -0,0 +1,376 @@
+package definition
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+// Builder declares a saga definition step by step:
+//
+//	b := definition.New("order_saga")
+//	b.Step("create_order").
+//		Action(orders.CreateOrder, createOrderRequest).
+//		Compensation(orders.CancelOrder, cancelOrderRequest)
+//	g := b.Parallel()
+//	g.Step("process_payment").Action(...)
+//	g.Step("reserve_inventory").Action(...)
+//	def, err := b.Build()
+type Builder struct {
+	sagaType string
+	version  int
+	migrate  Migration
+	deadline time.Duration
+	groups   int
+	steps    []*StepBuilder
+}
+
+// StepBuilder configures one step of a Builder
+type StepBuilder struct {
+	step Step
+}
+
+// GroupBuilder declares steps that run concurrently. When one of them fails,
+// the siblings that succeeded are compensated with the rest of the saga.
+type GroupBuilder struct {
+	b     *Builder
+	group int
+}
+
+// New starts version 1 of a definition for the given saga type
+func New(sagaType string) *Builder {
+	return &Builder{sagaType: sagaType, version: 1}
+}
+
+// Version sets the version of the definition. A changed step list is
+// declared as a new version, registered next to the versions in-flight
+// sagas still run against.
+func (b *Builder) Version(version int) *Builder {
+	b.version = version
+	return b
+}
+
+// MigrateFrom makes the sagas in flight on the previous version upgrade to
+// this one, with their payload migrated by m
+func (b *Builder) MigrateFrom(m Migration) *Builder {
+	b.migrate = m
+	return b
+}
+
+// Step appends a step; steps run in the order they are declared
+func (b *Builder) Step(name string) *StepBuilder {
+	b.groups++
+	return b.add(name, b.groups)
+}
+
+// Parallel appends a group of steps that run concurrently
+func (b *Builder) Parallel() *GroupBuilder {
+	b.groups++
+	return &GroupBuilder{b: b, group: b.groups}
+}
+
+// Step adds a step to the group
+func (g *GroupBuilder) Step(name string) *StepBuilder {
+	return g.b.add(name, g.group)
+}
+
+func (b *Builder) add(name string, group int) *StepBuilder {
+	sb := &StepBuilder{step: Step{Name: name, Group: group}}
+	b.steps = append(b.steps, sb)
+	return sb
+}
+
+// Deadline sets how long the saga may run forward before it is compensated
+func (b *Builder) Deadline(d time.Duration) *Builder {
+	b.deadline = d
+	return b
+}
+
+// Action sets the forward action and how its request is built
+func (sb *StepBuilder) Action(action Action, request RequestBuilder) *StepBuilder {
+	sb.step.Action = action
+	sb.step.Request = request
+	sb.step.RequestTemplate = nil
+	return sb
+}
+
+// Compensation sets the action that undoes the step and how its request is built
+func (sb *StepBuilder) Compensation(action Action, request RequestBuilder) *StepBuilder {
+	sb.step.Compensation = action
+	sb.step.CompensationRequest = request
+	sb.step.CompensationTemplate = nil
+	return sb
+}
+
+// ActionTemplate sets the forward action and the template of its request
+func (sb *StepBuilder) ActionTemplate(action Action, request Template) *StepBuilder {
+	sb.step.Action = action
+	sb.step.Request = request.Build
+	sb.step.RequestTemplate = request
+	return sb
+}
+
+// CompensationTemplate sets the action that undoes the step and the
+// template of its request. It may reference the output of the step itself.
+func (sb *StepBuilder) CompensationTemplate(action Action, request Template) *StepBuilder {
+	sb.step.Compensation = action
+	sb.step.CompensationRequest = request.Build
+	sb.step.CompensationTemplate = request
+	return sb
+}
+
+// When makes the step conditional: it is skipped when the condition does not
+// hold once the steps of the earlier groups have run
+func (sb *StepBuilder) When(condition Condition) *StepBuilder {
+	sb.step.When = condition
+	return sb
+}
+
+// Response sets the mapper applied to the forward response before it is stored
+func (sb *StepBuilder) Response(mapper ResponseMapper) *StepBuilder {
+	sb.step.Response = mapper
+	return sb
+}
+
+// Retry sets the retry policy of the forward and compensating calls
+func (sb *StepBuilder) Retry(policy RetryPolicy) *StepBuilder {
+	sb.step.Retry = policy
+	return sb
+}
+
+// Deadline sets how long the step may take, retries included, before the
+// saga is compensated
+func (sb *StepBuilder) Deadline(d time.Duration) *StepBuilder {
+	sb.step.Deadline = d
+	return sb
+}
+
+// Async makes the step wait up to timeout for the completion of the command
+// its action issued, reported by a callback or an event. The completion
+// payload goes through the response mapper and becomes the step output.
+func (sb *StepBuilder) Async(timeout time.Duration) *StepBuilder {
+	sb.step.Wait = timeout
+	return sb
+}
+
+// AwaitSignal makes the step wait up to timeout for a signal of the given
+// name pushed into the saga, instead of calling an action. The signal
+// payload goes through the response mapper and becomes the step output.
+func (sb *StepBuilder) AwaitSignal(name string, timeout time.Duration) *StepBuilder {
+	sb.step.Signal = name
+	sb.step.Wait = timeout
+	return sb
+}
+
+// Approval makes the step wait for an approver to approve or reject the
+// saga, instead of calling an action. Without a decision after expiry, the
+// saga is rejected automatically. A rejection compensates the saga.
+func (sb *StepBuilder) Approval(expiry time.Duration) *StepBuilder {
+	sb.step.Approval = true
+	sb.step.Wait = expiry
+	return sb
+}
+
+// SubSaga makes the step start a saga of the given type as its child, with
+// the built request as payload, and wait up to timeout for it to finish. The
+// child outputs become the step output; a child that is compensated or
+// failed fails the step. When the parent is compensated, so is the child.
+func (sb *StepBuilder) SubSaga(sagaType string, payload RequestBuilder, timeout time.Duration) *StepBuilder {
+	sb.step.SubSaga = sagaType
+	sb.step.Request = payload
+	sb.step.RequestTemplate = nil
+	sb.step.Wait = timeout
+	return sb
+}
+
+// SubSagaTemplate is SubSaga with the payload built from a template
+func (sb *StepBuilder) SubSagaTemplate(sagaType string, payload Template, timeout time.Duration) *StepBuilder {
+	sb.step.SubSaga = sagaType
+	sb.step.Request = payload.Build
+	sb.step.RequestTemplate = payload
+	sb.step.Wait = timeout
+	return sb
+}
+
+// Pivot makes the step the point of no return of the saga. A failure up to
+// the group of the pivot compensates the saga; once that group succeeded,
+// failures of later steps are retried forward, for as long as it takes.
+func (sb *StepBuilder) Pivot() *StepBuilder {
+	sb.step.Pivot = true
+	return sb
+}
+
+// Build validates the declaration and returns the definition
+func (b *Builder) Build() (*Definition, error) {
+	if b.sagaType == "" {
+		return nil, pErrors.E(pErrors.Invalid, "saga type is required", nil)
+	}
+
+	if len(b.steps) == 0 {
+		return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s has no steps", b.sagaType), nil)
+	}
+
+	if b.version < 1 {
+		return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: version must be at least 1", b.sagaType), nil)
+	}
+
+	if b.migrate != nil && b.version == 1 {
+		return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: version 1 has no previous version to migrate from", b.sagaType), nil)
+	}
+
+	if b.deadline < 0 {
+		return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: negative deadline", b.sagaType), nil)
+	}
+
+	// Steps of a group stay together even when the group was filled after
+	// later steps were declared; empty groups leave no gap in the numbering
+	steps := make([]Step, len(b.steps))
+	for i, sb := range b.steps {
+		steps[i] = sb.step
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Group < steps[j].Group })
+	group, last := 0, 0
+	for i := range steps {
+		if steps[i].Group != last {
+			last = steps[i].Group
+			group++
+		}
+		steps[i].Group = group
+	}
+
+	pivot := 0
+	for _, step := range steps {
+		if !step.Pivot {
+			continue
+		}
+		if pivot != 0 {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s has more than one pivot step", b.sagaType), nil)
+		}
+		pivot = step.Group
+	}
+
+	def := &Definition{Type: b.sagaType, Version: b.version, Deadline: b.deadline, Migrate: b.migrate}
+	seen := make(map[string]bool, len(steps))
+	for i, step := range steps {
+
+		if step.Name == "" {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %d has no name", b.sagaType, i+1), nil)
+		}
+
+		if seen[step.Name] {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: duplicate step %s", b.sagaType, step.Name), nil)
+		}
+		seen[step.Name] = true
+
+		waits := 0
+		for _, w := range []bool{step.AwaitsSignal(), step.AwaitsApproval(), step.StartsSubSaga()} {
+			if w {
+				waits++
+			}
+		}
+		if waits > 1 {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s can only await one of a signal, an approval or a sub-saga", b.sagaType, step.Name), nil)
+		}
+
+		if step.SubSaga == b.sagaType {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s cannot start a saga of its own type", b.sagaType, step.Name), nil)
+		}
+		if step.StartsSubSaga() && step.Request == nil {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s needs a payload builder for its sub-saga", b.sagaType, step.Name), nil)
+		}
+
+		// A step that only waits has nothing to undo; the child of a
+		// sub-saga step is undone by its own compensations
+		if !step.HasAction() {
+			if step.Action != nil || (step.Request != nil && !step.StartsSubSaga()) || step.Compensation != nil {
+				return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s only waits and cannot have an action nor a compensation", b.sagaType, step.Name), nil)
+			}
+			if step.Wait <= 0 {
+				return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s needs a timeout for what it waits for", b.sagaType, step.Name), nil)
+			}
+		} else if step.Action == nil || step.Request == nil {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s needs an action and a request builder", b.sagaType, step.Name), nil)
+		}
+
+		if (step.Compensation == nil) != (step.CompensationRequest == nil) {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s needs both a compensation and its request builder", b.sagaType, step.Name), nil)
+		}
+
+		if step.Deadline < 0 {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s has a negative deadline", b.sagaType, step.Name), nil)
+		}
+
+		if step.Wait < 0 {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s has a negative wait timeout", b.sagaType, step.Name), nil)
+		}
+
+		// Steps past the pivot are never rolled back nor cut short
+		if pivot != 0 && step.Group > pivot {
+			if step.Compensation != nil {
+				return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s runs after the pivot and cannot have a compensation", b.sagaType, step.Name), nil)
+			}
+			if step.Deadline > 0 {
+				return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s runs after the pivot and cannot have a deadline", b.sagaType, step.Name), nil)
+			}
+			if step.AwaitsApproval() {
+				return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s runs after the pivot and cannot be rejected", b.sagaType, step.Name), nil)
+			}
+			if step.StartsSubSaga() {
+				return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s runs after the pivot and cannot start a sub-saga, a failed child is never retried", b.sagaType, step.Name), nil)
+			}
+		}
+
+		if err := validateTemplates(step, steps); err != nil {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s: %v", b.sagaType, step.Name, err), err)
+		}
+
+		if err := step.Retry.validate(); err != nil {
+			return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("saga %s: step %s: %v", b.sagaType, step.Name, err), err)
+		}
+
+		def.Steps = append(def.Steps, step)
+	}
+
+	return def, nil
+}
+
+// validateTemplates checks that the condition and templates of a step only
+// read the outputs of steps of earlier groups; a compensation may also read
+// its own step.
+func validateTemplates(step Step, steps []Step) error {
+	groups := make(map[string]int, len(steps))
+	for _, s := range steps {
+		groups[s.Name] = s.Group
+	}
+
+	before := func(name string) bool {
+		group, ok := groups[name]
+		return ok && group < step.Group
+	}
+
+	if step.When != nil {
+		if err := step.When.validate(before); err != nil {
+			return fmt.Errorf("condition: %w", err)
+		}
+	}
+
+	if step.RequestTemplate != nil {
+		if err := step.RequestTemplate.validate(before); err != nil {
+			return fmt.Errorf("request: %w", err)
+		}
+	}
+
+	if step.CompensationTemplate != nil {
+		err := step.CompensationTemplate.validate(func(name string) bool {
+			group, ok := groups[name]
+			return ok && (group < step.Group || name == step.Name)
+		})
+		if err != nil {
+			return fmt.Errorf("compensation request: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package definition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+func noop(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	return request, nil
+}
+
+func emptyRequest(state State) (json.RawMessage, error) {
+	return json.RawMessage(`{}`), nil
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func() *Builder
+		wantErr string
+	}{
+		{
+			name: "valid",
+			build: func() *Builder {
+				b := New("order_saga")
+				b.Step("create_order").Action(noop, emptyRequest).Compensation(noop, emptyRequest)
+				b.Step("confirm_order").Action(noop, emptyRequest)
+				return b
+			},
+		},
+		{
+			name:    "missing type",
+			build:   func() *Builder { return New("") },
+			wantErr: "saga type is required",
+		},
+		{
+			name:    "no steps",
+			build:   func() *Builder { return New("order_saga") },
+			wantErr: "saga order_saga has no steps",
+		},
+		{
+			name: "version below 1",
+			build: func() *Builder {
+				b := New("order_saga").Version(0)
+				b.Step("create_order").Action(noop, emptyRequest)
+				return b
+			},
+			wantErr: "version must be at least 1",
+		},
+		{
+			name: "migration of version 1",
+			build: func() *Builder {
+				b := New("order_saga").MigrateFrom(func(payload json.RawMessage) (json.RawMessage, error) { return payload, nil })
+				b.Step("create_order").Action(noop, emptyRequest)
+				return b
+			},
+			wantErr: "version 1 has no previous version",
+		},
+		{
+			name: "negative deadline",
+			build: func() *Builder {
+				b := New("order_saga").Deadline(-time.Second)
+				b.Step("create_order").Action(noop, emptyRequest)
+				return b
+			},
+			wantErr: "negative deadline",
+		},
+		{
+			name: "unnamed step",
+			build: func() *Builder {
+				b := New("order_saga")
+				b.Step("").Action(noop, emptyRequest)
+				return b
+			},
+			wantErr: "step 1 has no name",
+		},
+		{
+			name: "duplicate step",
+			build: func() *Builder {
+				b := New("order_saga")
+				b.Step("create_order").Action(noop, emptyRequest)
+				b.Step("create_order").Action(noop, emptyRequest)
+				return b
+			},
+			wantErr: "duplicate step create_order",
+		},
+		{
+			name: "missing action",
+			build: func() *Builder {
+				b := New("order_saga")
+				b.Step("create_order")
+				return b
+			},
+			wantErr: "needs an action and a request builder",
+		},
+		{
+			name: "compensation without request",
+			build: func() *Builder {
+				b := New("order_saga")
+				b.Step("create_order").Action(noop, emptyRequest).Compensation(noop, nil)
+				return b
+			},
+			wantErr: "needs both a compensation and its request builder",
+		},
+		{
+			name: "negative step deadline",
+			build: func() *Builder {
+				b := New("order_saga")
+				b.Step("create_order").Action(noop, emptyRequest).Deadline(-time.Second)
+				return b
+			},
+			wantErr: "has a negative deadline",
+		},
+		{
+			name: "invalid retry policy",
+			build: func() *Builder {
+				b := New("order_saga")
+				b.Step("create_order").Action(noop, emptyRequest).Retry(RetryPolicy{MaxAttempts: 3, Multiplier: 2})
+				return b
+			},
+			wantErr: "step create_order: initial backoff must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := tt.build().Build()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Build() error = %v", err)
+				}
+				if def == nil {
+					t.Fatal("Build() returned no definition")
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("Build() error = nil, want %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Build() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+			var pErr *pErrors.Error
+			if !errors.As(err, &pErr) || pErr.Code != pErrors.Invalid {
+				t.Errorf("Build() error = %#v, want an Invalid error", err)
+			}
+		})
+	}
+}
+
+func TestBuildGroups(t *testing.T) {
+	b := New("order_saga")
+	b.Step("create_order").Action(noop, emptyRequest)
+	g := b.Parallel()
+	b.Step("confirm_order").Action(noop, emptyRequest)
+	// Filled after a later step was declared
+	g.Step("process_payment").Action(noop, emptyRequest)
+	g.Step("reserve_inventory").Action(noop, emptyRequest)
+	// Empty groups leave no gap
+	b.Parallel()
+	b.Step("notify").Action(noop, emptyRequest)
+
+	def, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := []struct {
+		name  string
+		group int
+	}{
+		{"create_order", 1},
+		{"process_payment", 2},
+		{"reserve_inventory", 2},
+		{"confirm_order", 3},
+		{"notify", 4},
+	}
+	if len(def.Steps) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(def.Steps), len(want))
+	}
+	for i, w := range want {
+		if got := def.Steps[i]; got.Name != w.name || got.Group != w.group {
+			t.Errorf("step %d = %s in group %d, want %s in group %d", i, got.Name, got.Group, w.name, w.group)
+		}
+	}
+}
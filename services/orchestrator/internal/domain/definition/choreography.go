@@ -0,0 +1,77 @@
+package definition
+
+import (
+	"sort"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+// Choreography is a saga type run by the services themselves, each reacting
+// to the domain events of the others. The orchestrator does not call anyone:
+// it observes the events and derives the progress of each run from the
+// event types it has seen.
+type Choreography struct {
+	Type string
+	// Start is the event that begins a run
+	Start string
+	// Complete is the event that ends a successful run
+	Complete string
+	// Failures are the events after which the run is compensated
+	Failures []string
+	// Compensations maps each forward event to the event that undoes it.
+	// A failed run is compensated once every forward event it saw was undone.
+	Compensations map[string]string
+}
+
+// Validate checks that the choreography is complete
+func (c *Choreography) Validate() error {
+	if c.Type == "" {
+		return pErrors.E(pErrors.Invalid, "choreography type is required", nil)
+	}
+	if c.Start == "" || c.Complete == "" {
+		return pErrors.E(pErrors.Invalid, "choreography "+c.Type+": start and complete events are required", nil)
+	}
+	if len(c.Failures) == 0 {
+		return pErrors.E(pErrors.Invalid, "choreography "+c.Type+": at least one failure event is required", nil)
+	}
+	return nil
+}
+
+// Events returns every event type the observer subscribes to, sorted
+func (c *Choreography) Events() []string {
+	set := map[string]bool{c.Start: true, c.Complete: true}
+	for _, event := range c.Failures {
+		set[event] = true
+	}
+	for forward, undo := range c.Compensations {
+		set[forward] = true
+		set[undo] = true
+	}
+
+	events := make([]string, 0, len(set))
+	for event := range set {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	return events
+}
+
+// IsFailure reports whether an event type fails the run
+func (c *Choreography) IsFailure(eventType string) bool {
+	for _, event := range c.Failures {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Compensated reports whether every forward event in seen was undone
+func (c *Choreography) Compensated(seen func(eventType string) bool) bool {
+	for forward, undo := range c.Compensations {
+		if seen(forward) && !seen(undo) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package definition
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Condition is a predicate over the saga payload and the outputs of the
+// steps that ran before. A step with a condition that does not hold is
+// SKIPPED: it is not called, has no output and is not compensated.
+// References are checked when the definition is built, like those of a Template.
+type Condition interface {
+	// Evaluate reports whether the condition holds for the saga state
+	Evaluate(state State) (bool, error)
+	validate(visible func(step string) bool) error
+}
+
+// Exists holds when there is a non-null value at ref. The output of a
+// skipped step does not exist.
+func Exists(ref Ref) Condition {
+	return &compare{ref: ref, op: "exists"}
+}
+
+// Equals holds when the value at ref equals value. Numbers are compared by
+// value, anything else by its JSON encoding. A missing value equals nothing.
+func Equals(ref Ref, value any) Condition {
+	return &compare{ref: ref, op: "==", value: value}
+}
+
+// NotEquals holds when Equals does not, including when the value is missing
+func NotEquals(ref Ref, value any) Condition {
+	return Not(Equals(ref, value))
+}
+
+// GreaterThan holds when the value at ref is a number above n
+func GreaterThan(ref Ref, n float64) Condition {
+	return &compare{ref: ref, op: ">", value: n}
+}
+
+// LessThan holds when the value at ref is a number below n
+func LessThan(ref Ref, n float64) Condition {
+	return &compare{ref: ref, op: "<", value: n}
+}
+
+// Not negates a condition
+func Not(c Condition) Condition {
+	return &not{c: c}
+}
+
+// All holds when every condition holds
+func All(conditions ...Condition) Condition {
+	return &combine{all: true, conditions: conditions}
+}
+
+// Any holds when at least one condition holds
+func Any(conditions ...Condition) Condition {
+	return &combine{all: false, conditions: conditions}
+}
+
+type compare struct {
+	ref   Ref
+	op    string
+	value any
+}
+
+func (c *compare) Evaluate(state State) (bool, error) {
+	return c.evaluate(&scope{state: state, outputs: make(map[string]any)})
+}
+
+func (c *compare) evaluate(s *scope) (bool, error) {
+	value, found, err := s.find(c.ref)
+	if err != nil || !found {
+		return false, err
+	}
+
+	switch c.op {
+	case "exists":
+		return true, nil
+	case "==":
+		return equal(value, c.value), nil
+	case ">", "<":
+		n, ok := number(value)
+		if !ok {
+			return false, nil
+		}
+		if c.op == ">" {
+			return n > c.value.(float64), nil
+		}
+		return n < c.value.(float64), nil
+	default:
+		return false, fmt.Errorf("unknown comparison %s", c.op)
+	}
+}
+
+func (c *compare) validate(visible func(step string) bool) error {
+	if err := c.ref.validate(visible, false); err != nil {
+		return err
+	}
+	if c.op == "==" {
+		return validateValue(c.value, visible, false)
+	}
+	return nil
+}
+
+type not struct {
+	c Condition
+}
+
+func (n *not) Evaluate(state State) (bool, error) {
+	holds, err := n.c.Evaluate(state)
+	return !holds, err
+}
+
+func (n *not) validate(visible func(step string) bool) error {
+	return n.c.validate(visible)
+}
+
+type combine struct {
+	all        bool
+	conditions []Condition
+}
+
+func (c *combine) Evaluate(state State) (bool, error) {
+	for _, condition := range c.conditions {
+		holds, err := condition.Evaluate(state)
+		if err != nil {
+			return false, err
+		}
+		if holds != c.all {
+			return holds, nil
+		}
+	}
+	return c.all, nil
+}
+
+func (c *combine) validate(visible func(step string) bool) error {
+	if len(c.conditions) == 0 {
+		return fmt.Errorf("empty condition list")
+	}
+	for _, condition := range c.conditions {
+		if err := condition.validate(visible); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// equal compares a decoded JSON value with a literal
+func equal(value, literal any) bool {
+	a, aNumber := number(value)
+	b, bNumber := number(literal)
+	if aNumber || bNumber {
+		return aNumber && bNumber && a == b
+	}
+
+	x, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	y, err := json.Marshal(literal)
+	if err != nil {
+		return false
+	}
+	return string(x) == string(y)
+}
+
+// number converts a decoded JSON number or a Go number literal to float64
+func number(v any) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,95 @@
+package definition
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestConditionEvaluate(t *testing.T) {
+	state := State{
+		Payload: json.RawMessage(`{"total_amount": 250, "express": true, "country": "ID", "coupon": null}`),
+		Outputs: map[string]json.RawMessage{
+			"check_fraud": json.RawMessage(`{"score": 0.2, "flags": []}`),
+		},
+	}
+
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+	}{
+		{"exists", Exists("payload.country"), true},
+		{"null does not exist", Exists("payload.coupon"), false},
+		{"missing field does not exist", Exists("payload.gift"), false},
+		{"output of a step that did not run", Exists("steps.verify_address.ok"), false},
+		{"equals string", Equals("payload.country", "ID"), true},
+		{"equals bool", Equals("payload.express", true), true},
+		{"equals number of another type", Equals("payload.total_amount", 250.0), true},
+		{"number does not equal its string", Equals("payload.total_amount", "250"), false},
+		{"equals empty array", Equals("steps.check_fraud.flags", []any{}), true},
+		{"missing value equals nothing", Equals("payload.gift", nil), false},
+		{"not equals", NotEquals("payload.country", "SG"), true},
+		{"missing value is not equal", NotEquals("payload.gift", "card"), true},
+		{"greater than", GreaterThan("payload.total_amount", 100), true},
+		{"not greater than", GreaterThan("payload.total_amount", 250), false},
+		{"less than", LessThan("steps.check_fraud.score", 0.5), true},
+		{"non-number is not compared", GreaterThan("payload.country", 0), false},
+		{"not", Not(Exists("payload.gift")), true},
+		{"all", All(Exists("payload.country"), GreaterThan("payload.total_amount", 100)), true},
+		{"all with one false", All(Exists("payload.country"), Exists("payload.gift")), false},
+		{"any", Any(Exists("payload.gift"), Equals("payload.express", true)), true},
+		{"any all false", Any(Exists("payload.gift"), Equals("payload.express", false)), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.condition.Evaluate(state)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionEvaluateInvalidPayload(t *testing.T) {
+	_, err := Exists("payload.country").Evaluate(State{Payload: json.RawMessage(`{`)})
+	if err == nil || !strings.Contains(err.Error(), "invalid saga payload") {
+		t.Fatalf("Evaluate() error = %v, want invalid saga payload", err)
+	}
+}
+
+func TestConditionValidate(t *testing.T) {
+	visible := func(step string) bool { return step == "check_fraud" }
+
+	tests := []struct {
+		name      string
+		condition Condition
+		wantErr   string
+	}{
+		{"earlier step", LessThan("steps.check_fraud.score", 0.5), ""},
+		{"later step", Exists("steps.ship_order.tracking_id"), "step ship_order has not run"},
+		{"item outside each", Exists("item.sku"), "only valid inside an each"},
+		{"nested", Not(Any(Exists("payload.gift"), Exists("input.gift"))), "must start with payload, steps or item"},
+		{"empty list", All(), "empty condition list"},
+		{"compared value", Equals("payload.country", struct{}{}), "unsupported template value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.condition.validate(visible)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package definition
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Action invokes one downstream operation. The idempotency key is owned by the
+// orchestrator and must be forwarded, so that a retried call is deduplicated.
+type Action func(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error)
+
+// RequestBuilder builds the request of an action from the saga state
+type RequestBuilder func(state State) (json.RawMessage, error)
+
+// ResponseMapper turns the raw response of an action into what is stored in
+// saga_steps.response_payload and exposed to later steps
+type ResponseMapper func(response json.RawMessage) (json.RawMessage, error)
+
+// Migration upgrades the payload of an in-flight saga created with the
+// previous version of a definition to the payload of the new version
+type Migration func(payload json.RawMessage) (json.RawMessage, error)
+
+// State is everything a step can read when building its requests:
+// the saga payload and the mapped responses of the steps that already succeeded.
+type State struct {
+	Payload json.RawMessage
+	Outputs map[string]json.RawMessage
+}
+
+// Step describes one forward action of a saga and the action that undoes it
+type Step struct {
+	Name                string
+	Action              Action
+	Request             RequestBuilder
+	Response            ResponseMapper
+	Compensation        Action
+	CompensationRequest RequestBuilder
+
+	// The templates the requests are built from, when declared as templates
+	RequestTemplate      Template
+	CompensationTemplate Template
+
+	// When, if set, decides whether the step runs or is SKIPPED
+	When Condition
+	// Group numbers the stages of the saga from 1. Steps of the same group
+	// run concurrently and must not read each other's outputs.
+	Group int
+	// Retry applies to both the forward and the compensating call
+	Retry RetryPolicy
+	// Deadline bounds the forward phase of the step, retries included; 0 means none
+	Deadline time.Duration
+	// Wait, when set, makes the step asynchronous: the action only issues a
+	// command, then the step waits up to Wait for a completion correlated by
+	// saga id and step name. A timeout is retried like a failed call.
+	Wait time.Duration
+	// Signal, when set, makes the step wait up to Wait for a signal of that
+	// name pushed into the saga instead of calling an action. The payload of
+	// the signal becomes the output of the step. A timeout is retried by
+	// waiting again.
+	Signal string
+	// Approval makes the step wait up to Wait for a human decision instead
+	// of calling an action. A rejection, or no decision in time, fails the
+	// step and compensates the saga.
+	Approval bool
+	// SubSaga, when set, makes the step start a child saga of that type with
+	// the request as its payload and wait up to Wait for its terminal status,
+	// instead of calling an action. The child is compensated with the step.
+	SubSaga string
+	// Pivot marks the point of no return: once the group of the pivot step
+	// succeeded, the saga is never compensated and later steps are retried
+	// forward until they succeed
+	Pivot bool
+}
+
+// HasCompensation reports whether the step can be undone
+func (s Step) HasCompensation() bool {
+	return s.Compensation != nil
+}
+
+// IsAsync reports whether the step waits for a completion after its action
+func (s Step) IsAsync() bool {
+	return s.Wait > 0 && s.HasAction()
+}
+
+// HasAction reports whether the step calls an action, rather than only
+// waiting for a signal, an approval or a sub-saga
+func (s Step) HasAction() bool {
+	return !s.AwaitsSignal() && !s.AwaitsApproval() && !s.StartsSubSaga()
+}
+
+// AwaitsSignal reports whether the step waits for a signal instead of
+// calling an action
+func (s Step) AwaitsSignal() bool {
+	return s.Signal != ""
+}
+
+// AwaitsApproval reports whether the step waits for a human decision
+func (s Step) AwaitsApproval() bool {
+	return s.Approval
+}
+
+// StartsSubSaga reports whether the step runs a child saga
+func (s Step) StartsSubSaga() bool {
+	return s.SubSaga != ""
+}
+
+// MapResponse applies the response mapper, storing the raw response when none is set
+func (s Step) MapResponse(response json.RawMessage) (json.RawMessage, error) {
+	if s.Response == nil {
+		return response, nil
+	}
+	return s.Response(response)
+}
+
+// Definition is a saga type declared once as an ordered list of steps
+type Definition struct {
+	Type string
+	// Version numbers the revisions of a saga type from 1. A saga keeps
+	// running against the version it was created with.
+	Version int
+	Steps   []Step
+	// Deadline bounds the forward phase of the saga from its creation; 0 means none.
+	// A saga past its deadline is compensated, unless it passed its pivot.
+	Deadline time.Duration
+	// Migrate, when set, upgrades the in-flight sagas of the previous version
+	// to this one. Their steps are matched by name: a step both versions
+	// declare keeps its state, a new one starts PENDING. A saga is not
+	// upgraded while a step this version dropped may have taken effect.
+	Migrate Migration
+}
+
+// PivotGroup returns the group of the pivot step, 0 when the saga has none
+func (d *Definition) PivotGroup() int {
+	for _, step := range d.Steps {
+		if step.Pivot {
+			return step.Group
+		}
+	}
+	return 0
+}
+
+// Groups returns the indexes into Steps of each group, in execution order
+func (d *Definition) Groups() [][]int {
+	var groups [][]int
+	for i, step := range d.Steps {
+		if i == 0 || step.Group != d.Steps[i-1].Group {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], i)
+	}
+	return groups
+}
+
+// Step looks up a step by name
+func (d *Definition) Step(name string) (Step, bool) {
+	for _, step := range d.Steps {
+		if step.Name == name {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
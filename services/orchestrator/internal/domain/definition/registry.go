@@ -0,0 +1,116 @@
+package definition
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+// Registry maps a saga_type to its definitions, one per version. New flows
+// are added by registering a definition; the executor only ever looks them
+// up here. New sagas start on the latest version of their type, older
+// versions stay registered for the sagas still running against them.
+type Registry struct {
+	mu          sync.RWMutex
+	definitions map[string][]*Definition
+}
+
+func NewRegistry() *Registry {
+	return &Registry{definitions: make(map[string][]*Definition)}
+}
+
+// Register adds a definition. A version of a saga type can only be
+// registered once.
+func (r *Registry) Register(def *Definition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions := r.definitions[def.Type]
+	for _, existing := range versions {
+		if existing.Version == def.Version {
+			return pErrors.E(pErrors.Conflict, fmt.Sprintf("saga type already registered: %s version %d", def.Type, def.Version), nil)
+		}
+	}
+
+	versions = append(versions, def)
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	r.definitions[def.Type] = versions
+	return nil
+}
+
+// Get returns the latest definition of a saga type, the one new sagas start on
+func (r *Registry) Get(sagaType string) (*Definition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, ok := r.definitions[sagaType]
+	if !ok {
+		return nil, pErrors.E(pErrors.NotFound, "unknown saga type: "+sagaType, nil)
+	}
+	return versions[len(versions)-1], nil
+}
+
+// GetVersion returns one version of the definition of a saga type
+func (r *Registry) GetVersion(sagaType string, version int) (*Definition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, ok := r.definitions[sagaType]
+	if !ok {
+		return nil, pErrors.E(pErrors.NotFound, "unknown saga type: "+sagaType, nil)
+	}
+	for _, def := range versions {
+		if def.Version == version {
+			return def, nil
+		}
+	}
+	return nil, pErrors.E(pErrors.NotFound, fmt.Sprintf("saga type %s has no version %d registered", sagaType, version), nil)
+}
+
+// Versions lists the registered versions of a saga type, oldest first
+func (r *Registry) Versions(sagaType string) []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := make([]int, len(r.definitions[sagaType]))
+	for i, def := range r.definitions[sagaType] {
+		versions[i] = def.Version
+	}
+	return versions
+}
+
+// Upgrades returns the definitions a saga of the given version upgrades to,
+// in order: the versions that follow it and each migrate from the one before.
+// It stops at the first version without a migration.
+func (r *Registry) Upgrades(sagaType string, version int) []*Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var upgrades []*Definition
+	for _, def := range r.definitions[sagaType] {
+		if def.Version <= version {
+			continue
+		}
+		if def.Version != version+1 || def.Migrate == nil {
+			break
+		}
+		upgrades = append(upgrades, def)
+		version = def.Version
+	}
+	return upgrades
+}
+
+// Types lists the registered saga types in alphabetical order
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.definitions))
+	for t := range r.definitions {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,124 @@
+package definition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy decides whether and when a failed call of a step is retried.
+// The zero value never retries.
+type RetryPolicy struct {
+	// MaxAttempts counts the first call, so 3 means up to 2 retries
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	// MaxBackoff caps the backoff before jitter is applied; 0 means no cap
+	MaxBackoff time.Duration
+	Multiplier float64
+	// Jitter spreads each backoff uniformly by ±Jitter of its value (0 to 1)
+	Jitter float64
+	// RetryableCodes are the gRPC status codes worth retrying.
+	// InvalidArgument is never retried, the request would fail again.
+	RetryableCodes []codes.Code
+}
+
+// DefaultRetryPolicy retries transient transport failures up to 4 times,
+// waiting about 3 seconds in total
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	RetryableCodes: []codes.Code{codes.Unavailable, codes.DeadlineExceeded},
+}
+
+// ShouldRetry reports whether a call that failed with err is retried, given
+// the number of retries already made
+func (p RetryPolicy) ShouldRetry(err error, retries int) bool {
+	return retries+1 < p.MaxAttempts && p.Retryable(err)
+}
+
+// Retryable reports whether err has one of the retryable status codes.
+// A step timeout is a DeadlineExceeded.
+func (p RetryPolicy) Retryable(err error) bool {
+	code := status.Code(err)
+	if errors.Is(err, context.DeadlineExceeded) {
+		code = codes.DeadlineExceeded
+	}
+	if code == codes.InvalidArgument {
+		return false
+	}
+	return slices.Contains(p.RetryableCodes, code)
+}
+
+// Backoff returns how long to wait before the given retry (1 for the first one)
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	// Without a cap the backoff grows past what a Duration holds
+	if backoff >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(backoff)
+}
+
+// maxRecoveryBackoff caps the backoff of forward recovery when the policy
+// does not
+const maxRecoveryBackoff = time.Minute
+
+// RecoveryBackoff returns how long a step past the pivot waits before the
+// given retry. Forward recovery never gives up, so the backoff is always
+// capped; a policy without backoff recovers with DefaultRetryPolicy's.
+func (p RetryPolicy) RecoveryBackoff(retry int) time.Duration {
+	if p.InitialBackoff <= 0 {
+		p = DefaultRetryPolicy
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 1
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = maxRecoveryBackoff
+	}
+	return p.Backoff(retry)
+}
+
+func (p RetryPolicy) validate() error {
+	if p.MaxAttempts <= 1 {
+		return nil
+	}
+	if p.InitialBackoff <= 0 {
+		return pErrors.E(pErrors.Invalid, "initial backoff must be positive", nil)
+	}
+	if p.Multiplier < 1 {
+		return pErrors.E(pErrors.Invalid, "backoff multiplier must be at least 1", nil)
+	}
+	if p.MaxBackoff != 0 && p.MaxBackoff < p.InitialBackoff {
+		return pErrors.E(pErrors.Invalid, "max backoff must not be below initial backoff", nil)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return pErrors.E(pErrors.Invalid, "jitter must be between 0 and 1", nil)
+	}
+	if len(p.RetryableCodes) == 0 {
+		return pErrors.E(pErrors.Invalid, "no retryable status codes", nil)
+	}
+	for _, code := range p.RetryableCodes {
+		if code == codes.OK || code == codes.InvalidArgument {
+			return pErrors.E(pErrors.Invalid, fmt.Sprintf("status code %s is not retryable", code), nil)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package definition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestShouldRetry(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		Multiplier:     2,
+		RetryableCodes: []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.InvalidArgument},
+	}
+	unavailable := status.Error(codes.Unavailable, "payment service unavailable")
+
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		err     error
+		retries int
+		want    bool
+	}{
+		{"first failure", policy, unavailable, 0, true},
+		{"last retry left", policy, unavailable, 1, true},
+		{"max attempts reached", policy, unavailable, 2, false},
+		{"invalid argument is never retried", policy, status.Error(codes.InvalidArgument, "bad request"), 0, false},
+		{"step timeout", policy, fmt.Errorf("call: %w", context.DeadlineExceeded), 0, true},
+		{"code not listed", policy, status.Error(codes.NotFound, "order not found"), 0, false},
+		{"plain error", policy, errors.New("connection reset"), 0, false},
+		{"zero policy", RetryPolicy{}, unavailable, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.ShouldRetry(tt.err, tt.retries); got != tt.want {
+				t.Errorf("ShouldRetry(%v, %d) = %v, want %v", tt.err, tt.retries, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	capped := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	uncapped := RetryPolicy{InitialBackoff: time.Second, Multiplier: 10}
+
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		want   time.Duration
+	}{
+		{"first retry", capped, 1, 100 * time.Millisecond},
+		{"doubled", capped, 3, 400 * time.Millisecond},
+		{"below the cap", capped, 4, 800 * time.Millisecond},
+		{"capped", capped, 5, time.Second},
+		{"long after the cap", capped, 50, time.Second},
+		{"constant", RetryPolicy{InitialBackoff: time.Second, Multiplier: 1}, 7, time.Second},
+		{"uncapped", uncapped, 4, 1000 * time.Second},
+		{"uncapped past a Duration", uncapped, 100, math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Backoff(tt.retry); got != tt.want {
+				t.Errorf("Backoff(%d) = %v, want %v", tt.retry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 4 * time.Second, Multiplier: 2, Jitter: 0.2}
+
+	tests := []struct {
+		retry    int
+		min, max time.Duration
+	}{
+		{1, 800 * time.Millisecond, 1200 * time.Millisecond},
+		{2, 1600 * time.Millisecond, 2400 * time.Millisecond},
+		// Jitter is applied after the cap
+		{5, 3200 * time.Millisecond, 4800 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := policy.Backoff(tt.retry); got < tt.min || got > tt.max {
+				t.Fatalf("Backoff(%d) = %v, want between %v and %v", tt.retry, got, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestRecoveryBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   RetryPolicy
+		retry    int
+		min, max time.Duration
+	}{
+		{
+			name:   "no backoff falls back to the default policy",
+			policy: RetryPolicy{},
+			retry:  1,
+			min:    160 * time.Millisecond,
+			max:    240 * time.Millisecond,
+		},
+		{
+			name:   "default policy keeps its own cap",
+			policy: RetryPolicy{},
+			retry:  20,
+			min:    4 * time.Second,
+			max:    6 * time.Second,
+		},
+		{
+			name:   "uncapped policy is capped at a minute",
+			policy: RetryPolicy{InitialBackoff: time.Second, Multiplier: 2},
+			retry:  30,
+			min:    time.Minute,
+			max:    time.Minute,
+		},
+		{
+			name:   "policy cap is kept",
+			policy: RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second, Multiplier: 2},
+			retry:  30,
+			min:    10 * time.Second,
+			max:    10 * time.Second,
+		},
+		{
+			name:   "multiplier below 1 is constant",
+			policy: RetryPolicy{InitialBackoff: time.Second, Multiplier: 0.5},
+			retry:  5,
+			min:    time.Second,
+			max:    time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.RecoveryBackoff(tt.retry); got < tt.min || got > tt.max {
+				t.Errorf("RecoveryBackoff(%d) = %v, want between %v and %v", tt.retry, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	valid := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}
+
+	tests := []struct {
+		name    string
+		change  func(p *RetryPolicy)
+		wantErr string
+	}{
+		{"valid", func(p *RetryPolicy) {}, ""},
+		{"default", func(p *RetryPolicy) { *p = DefaultRetryPolicy }, ""},
+		{"no retry", func(p *RetryPolicy) { *p = RetryPolicy{MaxAttempts: 1} }, ""},
+		{"no initial backoff", func(p *RetryPolicy) { p.InitialBackoff = 0 }, "initial backoff must be positive"},
+		{"shrinking backoff", func(p *RetryPolicy) { p.Multiplier = 0.5 }, "multiplier must be at least 1"},
+		{"cap below initial backoff", func(p *RetryPolicy) { p.MaxBackoff = time.Millisecond }, "max backoff must not be below initial backoff"},
+		{"jitter above 1", func(p *RetryPolicy) { p.Jitter = 1.5 }, "jitter must be between 0 and 1"},
+		{"no codes", func(p *RetryPolicy) { p.RetryableCodes = nil }, "no retryable status codes"},
+		{"invalid argument", func(p *RetryPolicy) { p.RetryableCodes = []codes.Code{codes.InvalidArgument} }, "InvalidArgument is not retryable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := valid
+			tt.change(&policy)
+			err := policy.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
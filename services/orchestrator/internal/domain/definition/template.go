@@ -0,0 +1,292 @@
+package definition
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+// Template declares a request as a JSON object whose values may reference
+// the saga state. Values are literals, nested objects (Template or
+// map[string]any), arrays ([]any), a Ref or an Each:
+//
+//	definition.Template{
+//		"order_id": definition.Ref("steps.create_order.order_id"),
+//		"amount":   definition.Ref("payload.total_amount"),
+//		"currency": "USD",
+//	}
+//
+// Unlike a RequestBuilder, the references of a template are checked when the
+// definition is built, before it can be registered.
+type Template map[string]any
+
+// Ref references a value of the saga state by a dotted path:
+//
+//	payload.<field>...       the saga input
+//	steps.<step>.<field>...  the stored response of a step that ran before
+//	item.<field>...          the current element inside an Each
+//
+// Path segments are object keys or array indexes.
+type Ref string
+
+// Each builds an array with one Item per element of the array at Over.
+// Inside Item, the element is referenced with the item root.
+type Each struct {
+	Over Ref
+	Item Template
+}
+
+// ForEach maps every element of the array at over through item
+func ForEach(over Ref, item Template) Each {
+	return Each{Over: over, Item: item}
+}
+
+const (
+	rootPayload = "payload"
+	rootSteps   = "steps"
+	rootItem    = "item"
+)
+
+// Build resolves the references against the saga state.
+// It has the signature of a RequestBuilder.
+func (t Template) Build(state State) (json.RawMessage, error) {
+	s := &scope{state: state, outputs: make(map[string]any)}
+	value, err := s.resolve(t)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// scope is the state references are resolved against. Documents are decoded
+// once per Build; numbers are kept as written.
+type scope struct {
+	state   State
+	payload any
+	decoded bool
+	outputs map[string]any
+	item    any
+	inEach  bool
+}
+
+func (s *scope) resolve(value any) (any, error) {
+	switch v := value.(type) {
+	case Ref:
+		return s.lookup(v)
+	case Each:
+		return s.each(v)
+	case Template:
+		return s.resolveObject(v)
+	case map[string]any:
+		return s.resolveObject(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			resolved, err := s.resolve(elem)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func (s *scope) resolveObject(object map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(object))
+	for key, value := range object {
+		resolved, err := s.resolve(value)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = resolved
+	}
+	return out, nil
+}
+
+func (s *scope) each(e Each) (any, error) {
+	over, err := s.lookup(e.Over)
+	if err != nil {
+		return nil, err
+	}
+
+	elems, ok := over.([]any)
+	if !ok {
+		return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("%s is not an array", e.Over), nil)
+	}
+
+	out := make([]any, len(elems))
+	for i, elem := range elems {
+		inner := *s
+		inner.item, inner.inEach = elem, true
+		resolved, err := inner.resolveObject(e.Item)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = resolved
+	}
+	return out, nil
+}
+
+func (s *scope) lookup(ref Ref) (any, error) {
+	value, found, err := s.find(ref)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if segments := strings.Split(string(ref), "."); segments[0] == rootSteps {
+			if _, ok := s.state.Outputs[segments[1]]; !ok {
+				return nil, pErrors.E(pErrors.Invalid, "missing output of step "+segments[1], nil)
+			}
+		}
+		return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("missing value at %s", ref), nil)
+	}
+	return value, nil
+}
+
+// find resolves a reference, reporting whether there is a non-null value at
+// its path. The output of a step that did not run is not found.
+func (s *scope) find(ref Ref) (any, bool, error) {
+	segments := strings.Split(string(ref), ".")
+
+	var doc any
+	var path []string
+	switch segments[0] {
+	case rootPayload:
+		if !s.decoded {
+			payload, err := decodeDocument(s.state.Payload)
+			if err != nil {
+				return nil, false, pErrors.E(pErrors.Invalid, "invalid saga payload", err)
+			}
+			s.payload, s.decoded = payload, true
+		}
+		doc, path = s.payload, segments[1:]
+	case rootSteps:
+		name := segments[1]
+		output, ok := s.outputs[name]
+		if !ok {
+			raw, ok := s.state.Outputs[name]
+			if !ok {
+				return nil, false, nil
+			}
+			var err error
+			if output, err = decodeDocument(raw); err != nil {
+				return nil, false, pErrors.E(pErrors.Invalid, "invalid output of step "+name, err)
+			}
+			s.outputs[name] = output
+		}
+		doc, path = output, segments[2:]
+	case rootItem:
+		doc, path = s.item, segments[1:]
+	}
+
+	for _, segment := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[segment]
+			if !ok {
+				return nil, false, nil
+			}
+			doc = value
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false, nil
+			}
+			doc = node[i]
+		default:
+			return nil, false, nil
+		}
+	}
+
+	return doc, doc != nil, nil
+}
+
+func decodeDocument(raw json.RawMessage) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// validate checks the template and its references. visible reports whether
+// a step's output can be read by this template.
+func (t Template) validate(visible func(step string) bool) error {
+	return validateValue(t, visible, false)
+}
+
+func validateValue(value any, visible func(step string) bool, inEach bool) error {
+	switch v := value.(type) {
+	case Ref:
+		return v.validate(visible, inEach)
+	case Each:
+		if err := v.Over.validate(visible, inEach); err != nil {
+			return err
+		}
+		if v.Item == nil {
+			return fmt.Errorf("each over %s has no item", v.Over)
+		}
+		return validateValue(v.Item, visible, true)
+	case Template:
+		return validateValue(map[string]any(v), visible, inEach)
+	case map[string]any:
+		for key, elem := range v {
+			if err := validateValue(elem, visible, inEach); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		return nil
+	case []any:
+		for i, elem := range v {
+			if err := validateValue(elem, visible, inEach); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return nil
+	case nil, string, bool, json.Number,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return nil
+	default:
+		return fmt.Errorf("unsupported template value of type %T", value)
+	}
+}
+
+func (r Ref) validate(visible func(step string) bool, inEach bool) error {
+	segments := strings.Split(string(r), ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return fmt.Errorf("malformed reference %q", r)
+		}
+	}
+
+	switch segments[0] {
+	case rootPayload:
+		return nil
+	case rootSteps:
+		if len(segments) < 2 {
+			return fmt.Errorf("reference %q names no step", r)
+		}
+		if !visible(segments[1]) {
+			return fmt.Errorf("reference %q: step %s has not run when the request is built", r, segments[1])
+		}
+		return nil
+	case rootItem:
+		if !inEach {
+			return fmt.Errorf("reference %q is only valid inside an each", r)
+		}
+		return nil
+	default:
+		return fmt.Errorf("reference %q must start with payload, steps or item", r)
+	}
+}
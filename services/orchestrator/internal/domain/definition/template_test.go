@@ -0,0 +1,230 @@
+package definition
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTemplateBuild(t *testing.T) {
+	state := State{
+		Payload: json.RawMessage(`{
+			"customer_id": "c-1",
+			"total_amount": 120.50,
+			"items": [
+				{"product_id": "p-1", "quantity": 2},
+				{"product_id": "p-2", "quantity": 1}
+			]
+		}`),
+		Outputs: map[string]json.RawMessage{
+			"create_order": json.RawMessage(`{"order_id": "o-1", "lines": ["a", "b"]}`),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template Template
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "literals",
+			template: Template{"currency": "USD", "express": true, "count": 3},
+			want:     `{"count":3,"currency":"USD","express":true}`,
+		},
+		{
+			name: "payload and step references",
+			template: Template{
+				"order_id": Ref("steps.create_order.order_id"),
+				"amount":   Ref("payload.total_amount"),
+			},
+			want: `{"amount":120.50,"order_id":"o-1"}`,
+		},
+		{
+			name:     "array index",
+			template: Template{"first": Ref("steps.create_order.lines.1")},
+			want:     `{"first":"b"}`,
+		},
+		{
+			name: "nested objects and arrays",
+			template: Template{
+				"customer": map[string]any{"id": Ref("payload.customer_id")},
+				"tags":     []any{"new", Ref("payload.customer_id")},
+			},
+			want: `{"customer":{"id":"c-1"},"tags":["new","c-1"]}`,
+		},
+		{
+			name: "each",
+			template: Template{
+				"items": ForEach("payload.items", Template{
+					"sku":      Ref("item.product_id"),
+					"quantity": Ref("item.quantity"),
+					"order_id": Ref("steps.create_order.order_id"),
+				}),
+			},
+			want: `{"items":[{"order_id":"o-1","quantity":2,"sku":"p-1"},{"order_id":"o-1","quantity":1,"sku":"p-2"}]}`,
+		},
+		{
+			name:     "missing payload value",
+			template: Template{"coupon": Ref("payload.coupon")},
+			wantErr:  "missing value at payload.coupon",
+		},
+		{
+			name:     "missing step output",
+			template: Template{"payment_id": Ref("steps.process_payment.payment_id")},
+			wantErr:  "missing output of step process_payment",
+		},
+		{
+			name:     "each over a non-array",
+			template: Template{"items": ForEach("payload.customer_id", Template{})},
+			wantErr:  "payload.customer_id is not an array",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.template.Build(state)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Build() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Build() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateValidate(t *testing.T) {
+	// Only create_order runs before the step of the templates
+	visible := func(step string) bool { return step == "create_order" }
+
+	tests := []struct {
+		name     string
+		template Template
+		wantErr  string
+	}{
+		{
+			name:     "payload",
+			template: Template{"amount": Ref("payload.total_amount")},
+		},
+		{
+			name:     "earlier step",
+			template: Template{"order_id": Ref("steps.create_order.order_id")},
+		},
+		{
+			name:     "item inside each",
+			template: Template{"items": ForEach("payload.items", Template{"sku": Ref("item.product_id")})},
+		},
+		{
+			name:     "later step",
+			template: Template{"payment_id": Ref("steps.process_payment.payment_id")},
+			wantErr:  "step process_payment has not run",
+		},
+		{
+			name:     "item outside each",
+			template: Template{"sku": Ref("item.product_id")},
+			wantErr:  "only valid inside an each",
+		},
+		{
+			name:     "unknown root",
+			template: Template{"amount": Ref("input.total_amount")},
+			wantErr:  "must start with payload, steps or item",
+		},
+		{
+			name:     "malformed path",
+			template: Template{"amount": Ref("payload..total_amount")},
+			wantErr:  "malformed reference",
+		},
+		{
+			name:     "step without name",
+			template: Template{"order": Ref("steps")},
+			wantErr:  "names no step",
+		},
+		{
+			name:     "each without item",
+			template: Template{"items": ForEach("payload.items", nil)},
+			wantErr:  "has no item",
+		},
+		{
+			name:     "unsupported value",
+			template: Template{"at": struct{}{}},
+			wantErr:  "unsupported template value",
+		},
+		{
+			name:     "nested error names its key",
+			template: Template{"customer": map[string]any{"id": Ref("item.id")}},
+			wantErr:  "customer: id: reference",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.template.validate(visible)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildValidatesTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(b *Builder)
+		wantErr string
+	}{
+		{
+			name: "compensation reads its own step",
+			build: func(b *Builder) {
+				b.Step("create_order").
+					Action(noop, emptyRequest).
+					CompensationTemplate(noop, Template{"order_id": Ref("steps.create_order.order_id")})
+			},
+		},
+		{
+			name: "request reads its own step",
+			build: func(b *Builder) {
+				b.Step("create_order").ActionTemplate(noop, Template{"order_id": Ref("steps.create_order.order_id")})
+			},
+			wantErr: "step create_order: request: order_id: reference",
+		},
+		{
+			name: "parallel sibling",
+			build: func(b *Builder) {
+				g := b.Parallel()
+				g.Step("process_payment").Action(noop, emptyRequest)
+				g.Step("reserve_inventory").ActionTemplate(noop, Template{"payment_id": Ref("steps.process_payment.payment_id")})
+			},
+			wantErr: "step process_payment has not run",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New("order_saga")
+			tt.build(b)
+			_, err := b.Build()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Build() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Build() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+// ApprovalStatus is the decision on an approval step
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending  ApprovalStatus = "PENDING"
+	ApprovalStatusApproved ApprovalStatus = "APPROVED"
+	ApprovalStatusRejected ApprovalStatus = "REJECTED"
+	// ApprovalStatusExpired is an approval nobody decided on in time, it is
+	// rejected automatically
+	ApprovalStatusExpired ApprovalStatus = "EXPIRED"
+)
+
+// Approval is the request of an approval step for a human decision, such as
+// a fraud analyst reviewing a high-value order. It is identified by saga id
+// and step name and decided once.
+type Approval struct {
+	SagaID      string
+	StepName    string
+	Status      ApprovalStatus
+	ApproverID  string
+	Comment     string
+	RequestedAt time.Time
+	ExpiresAt   time.Time
+	DecidedAt   *time.Time
+
+	// The saga under review, loaded with the pending approvals
+	SagaType    string
+	SagaPayload json.RawMessage
+}
+
+// NewApproval requests a decision on a step until the given time (factory function)
+func NewApproval(sagaID, stepName string, expiresAt time.Time) *Approval {
+	return &Approval{
+		SagaID:      sagaID,
+		StepName:    stepName,
+		Status:      ApprovalStatusPending,
+		RequestedAt: time.Now(),
+		ExpiresAt:   expiresAt,
+	}
+}
+
+// Decide records the decision of an approver
+func (a *Approval) Decide(approved bool, approverID, comment string) error {
+	if approverID == "" {
+		return pErrors.E(pErrors.Invalid, "approver id is required", nil)
+	}
+	if a.Status != ApprovalStatusPending {
+		return pErrors.E(pErrors.Conflict, "approval of step "+a.StepName+" is already "+string(a.Status), nil)
+	}
+	if !time.Now().Before(a.ExpiresAt) {
+		return pErrors.E(pErrors.Conflict, "approval of step "+a.StepName+" expired", nil)
+	}
+
+	now := time.Now()
+	a.Status = ApprovalStatusRejected
+	if approved {
+		a.Status = ApprovalStatusApproved
+	}
+	a.ApproverID = approverID
+	a.Comment = comment
+	a.DecidedAt = &now
+	return nil
+}
+
+// Approved reports whether the step may proceed
+func (a *Approval) Approved() bool {
+	return a.Status == ApprovalStatusApproved
+}
+
+// Reason explains a rejection
+func (a *Approval) Reason() string {
+	switch {
+	case a.Status == ApprovalStatusExpired:
+		return "approval expired at " + a.ExpiresAt.Format(time.RFC3339)
+	case a.Comment != "":
+		return "rejected by " + a.ApproverID + ": " + a.Comment
+	default:
+		return "rejected by " + a.ApproverID
+	}
+}
+
+// Output is what an approved step exposes to later steps
+func (a *Approval) Output() json.RawMessage {
+	// Plain values only, it cannot fail
+	b, _ := json.Marshal(map[string]any{
+		"approved":    a.Approved(),
+		"approver_id": a.ApproverID,
+		"comment":     a.Comment,
+		"decided_at":  a.DecidedAt,
+	})
+	return b
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Choreography is the progress of one choreographed saga run, as observed
+// from the domain events the services published. It is identified by the
+// correlation id the events carry, the id of the order for the checkout flow.
+// Version guards the status against concurrent observers.
+type Choreography struct {
+	CorrelationID string
+	Type          string
+	Status        SagaStatus
+	ErrorMessage  string
+	StartedAt     time.Time
+	UpdatedAt     time.Time
+	CompletedAt   *time.Time
+	Version       int64
+	Events        []*ChoreographyEvent
+}
+
+// ChoreographyEvent is one domain event observed for a run. An event type is
+// recorded once per run, redeliveries are ignored.
+type ChoreographyEvent struct {
+	CorrelationID string
+	Type          string
+	MessageID     string
+	Payload       json.RawMessage
+	PublishedAt   time.Time
+	ReceivedAt    time.Time
+}
+
+// Seen reports whether an event type was observed for the run
+func (c *Choreography) Seen(eventType string) bool {
+	for _, event := range c.Events {
+		if event.Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the run to a new status. Terminal statuses set CompletedAt.
+func (c *Choreography) Advance(status SagaStatus, errorMessage string) {
+	now := time.Now()
+	c.Status = status
+	c.ErrorMessage = errorMessage
+	c.UpdatedAt = now
+	if status == SagaStatusCompleted || status == SagaStatusCompensated {
+		c.CompletedAt = &now
+	} else {
+		c.CompletedAt = nil
+	}
+}
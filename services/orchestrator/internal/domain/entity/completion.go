@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+// StepCompletion is the outcome of the command of an asynchronous step,
+// reported by a callback or an event after the command was accepted. It is
+// correlated with its step by saga id and step name; the first one received
+// for a step wins.
+type StepCompletion struct {
+	SagaID   string
+	StepName string
+	// Payload is the result of a successful command, it becomes the
+	// response of the step
+	Payload json.RawMessage
+	// ErrorMessage is set when the command failed
+	ErrorMessage string
+	// Source is how the completion was reported, a callback or an event
+	Source     string
+	ReceivedAt time.Time
+}
+
+// NewStepCompletion creates a completion (factory function)
+func NewStepCompletion(sagaID, stepName string, payload json.RawMessage, errorMessage, source string) (*StepCompletion, error) {
+	if sagaID == "" || stepName == "" {
+		return nil, pErrors.E(pErrors.Invalid, "saga id and step name are required", nil)
+	}
+	if len(payload) > 0 {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &object); err != nil || object == nil {
+			return nil, pErrors.E(pErrors.Invalid, "completion payload must be a JSON object", err)
+		}
+	}
+
+	return &StepCompletion{
+		SagaID:       sagaID,
+		StepName:     stepName,
+		Payload:      payload,
+		ErrorMessage: errorMessage,
+		Source:       source,
+		ReceivedAt:   time.Now(),
+	}, nil
+}
+
+// Succeeded reports whether the command succeeded
+func (c *StepCompletion) Succeeded() bool {
+	return c.ErrorMessage == ""
+}
@@ -0,0 +1,311 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType names one transition of a saga or of one of its steps
+type EventType string
+
+const (
+	EventSagaCreated           EventType = "SagaCreated"
+	EventSagaStarted           EventType = "SagaStarted"
+	EventSagaCompleted         EventType = "SagaCompleted"
+	EventSagaCompensating      EventType = "SagaCompensating"
+	EventSagaCompensated       EventType = "SagaCompensated"
+	EventSagaFailed            EventType = "SagaFailed"
+	EventSagaForwardRecovering EventType = "SagaForwardRecovering"
+	EventSagaCancelRequested   EventType = "SagaCancelRequested"
+	EventSagaSignalReceived    EventType = "SagaSignalReceived"
+	EventSagaUpgraded          EventType = "SagaUpgraded"
+
+	EventStepCreated                EventType = "StepCreated"
+	EventStepStarted                EventType = "StepStarted"
+	EventStepWaiting                EventType = "StepWaiting"
+	EventStepTimedOut               EventType = "StepTimedOut"
+	EventStepCompletionReceived     EventType = "StepCompletionReceived"
+	EventApprovalRequested          EventType = "ApprovalRequested"
+	EventApprovalDecided            EventType = "ApprovalDecided"
+	EventStepRetryScheduled         EventType = "StepRetryScheduled"
+	EventStepSucceeded              EventType = "StepSucceeded"
+	EventStepSkipped                EventType = "StepSkipped"
+	EventStepFailed                 EventType = "StepFailed"
+	EventCompensationStarted        EventType = "CompensationStarted"
+	EventCompensationRetryScheduled EventType = "CompensationRetryScheduled"
+	EventStepCompensated            EventType = "StepCompensated"
+	EventCompensationFailed         EventType = "CompensationFailed"
+
+	EventOperatorIntervened EventType = "OperatorIntervened"
+)
+
+// SagaEvent is one entry of the append-only log of a saga. It is written in
+// the same transaction as the change it records and carries the state the
+// change produced, so the timeline shows exactly what happened.
+// StepName is empty for events of the whole saga.
+type SagaEvent struct {
+	Sequence  int64
+	SagaID    string
+	StepName  string
+	Type      EventType
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
+
+// SagaSnapshot is the state of a saga carried by its events.
+// The payload and definition version are only carried by SagaCreated.
+type SagaSnapshot struct {
+	Type              string          `json:"saga_type"`
+	DefinitionVersion int             `json:"definition_version,omitempty"`
+	Payload           json.RawMessage `json:"payload,omitempty"`
+	ParentSagaID      string          `json:"parent_saga_id,omitempty"`
+	ParentStep        string          `json:"parent_step,omitempty"`
+	Status            SagaStatus      `json:"status"`
+	ErrorMessage      string          `json:"error_message,omitempty"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+	CompletedAt       *time.Time      `json:"completed_at,omitempty"`
+}
+
+// StepSnapshot is the state of a saga step carried by its events
+type StepSnapshot struct {
+	ID              string          `json:"id"`
+	Order           int             `json:"step_order"`
+	Group           int             `json:"step_group"`
+	IdempotencyKey  string          `json:"idempotency_key"`
+	Status          StepStatus      `json:"status"`
+	RequestPayload  json.RawMessage `json:"request,omitempty"`
+	ResponsePayload json.RawMessage `json:"response,omitempty"`
+	ErrorMessage    string          `json:"error_message,omitempty"`
+	ExecutedAt      *time.Time      `json:"executed_at,omitempty"`
+	CompensatedAt   *time.Time      `json:"compensated_at,omitempty"`
+	RetryCount      int             `json:"retry_count"`
+	NextRetryAt     *time.Time      `json:"next_retry_at,omitempty"`
+	WaitUntil       *time.Time      `json:"wait_until,omitempty"`
+}
+
+// UpgradeSnapshot is carried by SagaUpgraded: the saga as migrated to a later
+// definition version, with all of its steps
+type UpgradeSnapshot struct {
+	FromVersion int             `json:"from_version"`
+	Version     int             `json:"version"`
+	Payload     json.RawMessage `json:"payload"`
+	Steps       []UpgradedStep  `json:"steps"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// UpgradedStep is one step of an UpgradeSnapshot
+type UpgradedStep struct {
+	Name string `json:"name"`
+	StepSnapshot
+}
+
+// CancelSnapshot is carried by SagaCancelRequested
+type CancelSnapshot struct {
+	Reason      string    `json:"reason,omitempty"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// CompletionSnapshot is carried by StepCompletionReceived
+type CompletionSnapshot struct {
+	Payload      json.RawMessage `json:"payload,omitempty"`
+	ErrorMessage string          `json:"error_message,omitempty"`
+	Source       string          `json:"source"`
+}
+
+// ApprovalSnapshot is carried by ApprovalRequested and ApprovalDecided
+type ApprovalSnapshot struct {
+	Status     ApprovalStatus `json:"status"`
+	ApproverID string         `json:"approver_id,omitempty"`
+	Comment    string         `json:"comment,omitempty"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+}
+
+// SignalSnapshot is carried by SagaSignalReceived
+type SignalSnapshot struct {
+	Name           string          `json:"name"`
+	IdempotencyKey string          `json:"idempotency_key"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+}
+
+// InterventionSnapshot is carried by OperatorIntervened
+type InterventionSnapshot struct {
+	Action         InterventionAction `json:"action"`
+	OperatorID     string             `json:"operator_id"`
+	Note           string             `json:"note,omitempty"`
+	PreviousStatus SagaStatus         `json:"previous_status"`
+}
+
+// NewSagaEvent records the current status of a saga (factory function)
+func NewSagaEvent(saga *Saga) *SagaEvent {
+	snapshot := SagaSnapshot{
+		Type:         saga.Type,
+		Status:       saga.Status,
+		ErrorMessage: saga.ErrorMessage,
+		CreatedAt:    saga.CreatedAt,
+		UpdatedAt:    saga.UpdatedAt,
+		CompletedAt:  saga.CompletedAt,
+	}
+
+	var eventType EventType
+	switch saga.Status {
+	case SagaStatusPending:
+		eventType = EventSagaCreated
+		snapshot.DefinitionVersion = saga.DefinitionVersion
+		snapshot.Payload = saga.Payload
+		snapshot.ParentSagaID = saga.ParentSagaID
+		snapshot.ParentStep = saga.ParentStep
+	case SagaStatusExecuting:
+		eventType = EventSagaStarted
+	case SagaStatusCompleted:
+		eventType = EventSagaCompleted
+	case SagaStatusCompensating:
+		eventType = EventSagaCompensating
+	case SagaStatusCompensated:
+		eventType = EventSagaCompensated
+	case SagaStatusFailed:
+		eventType = EventSagaFailed
+	case SagaStatusForwardRecovering:
+		eventType = EventSagaForwardRecovering
+	}
+
+	return newEvent(saga.ID, "", eventType, snapshot)
+}
+
+// NewStepEvent records the current status of a step (factory function).
+// A step waiting for a retry records the scheduled retry.
+func NewStepEvent(step *SagaStep) *SagaEvent {
+	var eventType EventType
+	switch step.Status {
+	case StepStatusPending:
+		eventType = EventStepCreated
+		if step.NextRetryAt != nil {
+			eventType = EventStepRetryScheduled
+		}
+	case StepStatusExecuting:
+		eventType = EventStepStarted
+		if step.NextRetryAt != nil {
+			eventType = EventStepRetryScheduled
+		}
+	case StepStatusWaiting:
+		eventType = EventStepWaiting
+		if step.NextRetryAt != nil {
+			eventType = EventStepRetryScheduled
+		}
+	case StepStatusTimedOut:
+		eventType = EventStepTimedOut
+	case StepStatusSucceeded:
+		eventType = EventStepSucceeded
+	case StepStatusSkipped:
+		eventType = EventStepSkipped
+	case StepStatusFailed:
+		eventType = EventStepFailed
+	case StepStatusCompensating:
+		eventType = EventCompensationStarted
+		if step.NextRetryAt != nil {
+			eventType = EventCompensationRetryScheduled
+		}
+	case StepStatusCompensated:
+		eventType = EventStepCompensated
+	case StepStatusCompensationFailed:
+		eventType = EventCompensationFailed
+	}
+
+	return newEvent(step.SagaID, step.Name, eventType, stepSnapshot(step))
+}
+
+// stepSnapshot is the state of a step carried by its events
+func stepSnapshot(step *SagaStep) StepSnapshot {
+	return StepSnapshot{
+		ID:              step.ID,
+		Order:           step.Order,
+		Group:           step.Group,
+		IdempotencyKey:  step.IdempotencyKey,
+		Status:          step.Status,
+		RequestPayload:  step.RequestPayload,
+		ResponsePayload: step.ResponsePayload,
+		ErrorMessage:    step.ErrorMessage,
+		ExecutedAt:      step.ExecutedAt,
+		CompensatedAt:   step.CompensatedAt,
+		RetryCount:      step.RetryCount,
+		NextRetryAt:     step.NextRetryAt,
+		WaitUntil:       step.WaitUntil,
+	}
+}
+
+// NewUpgradeEvent records the migration of a saga to a later definition
+// version (factory function)
+func NewUpgradeEvent(saga *Saga, fromVersion int) *SagaEvent {
+	steps := make([]UpgradedStep, len(saga.Steps))
+	for i, step := range saga.Steps {
+		steps[i] = UpgradedStep{Name: step.Name, StepSnapshot: stepSnapshot(step)}
+	}
+	return newEvent(saga.ID, "", EventSagaUpgraded, UpgradeSnapshot{
+		FromVersion: fromVersion,
+		Version:     saga.DefinitionVersion,
+		Payload:     saga.Payload,
+		Steps:       steps,
+		UpdatedAt:   saga.UpdatedAt,
+	})
+}
+
+// NewCancelEvent records a cancellation request (factory function)
+func NewCancelEvent(sagaID, reason string, requestedAt time.Time) *SagaEvent {
+	return newEvent(sagaID, "", EventSagaCancelRequested, CancelSnapshot{Reason: reason, RequestedAt: requestedAt})
+}
+
+// NewCompletionEvent records the completion of an asynchronous step
+// (factory function)
+func NewCompletionEvent(completion *StepCompletion) *SagaEvent {
+	return newEvent(completion.SagaID, completion.StepName, EventStepCompletionReceived, CompletionSnapshot{
+		Payload:      completion.Payload,
+		ErrorMessage: completion.ErrorMessage,
+		Source:       completion.Source,
+	})
+}
+
+// NewApprovalEvent records the request for or the decision on an approval
+// (factory function)
+func NewApprovalEvent(approval *Approval) *SagaEvent {
+	eventType := EventApprovalDecided
+	if approval.Status == ApprovalStatusPending {
+		eventType = EventApprovalRequested
+	}
+	return newEvent(approval.SagaID, approval.StepName, eventType, ApprovalSnapshot{
+		Status:     approval.Status,
+		ApproverID: approval.ApproverID,
+		Comment:    approval.Comment,
+		ExpiresAt:  approval.ExpiresAt,
+	})
+}
+
+// NewSignalEvent records a signal pushed into a saga (factory function)
+func NewSignalEvent(signal *Signal) *SagaEvent {
+	return newEvent(signal.SagaID, "", EventSagaSignalReceived, SignalSnapshot{
+		Name:           signal.Name,
+		IdempotencyKey: signal.IdempotencyKey,
+		Payload:        signal.Payload,
+	})
+}
+
+// NewInterventionEvent records an operator action (factory function)
+func NewInterventionEvent(intervention *Intervention) *SagaEvent {
+	return newEvent(intervention.SagaID, intervention.StepName, EventOperatorIntervened, InterventionSnapshot{
+		Action:         intervention.Action,
+		OperatorID:     intervention.OperatorID,
+		Note:           intervention.Note,
+		PreviousStatus: intervention.PreviousStatus,
+	})
+}
+
+func newEvent(sagaID, stepName string, eventType EventType, data any) *SagaEvent {
+	// The snapshots only hold plain values and valid JSON
+	b, _ := json.Marshal(data)
+	return &SagaEvent{
+		SagaID:    sagaID,
+		StepName:  stepName,
+		Type:      eventType,
+		Data:      b,
+		CreatedAt: time.Now(),
+	}
+}
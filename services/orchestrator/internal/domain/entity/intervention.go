@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InterventionAction is what an operator did to a saga the executor could
+// not finish on its own
+type InterventionAction string
+
+const (
+	// InterventionRetryCompensation sends the failed compensation again
+	InterventionRetryCompensation InterventionAction = "RETRY_COMPENSATION"
+	// InterventionMarkCompensated records a step undone by hand
+	InterventionMarkCompensated InterventionAction = "MARK_COMPENSATED"
+	// InterventionForceComplete ends the saga as COMPLETED
+	InterventionForceComplete InterventionAction = "FORCE_COMPLETE"
+)
+
+// Intervention is one entry of the audit trail of operator actions.
+// StepName is empty for actions on the whole saga.
+type Intervention struct {
+	ID         string
+	SagaID     string
+	StepName   string
+	Action     InterventionAction
+	OperatorID string
+	Note       string
+	// Status of the saga before the intervention
+	PreviousStatus SagaStatus
+	CreatedAt      time.Time
+}
+
+// NewIntervention records an operator action (factory function)
+func NewIntervention(saga *Saga, stepName string, action InterventionAction, operatorID, note string) *Intervention {
+	return &Intervention{
+		ID:             uuid.New().String(),
+		SagaID:         saga.ID,
+		StepName:       stepName,
+		Action:         action,
+		OperatorID:     operatorID,
+		Note:           note,
+		PreviousStatus: saga.Status,
+		CreatedAt:      time.Now(),
+	}
+}
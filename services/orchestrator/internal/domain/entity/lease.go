@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// Lease is the right of one orchestrator instance to drive a saga until
+// ExpiresAt. Token is the fencing token of the lease: it changes every time
+// the lease is (re)acquired and is checked on every write to the saga.
+type Lease struct {
+	SagaID    string
+	OwnerID   string
+	Token     int64
+	ExpiresAt time.Time
+}
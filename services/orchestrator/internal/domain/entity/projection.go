@@ -0,0 +1,212 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+// Replay rebuilds a saga and its steps from its event log, oldest event
+// first. The sagas and saga_steps rows are a projection of the log: every
+// write to them appends an event carrying the state it wrote.
+func Replay(events []*SagaEvent) (*Saga, error) {
+	if len(events) == 0 {
+		return nil, pErrors.E(pErrors.NotFound, "saga has no events", nil)
+	}
+
+	var saga *Saga
+	steps := make(map[string]*SagaStep)
+	for _, event := range events {
+		if saga == nil && event.Type != EventSagaCreated {
+			return nil, pErrors.E(pErrors.Internal, fmt.Sprintf("saga %s: event log does not start with %s", event.SagaID, EventSagaCreated), nil)
+		}
+
+		var err error
+		switch {
+		case event.Type == EventOperatorIntervened, event.Type == EventStepCompletionReceived, event.Type == EventSagaSignalReceived,
+			event.Type == EventApprovalRequested, event.Type == EventApprovalDecided:
+			// The changes they lead to are recorded by their own events
+		case event.Type == EventSagaCancelRequested:
+			err = replayCancel(saga, event)
+		case event.Type == EventSagaUpgraded:
+			err = replayUpgrade(saga, steps, event)
+		case event.StepName == "":
+			saga, err = replaySaga(saga, event)
+		default:
+			err = replayStep(steps, event)
+		}
+		if err != nil {
+			return nil, pErrors.E(pErrors.Internal, fmt.Sprintf("saga %s: event %d (%s): %v", event.SagaID, event.Sequence, event.Type, err), err)
+		}
+	}
+
+	for _, step := range steps {
+		saga.Steps = append(saga.Steps, step)
+	}
+	sort.Slice(saga.Steps, func(i, j int) bool { return saga.Steps[i].Order < saga.Steps[j].Order })
+
+	return saga, nil
+}
+
+func replaySaga(saga *Saga, event *SagaEvent) (*Saga, error) {
+	var snapshot SagaSnapshot
+	if err := json.Unmarshal(event.Data, &snapshot); err != nil {
+		return nil, err
+	}
+
+	if event.Type == EventSagaCreated {
+		saga = &Saga{
+			ID:                event.SagaID,
+			Payload:           snapshot.Payload,
+			ParentSagaID:      snapshot.ParentSagaID,
+			ParentStep:        snapshot.ParentStep,
+			DefinitionVersion: snapshot.DefinitionVersion,
+		}
+		// Sagas created before definitions were versioned run version 1
+		if saga.DefinitionVersion == 0 {
+			saga.DefinitionVersion = 1
+		}
+	}
+	saga.Type = snapshot.Type
+	saga.Status = snapshot.Status
+	saga.ErrorMessage = snapshot.ErrorMessage
+	saga.CreatedAt = snapshot.CreatedAt
+	saga.UpdatedAt = snapshot.UpdatedAt
+	saga.CompletedAt = snapshot.CompletedAt
+	return saga, nil
+}
+
+// replayUpgrade lays the steps out as the upgrade recorded them; the steps
+// the new version dropped are removed
+func replayUpgrade(saga *Saga, steps map[string]*SagaStep, event *SagaEvent) error {
+	var snapshot UpgradeSnapshot
+	if err := json.Unmarshal(event.Data, &snapshot); err != nil {
+		return err
+	}
+
+	saga.DefinitionVersion = snapshot.Version
+	saga.Payload = snapshot.Payload
+	saga.UpdatedAt = snapshot.UpdatedAt
+	for name := range steps {
+		delete(steps, name)
+	}
+	for _, step := range snapshot.Steps {
+		steps[step.Name] = newStepFromSnapshot(event.SagaID, step.Name, step.StepSnapshot)
+	}
+	return nil
+}
+
+func replayCancel(saga *Saga, event *SagaEvent) error {
+	var snapshot CancelSnapshot
+	if err := json.Unmarshal(event.Data, &snapshot); err != nil {
+		return err
+	}
+
+	saga.CancelRequestedAt = &snapshot.RequestedAt
+	saga.CancelReason = snapshot.Reason
+	return nil
+}
+
+func replayStep(steps map[string]*SagaStep, event *SagaEvent) error {
+	var snapshot StepSnapshot
+	if err := json.Unmarshal(event.Data, &snapshot); err != nil {
+		return err
+	}
+
+	steps[event.StepName] = newStepFromSnapshot(event.SagaID, event.StepName, snapshot)
+	return nil
+}
+
+// newStepFromSnapshot is the step a snapshot carries
+func newStepFromSnapshot(sagaID, name string, snapshot StepSnapshot) *SagaStep {
+	return &SagaStep{
+		ID:              snapshot.ID,
+		SagaID:          sagaID,
+		Name:            name,
+		Order:           snapshot.Order,
+		Group:           snapshot.Group,
+		Status:          snapshot.Status,
+		IdempotencyKey:  snapshot.IdempotencyKey,
+		RequestPayload:  snapshot.RequestPayload,
+		ResponsePayload: snapshot.ResponsePayload,
+		ErrorMessage:    snapshot.ErrorMessage,
+		ExecutedAt:      snapshot.ExecutedAt,
+		CompensatedAt:   snapshot.CompensatedAt,
+		RetryCount:      snapshot.RetryCount,
+		NextRetryAt:     snapshot.NextRetryAt,
+		WaitUntil:       snapshot.WaitUntil,
+	}
+}
+
+// ProjectionDiff lists the fields where a stored saga differs from the saga
+// rebuilt from its events; none means the projection is correct.
+// Times are compared to the microsecond, the precision of the database.
+func ProjectionDiff(stored, rebuilt *Saga) []string {
+	var diff []string
+	field := func(name string, a, b any) {
+		if !reflect.DeepEqual(a, b) {
+			diff = append(diff, fmt.Sprintf("%s: stored %v, rebuilt %v", name, a, b))
+		}
+	}
+
+	field("status", stored.Status, rebuilt.Status)
+	field("saga_type", stored.Type, rebuilt.Type)
+	field("payload", jsonValue(stored.Payload), jsonValue(rebuilt.Payload))
+	field("error_message", stored.ErrorMessage, rebuilt.ErrorMessage)
+	field("created_at", micros(&stored.CreatedAt), micros(&rebuilt.CreatedAt))
+	field("updated_at", micros(&stored.UpdatedAt), micros(&rebuilt.UpdatedAt))
+	field("completed_at", micros(stored.CompletedAt), micros(rebuilt.CompletedAt))
+	field("cancel_requested_at", micros(stored.CancelRequestedAt), micros(rebuilt.CancelRequestedAt))
+	field("cancel_reason", stored.CancelReason, rebuilt.CancelReason)
+	field("parent_saga_id", stored.ParentSagaID, rebuilt.ParentSagaID)
+	field("parent_step", stored.ParentStep, rebuilt.ParentStep)
+	field("definition_version", stored.DefinitionVersion, rebuilt.DefinitionVersion)
+
+	if len(stored.Steps) != len(rebuilt.Steps) {
+		return append(diff, fmt.Sprintf("steps: stored %d, rebuilt %d", len(stored.Steps), len(rebuilt.Steps)))
+	}
+	for i, a := range stored.Steps {
+		b := rebuilt.Steps[i]
+		prefix := "step " + a.Name + ": "
+		field(prefix+"name", a.Name, b.Name)
+		field(prefix+"id", a.ID, b.ID)
+		field(prefix+"step_order", a.Order, b.Order)
+		field(prefix+"step_group", a.Group, b.Group)
+		field(prefix+"status", a.Status, b.Status)
+		field(prefix+"idempotency_key", a.IdempotencyKey, b.IdempotencyKey)
+		field(prefix+"request", jsonValue(a.RequestPayload), jsonValue(b.RequestPayload))
+		field(prefix+"response", jsonValue(a.ResponsePayload), jsonValue(b.ResponsePayload))
+		field(prefix+"error_message", a.ErrorMessage, b.ErrorMessage)
+		field(prefix+"executed_at", micros(a.ExecutedAt), micros(b.ExecutedAt))
+		field(prefix+"compensated_at", micros(a.CompensatedAt), micros(b.CompensatedAt))
+		field(prefix+"retry_count", a.RetryCount, b.RetryCount)
+		field(prefix+"next_retry_at", micros(a.NextRetryAt), micros(b.NextRetryAt))
+		field(prefix+"wait_until", micros(a.WaitUntil), micros(b.WaitUntil))
+	}
+
+	return diff
+}
+
+// micros rounds a time to the microsecond like the database does
+func micros(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Round(time.Microsecond).UTC().Format(time.RFC3339Nano)
+}
+
+// jsonValue decodes a document so that formatting differences do not count
+func jsonValue(b json.RawMessage) any {
+	if len(b) == 0 {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return string(b)
+	}
+	return v
+}
@@ -0,0 +1,268 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+// history runs a saga the way the executor does and records the events its
+// writes append. The saga is the projection the events must rebuild.
+type history struct {
+	saga   *Saga
+	events []*SagaEvent
+}
+
+func newHistory(t *testing.T, steps ...string) *history {
+	t.Helper()
+	saga, err := NewSaga("order", 1, json.RawMessage(`{"order_id":"order-1"}`))
+	if err != nil {
+		t.Fatalf("NewSaga() error = %v", err)
+	}
+	saga.ID = "saga-1"
+
+	h := &history{saga: saga}
+	h.record(NewSagaEvent(saga))
+	h.saga.Start()
+	h.record(NewSagaEvent(saga))
+	for i, name := range steps {
+		step := NewSagaStep(saga.ID, name, i+1, i+1)
+		saga.Steps = append(saga.Steps, step)
+		h.record(NewStepEvent(step))
+	}
+	return h
+}
+
+func (h *history) record(event *SagaEvent) {
+	event.Sequence = int64(len(h.events) + 1)
+	h.events = append(h.events, event)
+}
+
+// step changes a step and records it
+func (h *history) step(name string, change func(step *SagaStep)) {
+	step, _ := h.saga.Step(name)
+	change(step)
+	h.record(NewStepEvent(step))
+}
+
+// status changes the saga and records it
+func (h *history) status(change func(saga *Saga)) {
+	change(h.saga)
+	h.record(NewSagaEvent(h.saga))
+}
+
+// compensate runs reserve, fails charge and rolls reserve back
+func (h *history) compensate() {
+	h.step("reserve", func(s *SagaStep) { s.Start(json.RawMessage(`{"sku":"a"}`)) })
+	h.step("reserve", func(s *SagaStep) { s.Succeed(json.RawMessage(`{"reservation_id":"r-1"}`)) })
+	h.step("charge", func(s *SagaStep) { s.Start(json.RawMessage(`{"amount":10}`)) })
+	h.step("charge", func(s *SagaStep) { s.ScheduleRetry("payment service unavailable", time.Now().Add(time.Second)) })
+	h.step("charge", func(s *SagaStep) { s.Fail("card declined") })
+	h.status(func(s *Saga) { s.StartCompensation("step charge failed: card declined") })
+	h.step("reserve", func(s *SagaStep) { s.StartCompensation() })
+	h.step("reserve", func(s *SagaStep) { s.Compensated() })
+	h.status(func(s *Saga) { s.Compensated() })
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(t *testing.T) *history
+		check func(t *testing.T, saga *Saga)
+	}{
+		{
+			name: "compensated saga",
+			build: func(t *testing.T) *history {
+				h := newHistory(t, "reserve", "charge")
+				h.compensate()
+				return h
+			},
+			check: func(t *testing.T, saga *Saga) {
+				if saga.Status != SagaStatusCompensated || saga.CompletedAt == nil {
+					t.Errorf("saga = %s completed at %v, want COMPENSATED with a completion time", saga.Status, saga.CompletedAt)
+				}
+				charge, _ := saga.Step("charge")
+				if charge.RetryCount != 1 || charge.ErrorMessage != "card declined" {
+					t.Errorf("charge = %d retries, error %q, want 1 retry and the last error", charge.RetryCount, charge.ErrorMessage)
+				}
+			},
+		},
+		{
+			name: "informational events are skipped",
+			build: func(t *testing.T) *history {
+				h := newHistory(t, "reserve", "charge")
+				h.step("reserve", func(s *SagaStep) { s.Start(nil) })
+				h.step("reserve", func(s *SagaStep) { s.Wait(nil, time.Now().Add(time.Minute)) })
+
+				completion, err := NewStepCompletion(h.saga.ID, "reserve", json.RawMessage(`{"reservation_id":"r-1"}`), "", "callback")
+				if err != nil {
+					t.Fatalf("NewStepCompletion() error = %v", err)
+				}
+				h.record(NewCompletionEvent(completion))
+				signal, err := NewSignal(h.saga.ID, "fraud_review", "key-1", json.RawMessage(`{"cleared":true}`))
+				if err != nil {
+					t.Fatalf("NewSignal() error = %v", err)
+				}
+				h.record(NewSignalEvent(signal))
+				approval := NewApproval(h.saga.ID, "charge", time.Now().Add(time.Hour))
+				h.record(NewApprovalEvent(approval))
+				if err := approval.Decide(false, "bob", "too risky"); err != nil {
+					t.Fatalf("Decide() error = %v", err)
+				}
+				h.record(NewApprovalEvent(approval))
+
+				h.compensate()
+				h.status(func(s *Saga) { s.Fail("refund failed") })
+				h.record(NewInterventionEvent(NewIntervention(h.saga, "", InterventionForceComplete, "alice", "")))
+				h.status(func(s *Saga) { s.Complete() })
+				return h
+			},
+			check: func(t *testing.T, saga *Saga) {
+				if saga.Status != SagaStatusCompleted {
+					t.Errorf("saga status = %s, want COMPLETED", saga.Status)
+				}
+			},
+		},
+		{
+			name: "upgraded saga",
+			build: func(t *testing.T) *history {
+				h := newHistory(t, "reserve", "charge")
+				h.step("reserve", func(s *SagaStep) { s.Succeed(nil) })
+
+				reserve, _ := h.saga.Step("reserve")
+				h.saga.Upgrade(2, json.RawMessage(`{"order_id":"order-1","currency":"EUR"}`), []*SagaStep{
+					reserve,
+					NewSagaStep(h.saga.ID, "authorize", 2, 2),
+					NewSagaStep(h.saga.ID, "capture", 3, 3),
+				})
+				h.record(NewUpgradeEvent(h.saga, 1))
+				h.step("authorize", func(s *SagaStep) { s.Start(nil) })
+				return h
+			},
+			check: func(t *testing.T, saga *Saga) {
+				if saga.DefinitionVersion != 2 {
+					t.Errorf("definition version = %d, want 2", saga.DefinitionVersion)
+				}
+				if _, ok := saga.Step("charge"); ok {
+					t.Error("step charge dropped by the upgrade was rebuilt")
+				}
+			},
+		},
+		{
+			name: "cancel requested",
+			build: func(t *testing.T) *history {
+				h := newHistory(t, "reserve")
+				h.step("reserve", func(s *SagaStep) { s.Start(nil) })
+
+				requestedAt := time.Now()
+				h.saga.CancelRequestedAt = &requestedAt
+				h.saga.CancelReason = "customer cancelled"
+				h.record(NewCancelEvent(h.saga.ID, h.saga.CancelReason, requestedAt))
+
+				h.step("reserve", func(s *SagaStep) { s.Succeed(nil) })
+				h.status(func(s *Saga) { s.StartCompensation("customer cancelled") })
+				return h
+			},
+			check: func(t *testing.T, saga *Saga) {
+				if !saga.IsCancelRequested() || saga.CancelReason != "customer cancelled" {
+					t.Errorf("cancel = %v %q, want requested by the customer", saga.CancelRequestedAt, saga.CancelReason)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := tt.build(t)
+
+			rebuilt, err := Replay(h.events)
+			if err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+			if diff := ProjectionDiff(h.saga, rebuilt); len(diff) != 0 {
+				t.Errorf("ProjectionDiff() = %v, want none", diff)
+			}
+			tt.check(t, rebuilt)
+		})
+	}
+}
+
+func TestReplayInvalidLog(t *testing.T) {
+	h := newHistory(t, "reserve")
+
+	tests := []struct {
+		name     string
+		events   []*SagaEvent
+		wantCode pErrors.Code
+	}{
+		{"no events", nil, pErrors.NotFound},
+		{"created event missing", h.events[1:], pErrors.Internal},
+		{"corrupt snapshot", append(h.events[:1:1], &SagaEvent{SagaID: "saga-1", StepName: "reserve", Type: EventStepStarted, Data: json.RawMessage(`[]`)}), pErrors.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Replay(tt.events)
+			var e *pErrors.Error
+			if !errors.As(err, &e) || e.Code != tt.wantCode {
+				t.Fatalf("Replay() error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestProjectionDiff(t *testing.T) {
+	h := newHistory(t, "reserve", "charge")
+	h.compensate()
+
+	tests := []struct {
+		name   string
+		change func(stored *Saga)
+		want   []string
+	}{
+		{"same", func(stored *Saga) {}, nil},
+		{
+			name:   "formatting of the payload",
+			change: func(stored *Saga) { stored.Payload = json.RawMessage(`{ "order_id" : "order-1" }`) },
+		},
+		{
+			name:   "time rounded by the database",
+			change: func(stored *Saga) { stored.UpdatedAt = stored.UpdatedAt.Round(time.Microsecond) },
+		},
+		{
+			name: "status and step",
+			change: func(stored *Saga) {
+				stored.Status = SagaStatusCompensating
+				stored.Steps[0].Status = StepStatusCompensating
+			},
+			want: []string{"status: stored COMPENSATING, rebuilt COMPENSATED", "step reserve: status: stored COMPENSATING, rebuilt COMPENSATED"},
+		},
+		{
+			name:   "missing step",
+			change: func(stored *Saga) { stored.Steps = stored.Steps[:1] },
+			want:   []string{"steps: stored 1, rebuilt 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rebuilt, err := Replay(h.events)
+			if err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+			stored, err := Replay(h.events)
+			if err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+			tt.change(stored)
+
+			if got := ProjectionDiff(stored, rebuilt); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ProjectionDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
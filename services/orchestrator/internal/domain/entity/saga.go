@@ -0,0 +1,212 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+// SagaStatus represents the lifecycle state of a saga
+type SagaStatus string
+
+const (
+	SagaStatusPending      SagaStatus = "PENDING"
+	SagaStatusExecuting    SagaStatus = "EXECUTING"
+	SagaStatusCompleted    SagaStatus = "COMPLETED"
+	SagaStatusCompensating SagaStatus = "COMPENSATING"
+	SagaStatusCompensated  SagaStatus = "COMPENSATED"
+	SagaStatusFailed       SagaStatus = "FAILED"
+	// A step failed after the pivot: the saga retries it forward and is
+	// never compensated
+	SagaStatusForwardRecovering SagaStatus = "FORWARD_RECOVERING"
+)
+
+// Saga is the aggregate root of one distributed transaction.
+// Payload is the input data the saga was started with, Steps are ordered by StepOrder.
+type Saga struct {
+	ID           string
+	Type         string
+	Status       SagaStatus
+	Payload      json.RawMessage
+	ErrorMessage string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	CompletedAt  *time.Time
+	Steps        []*SagaStep
+
+	// Set when cancellation was requested through the API
+	CancelRequestedAt *time.Time
+	CancelReason      string
+
+	// Set on a sub-saga: the saga and the step that started it
+	ParentSagaID string
+	ParentStep   string
+
+	// DefinitionVersion is the version of the definition of Type the saga
+	// runs against, the latest one when it was created
+	DefinitionVersion int
+}
+
+// NewSaga creates a pending saga of a definition version (factory function).
+// The payload must be a JSON object.
+func NewSaga(sagaType string, version int, payload json.RawMessage) (*Saga, error) {
+	if sagaType == "" {
+		return nil, pErrors.E(pErrors.Invalid, "saga type is required", nil)
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &object); err != nil || object == nil {
+		return nil, pErrors.E(pErrors.Invalid, "payload must be a JSON object", err)
+	}
+
+	now := time.Now()
+	return &Saga{
+		Type:              sagaType,
+		DefinitionVersion: version,
+		Status:            SagaStatusPending,
+		Payload:           payload,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}, nil
+}
+
+// NewSubSaga creates a pending saga started by a step of another saga
+// (factory function)
+func NewSubSaga(sagaType string, version int, payload json.RawMessage, parentSagaID, parentStep string) (*Saga, error) {
+	saga, err := NewSaga(sagaType, version, payload)
+	if err != nil {
+		return nil, err
+	}
+	saga.ParentSagaID = parentSagaID
+	saga.ParentStep = parentStep
+	return saga, nil
+}
+
+// Start moves a pending saga into execution
+func (s *Saga) Start() {
+	s.Status = SagaStatusExecuting
+	s.UpdatedAt = time.Now()
+}
+
+// Complete marks the saga as successfully finished
+func (s *Saga) Complete() {
+	now := time.Now()
+	s.Status = SagaStatusCompleted
+	s.ErrorMessage = ""
+	s.UpdatedAt = now
+	s.CompletedAt = &now
+}
+
+// StartCompensation records why the saga failed and begins rolling back
+func (s *Saga) StartCompensation(reason string) {
+	s.Status = SagaStatusCompensating
+	s.ErrorMessage = reason
+	s.UpdatedAt = time.Now()
+}
+
+// StartForwardRecovery records why a step past the pivot failed. The saga
+// keeps running forward.
+func (s *Saga) StartForwardRecovery(reason string) {
+	s.Status = SagaStatusForwardRecovering
+	s.ErrorMessage = reason
+	s.UpdatedAt = time.Now()
+}
+
+// Compensated marks the saga as fully rolled back
+func (s *Saga) Compensated() {
+	now := time.Now()
+	s.Status = SagaStatusCompensated
+	s.UpdatedAt = now
+	s.CompletedAt = &now
+}
+
+// Fail marks the saga as stuck: a compensation could not be applied
+func (s *Saga) Fail(reason string) {
+	now := time.Now()
+	s.Status = SagaStatusFailed
+	s.ErrorMessage = reason
+	s.UpdatedAt = now
+	s.CompletedAt = &now
+}
+
+// GroupsDone reports whether the forward phase of every step of the groups
+// up to group is over
+func (s *Saga) GroupsDone(group int) bool {
+	// The steps are materialized when the saga is first run
+	if len(s.Steps) == 0 {
+		return false
+	}
+	for _, step := range s.Steps {
+		if step.Group <= group && !step.IsDone() {
+			return false
+		}
+	}
+	return true
+}
+
+// ResumeCompensation hands a FAILED saga back to the executor after an
+// operator resolved its failed compensation
+func (s *Saga) ResumeCompensation() {
+	s.Status = SagaStatusCompensating
+	s.UpdatedAt = time.Now()
+	s.CompletedAt = nil
+}
+
+// Revert compensates a COMPLETED saga, such as a sub-saga whose parent is
+// being compensated
+func (s *Saga) Revert(reason string) {
+	s.Status = SagaStatusCompensating
+	s.ErrorMessage = reason
+	s.UpdatedAt = time.Now()
+	s.CompletedAt = nil
+}
+
+// Upgrade moves an in-flight saga to a later version of its definition, with
+// its payload migrated and its steps laid out as that version declares them
+func (s *Saga) Upgrade(version int, payload json.RawMessage, steps []*SagaStep) {
+	s.DefinitionVersion = version
+	s.Payload = payload
+	s.Steps = steps
+	s.UpdatedAt = time.Now()
+}
+
+// FailedCompensations returns the steps whose compensation failed
+func (s *Saga) FailedCompensations() []*SagaStep {
+	var steps []*SagaStep
+	for _, step := range s.Steps {
+		if step.Status == StepStatusCompensationFailed {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// Step looks up a step by name
+func (s *Saga) Step(name string) (*SagaStep, bool) {
+	for _, step := range s.Steps {
+		if step.Name == name {
+			return step, true
+		}
+	}
+	return nil, false
+}
+
+// IsCancelRequested reports whether the saga was cancelled through the API
+func (s *Saga) IsCancelRequested() bool {
+	return s.CancelRequestedAt != nil
+}
+
+// IsSubSaga reports whether the saga was started by a step of another saga
+func (s *Saga) IsSubSaga() bool {
+	return s.ParentSagaID != ""
+}
+
+// IsTerminal reports whether the saga will not make any further progress
+func (s *Saga) IsTerminal() bool {
+	switch s.Status {
+	case SagaStatusCompleted, SagaStatusCompensated, SagaStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
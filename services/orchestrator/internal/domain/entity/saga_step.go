@@ -0,0 +1,199 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StepStatus represents the state of a single saga step
+type StepStatus string
+
+const (
+	StepStatusPending            StepStatus = "PENDING"
+	StepStatusExecuting          StepStatus = "EXECUTING"
+	StepStatusSucceeded          StepStatus = "SUCCEEDED"
+	StepStatusSkipped            StepStatus = "SKIPPED"
+	StepStatusFailed             StepStatus = "FAILED"
+	StepStatusCompensating       StepStatus = "COMPENSATING"
+	StepStatusCompensated        StepStatus = "COMPENSATED"
+	StepStatusCompensationFailed StepStatus = "COMPENSATION_FAILED"
+	// The command of an asynchronous step was accepted, the step waits for
+	// its completion
+	StepStatusWaiting StepStatus = "WAITING"
+	// The completion of an asynchronous step did not arrive in time. Its
+	// command may still take effect, so the step is compensated.
+	StepStatusTimedOut StepStatus = "TIMED_OUT"
+)
+
+// SagaStep is one call to a downstream service inside a saga.
+// IdempotencyKey is generated once and re-sent on every retry so the
+// downstream service can deduplicate.
+type SagaStep struct {
+	ID              string
+	SagaID          string
+	Name            string
+	Order           int
+	Group           int
+	Status          StepStatus
+	IdempotencyKey  string
+	RequestPayload  json.RawMessage
+	ResponsePayload json.RawMessage
+	ErrorMessage    string
+	ExecutedAt      *time.Time
+	CompensatedAt   *time.Time
+	RetryCount      int
+	// NextRetryAt is when the failed call of the current phase (forward or
+	// compensation) is sent again; nil when no retry is scheduled
+	NextRetryAt *time.Time
+	// WaitUntil is when a WAITING step times out
+	WaitUntil *time.Time
+}
+
+// NewSagaStep creates a pending step (factory function).
+// Order is the position of the step in its definition, steps sharing a Group
+// run concurrently.
+func NewSagaStep(sagaID, name string, order, group int) *SagaStep {
+	return &SagaStep{
+		ID:             uuid.New().String(),
+		SagaID:         sagaID,
+		Name:           name,
+		Order:          order,
+		Group:          group,
+		Status:         StepStatusPending,
+		IdempotencyKey: uuid.New().String(),
+	}
+}
+
+// CompensationKey is the idempotency key used for the compensating call.
+// It must differ from IdempotencyKey (downstream services keep one idempotency
+// table per service) but stay stable across retries, so it is derived from it.
+func (s *SagaStep) CompensationKey() string {
+	return uuid.NewSHA1(uuid.MustParse(s.IdempotencyKey), []byte("compensate")).String()
+}
+
+// Start marks the step as in flight with the request that is about to be sent.
+// It is called again for every retry.
+func (s *SagaStep) Start(request json.RawMessage) {
+	now := time.Now()
+	s.Status = StepStatusExecuting
+	s.RequestPayload = request
+	s.ExecutedAt = &now
+	s.NextRetryAt = nil
+	s.WaitUntil = nil
+}
+
+// Wait records the accepted command of an asynchronous step, which then
+// waits for its completion until the given time
+func (s *SagaStep) Wait(response json.RawMessage, until time.Time) {
+	s.Status = StepStatusWaiting
+	s.ResponsePayload = response
+	s.WaitUntil = &until
+	s.NextRetryAt = nil
+}
+
+// WaitExpired reports whether a WAITING step timed out
+func (s *SagaStep) WaitExpired() bool {
+	return s.WaitUntil != nil && !time.Now().Before(*s.WaitUntil)
+}
+
+// TimeOut gives up waiting for the completion of an asynchronous step
+func (s *SagaStep) TimeOut(reason string) {
+	s.Status = StepStatusTimedOut
+	s.ErrorMessage = reason
+	s.NextRetryAt = nil
+	s.WaitUntil = nil
+}
+
+// Abandon gives up a step waiting for a retry or for its completion when
+// the saga is compensated. A step whose command was accepted is timed out,
+// so that it is compensated.
+func (s *SagaStep) Abandon(reason string) {
+	if s.Status == StepStatusWaiting {
+		s.TimeOut(reason)
+		return
+	}
+	s.Fail(reason)
+}
+
+// Succeed stores the downstream response
+func (s *SagaStep) Succeed(response json.RawMessage) {
+	s.Status = StepStatusSucceeded
+	s.ResponsePayload = response
+	s.ErrorMessage = ""
+	s.NextRetryAt = nil
+	s.WaitUntil = nil
+}
+
+// Skip marks a step whose condition did not hold. It is never called nor
+// compensated.
+func (s *SagaStep) Skip() {
+	s.Status = StepStatusSkipped
+}
+
+// IsDone reports whether the forward phase of the step is over
+func (s *SagaStep) IsDone() bool {
+	return s.Status == StepStatusSucceeded || s.Status == StepStatusSkipped
+}
+
+// Fail records the error returned by the forward call
+func (s *SagaStep) Fail(reason string) {
+	s.Status = StepStatusFailed
+	s.ErrorMessage = reason
+	s.NextRetryAt = nil
+	s.WaitUntil = nil
+}
+
+// ScheduleRetry records a failed call that is sent again at the given time.
+// The status is left as is, the same request is re-sent under the same key.
+func (s *SagaStep) ScheduleRetry(reason string, at time.Time) {
+	s.RetryCount++
+	s.ErrorMessage = reason
+	s.NextRetryAt = &at
+}
+
+// NeedsCompensation reports whether the forward phase of the step may have
+// taken effect downstream
+func (s *SagaStep) NeedsCompensation() bool {
+	return s.Status == StepStatusSucceeded || s.Status == StepStatusTimedOut || s.Status == StepStatusCompensating
+}
+
+// StartCompensation marks the compensating call as in flight. It is called
+// again for every retry; the compensation gets its own retry budget.
+func (s *SagaStep) StartCompensation() {
+	if s.Status != StepStatusCompensating {
+		s.RetryCount = 0
+	}
+	s.Status = StepStatusCompensating
+	s.NextRetryAt = nil
+}
+
+// RetryCompensation sends a failed compensation again with a fresh retry
+// budget
+func (s *SagaStep) RetryCompensation() {
+	s.Status = StepStatusCompensating
+	s.RetryCount = 0
+	s.NextRetryAt = nil
+}
+
+// RetryPending reports whether the step waits for a scheduled retry
+func (s *SagaStep) RetryPending() bool {
+	return s.NextRetryAt != nil && time.Now().Before(*s.NextRetryAt)
+}
+
+// Compensated marks the step as rolled back
+func (s *SagaStep) Compensated() {
+	now := time.Now()
+	s.Status = StepStatusCompensated
+	s.CompensatedAt = &now
+	s.ErrorMessage = ""
+	s.NextRetryAt = nil
+}
+
+// CompensationFailed records the error returned by the compensating call
+func (s *SagaStep) CompensationFailed(reason string) {
+	s.Status = StepStatusCompensationFailed
+	s.ErrorMessage = reason
+	s.NextRetryAt = nil
+}
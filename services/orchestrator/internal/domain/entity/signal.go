@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+)
+
+// Signal is data an external system pushed into a running saga, such as the
+// decision of a fraud review desk. Signals are kept in the order they were
+// received; each one is consumed by at most one step waiting for its name.
+// The idempotency key deduplicates deliveries of the same signal.
+type Signal struct {
+	SagaID         string
+	Name           string
+	IdempotencyKey string
+	Payload        json.RawMessage
+	ReceivedAt     time.Time
+	// ConsumedBy is the step the signal became the output of
+	ConsumedBy string
+	ConsumedAt *time.Time
+}
+
+// NewSignal creates a signal (factory function)
+func NewSignal(sagaID, name, idempotencyKey string, payload json.RawMessage) (*Signal, error) {
+	if sagaID == "" || name == "" {
+		return nil, pErrors.E(pErrors.Invalid, "saga id and signal name are required", nil)
+	}
+	if idempotencyKey == "" {
+		return nil, pErrors.E(pErrors.Invalid, "idempotency key is required", nil)
+	}
+	if len(payload) > 0 {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &object); err != nil || object == nil {
+			return nil, pErrors.E(pErrors.Invalid, "signal payload must be a JSON object", err)
+		}
+	}
+
+	return &Signal{
+		SagaID:         sagaID,
+		Name:           name,
+		IdempotencyKey: idempotencyKey,
+		Payload:        payload,
+		ReceivedAt:     time.Now(),
+	}, nil
+}
+
+// Matches reports whether another delivery under the same idempotency key
+// carries the same signal
+func (s *Signal) Matches(other *Signal) bool {
+	return s.Name == other.Name && reflect.DeepEqual(jsonValue(s.Payload), jsonValue(other.Payload))
+}
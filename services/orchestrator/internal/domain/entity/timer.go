@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimerKind is what a timer does when it fires
+type TimerKind string
+
+const (
+	// TimerKindStepRetry resumes a saga parked until a failed call is re-sent
+	TimerKindStepRetry TimerKind = "STEP_RETRY"
+	// TimerKindStepWait resumes a saga whose asynchronous step timed out, or
+	// received its completion
+	TimerKindStepWait TimerKind = "STEP_WAIT"
+	// TimerKindStepDeadline aborts the saga when the step has not succeeded yet
+	TimerKindStepDeadline TimerKind = "STEP_DEADLINE"
+	// TimerKindSagaDeadline aborts the saga when it is still running
+	TimerKindSagaDeadline TimerKind = "SAGA_DEADLINE"
+)
+
+// Timer wakes a saga up at FireAt. Timers are stored in the database, so they
+// survive restarts, and each one fires exactly once across replicas.
+// StepID is empty for timers of the whole saga.
+type Timer struct {
+	ID      string
+	SagaID  string
+	StepID  string
+	Kind    TimerKind
+	FireAt  time.Time
+	FiredAt *time.Time
+}
+
+// NewSagaTimer creates a timer of the whole saga (factory function)
+func NewSagaTimer(sagaID string, kind TimerKind, fireAt time.Time) *Timer {
+	return &Timer{
+		ID:     uuid.New().String(),
+		SagaID: sagaID,
+		Kind:   kind,
+		FireAt: fireAt,
+	}
+}
+
+// NewStepTimer creates a timer of one step (factory function)
+func NewStepTimer(step *SagaStep, kind TimerKind, fireAt time.Time) *Timer {
+	return &Timer{
+		ID:     uuid.New().String(),
+		SagaID: step.SagaID,
+		StepID: step.ID,
+		Kind:   kind,
+		FireAt: fireAt,
+	}
+}
+
+// Rearmable reports whether scheduling the timer again moves it. Retries and
+// waits are rescheduled after every call, deadlines keep their first schedule.
+func (t *Timer) Rearmable() bool {
+	return t.Kind == TimerKindStepRetry || t.Kind == TimerKindStepWait
+}
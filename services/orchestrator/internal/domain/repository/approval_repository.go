@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+// ApprovalFilter selects pending approvals, oldest request first. An empty
+// type matches all sagas.
+type ApprovalFilter struct {
+	SagaType string
+	Limit    int
+}
+
+// ApprovalRepository stores the approvals requested by approval steps
+type ApprovalRepository interface {
+	// Request stores a PENDING approval unless the step already requested one
+	Request(ctx context.Context, approval *entity.Approval) error
+	// Find returns the approval of a step, nil when none was requested
+	Find(ctx context.Context, sagaID, stepName string) (*entity.Approval, error)
+	// Decide stores the decision on a PENDING approval and wakes the saga
+	// up. It fails with a Conflict error when the approval was already
+	// decided or expired.
+	Decide(ctx context.Context, approval *entity.Approval) error
+	// Expire rejects a PENDING approval nobody decided on in time. It
+	// reports false when a decision was made meanwhile.
+	Expire(ctx context.Context, sagaID, stepName string) (bool, error)
+	// ListPending returns the approvals whose step still waits for a
+	// decision, with the saga under review
+	ListPending(ctx context.Context, filter ApprovalFilter) ([]*entity.Approval, error)
+}
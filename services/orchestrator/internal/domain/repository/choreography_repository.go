@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+// ChoreographyRepository stores the observed progress of choreographed sagas
+type ChoreographyRepository interface {
+	// AddEvent records an observed event, creating the run on its first
+	// event. It reports false when the event type was already recorded.
+	AddEvent(ctx context.Context, sagaType string, event *entity.ChoreographyEvent) (bool, error)
+	// FindByCorrelationID returns a run with its events, oldest first
+	FindByCorrelationID(ctx context.Context, correlationID string) (*entity.Choreography, error)
+	// Update writes the status of a run. It fails with Conflict when the run
+	// changed since it was read.
+	Update(ctx context.Context, choreography *entity.Choreography) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+// CompletionRepository stores the completions of asynchronous steps
+type CompletionRepository interface {
+	// Record stores a completion unless one was already received for the
+	// step, and wakes the saga up when the step waits for it. It reports
+	// false for a duplicate.
+	Record(ctx context.Context, completion *entity.StepCompletion) (bool, error)
+	// Find returns the completion of a step, nil when none was received
+	Find(ctx context.Context, sagaID, stepName string) (*entity.StepCompletion, error)
+	// Discard removes the completion of a step whose command is sent again
+	Discard(ctx context.Context, sagaID, stepName string) error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+// EventRepository reads the append-only event log of sagas. Events are
+// appended by the other repositories, in the transaction of the change they
+// record.
+type EventRepository interface {
+	// FindBySaga returns the timeline of a saga, oldest first
+	FindBySaga(ctx context.Context, sagaID string) ([]*entity.SagaEvent, error)
+	// FindSagaIDs returns up to limit ids of sagas that have events, in id
+	// order, starting after afterID
+	FindSagaIDs(ctx context.Context, afterID string, limit int) ([]string, error)
+}
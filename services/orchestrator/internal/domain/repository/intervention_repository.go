@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+// InterventionRepository keeps the audit trail of operator actions
+type InterventionRepository interface {
+	// Record applies an operator action: it writes the saga and the changed
+	// steps and appends the intervention in one transaction. The write is
+	// fenced like the writes of the executor.
+	Record(ctx context.Context, lease *entity.Lease, saga *entity.Saga, steps []*entity.SagaStep, intervention *entity.Intervention) error
+	// FindBySaga returns the interventions on a saga, oldest first
+	FindBySaga(ctx context.Context, sagaID string) ([]*entity.Intervention, error)
+}
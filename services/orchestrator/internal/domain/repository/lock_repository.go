@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+type LockRepository interface {
+	// Acquire claims the saga lease, stealing it if the previous one expired.
+	// It returns nil when another owner holds a valid lease.
+	Acquire(ctx context.Context, sagaID, ownerID string, ttl time.Duration) (*entity.Lease, error)
+	// Renew extends the lease, failing if it has been lost to another owner
+	Renew(ctx context.Context, lease *entity.Lease, ttl time.Duration) error
+	Release(ctx context.Context, lease *entity.Lease) error
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+// SagaFilter selects a page of sagas, newest first. Empty fields match all
+// sagas; the page starts after the saga identified by AfterCreatedAt/AfterID.
+type SagaFilter struct {
+	Type           string
+	Status         string
+	Limit          int
+	AfterCreatedAt *time.Time
+	AfterID        string
+}
+
+// VersionUsage counts the sagas that may still run against one version of a
+// definition: those not finished, FAILED ones that an operator can resume,
+// and the completed sub-sagas such a saga may still compensate
+type VersionUsage struct {
+	SagaType string
+	Version  int
+	Sagas    int
+}
+
+type SagaRepository interface {
+	// Create stores a PENDING saga together with its idempotency key.
+	// It fails with a Conflict error when the key is already taken, or when
+	// the parent step of a sub-saga already started one.
+	Create(ctx context.Context, saga *entity.Saga, idempotencyKey string) (*entity.Saga, error)
+	CheckIdempotency(ctx context.Context, key string) (*entity.Saga, error)
+	// List returns sagas without their steps
+	List(ctx context.Context, filter SagaFilter) ([]*entity.Saga, error)
+	// RequestCancel flags a saga that is not finished yet for cancellation.
+	// It fails with a Conflict error naming the status of a saga that is
+	// finished, FAILED or past its pivot.
+	RequestCancel(ctx context.Context, sagaID string, reason string) error
+	// FindCancelRequest reports whether cancellation of the saga was requested
+	FindCancelRequest(ctx context.Context, sagaID string) (reason string, requested bool, err error)
+
+	// FindPending returns up to limit PENDING sagas whose lease is free or expired
+	FindPending(ctx context.Context, limit int) ([]string, error)
+	// FindStuck returns up to limit EXECUTING or COMPENSATING sagas whose
+	// lease is free or expired, i.e. whose owner crashed or gave up
+	FindStuck(ctx context.Context, limit int) ([]string, error)
+	FindByID(ctx context.Context, id string) (*entity.Saga, error)
+	// FindChild returns the sub-saga started by a step, nil when none was
+	FindChild(ctx context.Context, parentSagaID, stepName string) (*entity.Saga, error)
+	// VersionsInUse returns the definition versions sagas still run against
+	VersionsInUse(ctx context.Context) ([]VersionUsage, error)
+
+	// Writes are fenced: they fail with a Conflict error when lease is no
+	// longer the current lease of the saga
+	CreateSteps(ctx context.Context, lease *entity.Lease, steps []*entity.SagaStep) error
+	UpdateSaga(ctx context.Context, lease *entity.Lease, saga *entity.Saga) error
+	UpdateStep(ctx context.Context, lease *entity.Lease, step *entity.SagaStep) error
+	// Upgrade stores a saga migrated from fromVersion to a later definition
+	// version: its version, payload and steps. Steps it no longer has are
+	// deleted.
+	Upgrade(ctx context.Context, lease *entity.Lease, saga *entity.Saga, fromVersion int) error
+	// Restore overwrites the rows of a saga and its steps with the state
+	// rebuilt from its events, without appending any. It fails with a
+	// Conflict error when events were appended after sequence.
+	Restore(ctx context.Context, lease *entity.Lease, saga *entity.Saga, sequence int64) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+// SignalRepository stores the signals pushed into sagas
+type SignalRepository interface {
+	// Record stores a signal unless its idempotency key was already used for
+	// the saga, and wakes up the steps of the saga waiting for a signal. It
+	// reports false for a duplicate.
+	Record(ctx context.Context, signal *entity.Signal) (bool, error)
+	// FindByKey returns the signal recorded under an idempotency key, nil
+	// when none was
+	FindByKey(ctx context.Context, sagaID, idempotencyKey string) (*entity.Signal, error)
+	// Claim returns the signal consumed by a step, consuming the oldest
+	// unconsumed signal of the given name the first time. It returns nil
+	// when no signal is available.
+	Claim(ctx context.Context, sagaID, name, stepName string) (*entity.Signal, error)
+	// Release lets go of the signal consumed by a step that waits again. The
+	// signal stays consumed, the step claims the next one.
+	Release(ctx context.Context, sagaID, stepName string) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+)
+
+type TimerRepository interface {
+	// Schedule stores the timer. A timer of the same saga, step and kind is
+	// moved to the new time when rearmable and kept as is otherwise.
+	Schedule(ctx context.Context, timer *entity.Timer) error
+	// FireDue marks up to limit due timers as fired and returns them. Each
+	// timer is returned to exactly one caller across replicas. A deadline
+	// timer requests cancellation of its saga in the same transaction, so a
+	// deadline is never lost between firing and handling.
+	FireDue(ctx context.Context, limit int) ([]*entity.Timer, error)
+	// DeleteBySaga removes the timers of a saga that finished
+	DeleteBySaga(ctx context.Context, sagaID string) error
+}
@@ -0,0 +1,53 @@
+package choreography
+
+import (
+	"context"
+	"errors"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/messaging"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+)
+
+// Group is the consumer group of the orchestrator. Every replica shares it,
+// so that each event is observed once.
+const Group = "orchestrator-observer"
+
+type ChoreographyObserver interface {
+	Execute(ctx context.Context, req dto.ChoreographyEventRequest) error
+}
+
+// Observer feeds the domain events of choreographed sagas to the
+// orchestrator, which only tracks their progress
+type Observer struct {
+	observeUC ChoreographyObserver
+}
+
+func NewObserver(observeUC ChoreographyObserver) *Observer {
+	return &Observer{observeUC: observeUC}
+}
+
+// Register subscribes the observer to the events of a choreography
+func (o *Observer) Register(s messaging.Consumer, events []string) {
+	for _, event := range events {
+		s.Subscribe(event, Group, o.observe)
+	}
+}
+
+func (o *Observer) observe(ctx context.Context, msg *messaging.Message) error {
+	// The events are keyed by the id of the run they belong to
+	err := o.observeUC.Execute(ctx, dto.ChoreographyEventRequest{
+		CorrelationID: msg.Key,
+		Type:          msg.Topic,
+		MessageID:     msg.ID,
+		Payload:       msg.Payload,
+		PublishedAt:   msg.PublishedAt,
+	})
+
+	// A concurrent update is retried, the status is derived again
+	var pErr *pErrors.Error
+	if errors.As(err, &pErr) && pErr.Code == pErrors.Conflict {
+		return err
+	}
+	return messaging.RejectPermanent(err)
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"encoding/json"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Step requests and responses are persisted as JSON in saga_steps, so every
+// client speaks JSON on the orchestrator side and protobuf on the wire.
+// Proto field names (snake_case) are used to match the saga payloads.
+var (
+	marshaler   = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+	unmarshaler = protojson.UnmarshalOptions{}
+)
+
+func dial(addr string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to dial "+addr, err)
+	}
+	return conn, nil
+}
+
+func decode(request json.RawMessage, msg proto.Message) error {
+	if err := unmarshaler.Unmarshal(request, msg); err != nil {
+		return pErrors.E(pErrors.Invalid, "invalid request payload", err)
+	}
+	return nil
+}
+
+func encode(msg proto.Message) (json.RawMessage, error) {
+	b, err := marshaler.Marshal(msg)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to encode response", err)
+	}
+	return b, nil
+}
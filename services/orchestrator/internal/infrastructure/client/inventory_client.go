@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	pb "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/gen/proto/inventory/v1"
+	"google.golang.org/grpc"
+)
+
+type InventoryClient struct {
+	conn   *grpc.ClientConn
+	client pb.InventoryServiceClient
+}
+
+func NewInventoryClient(addr string) (*InventoryClient, error) {
+	conn, err := dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &InventoryClient{conn: conn, client: pb.NewInventoryServiceClient(conn)}, nil
+}
+
+func (c *InventoryClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *InventoryClient) ReserveInventory(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	req := &pb.ReserveInventoryRequest{}
+	if err := decode(request, req); err != nil {
+		return nil, err
+	}
+	req.IdempotencyKey = idempotencyKey
+
+	resp, err := c.client.ReserveInventory(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return encode(resp)
+}
+
+func (c *InventoryClient) ReleaseInventory(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	req := &pb.ReleaseInventoryRequest{}
+	if err := decode(request, req); err != nil {
+		return nil, err
+	}
+	req.IdempotencyKey = idempotencyKey
+
+	resp, err := c.client.ReleaseInventory(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return encode(resp)
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	pb "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/gen/proto/order/v1"
+	"google.golang.org/grpc"
+)
+
+type OrderClient struct {
+	conn   *grpc.ClientConn
+	client pb.OrderServiceClient
+}
+
+func NewOrderClient(addr string) (*OrderClient, error) {
+	conn, err := dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderClient{conn: conn, client: pb.NewOrderServiceClient(conn)}, nil
+}
+
+func (c *OrderClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *OrderClient) CreateOrder(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	req := &pb.CreateOrderRequest{}
+	if err := decode(request, req); err != nil {
+		return nil, err
+	}
+	req.IdempotencyKey = idempotencyKey
+
+	resp, err := c.client.CreateOrder(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return encode(resp)
+}
+
+func (c *OrderClient) CancelOrder(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	req := &pb.CancelOrderRequest{}
+	if err := decode(request, req); err != nil {
+		return nil, err
+	}
+	req.IdempotencyKey = idempotencyKey
+
+	resp, err := c.client.CancelOrder(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return encode(resp)
+}
+
+func (c *OrderClient) ConfirmOrder(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	req := &pb.ConfirmOrderRequest{}
+	if err := decode(request, req); err != nil {
+		return nil, err
+	}
+	req.IdempotencyKey = idempotencyKey
+
+	resp, err := c.client.ConfirmOrder(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return encode(resp)
+}
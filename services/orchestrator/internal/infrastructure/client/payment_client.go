@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	pb "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/gen/proto/payment/v1"
+	"google.golang.org/grpc"
+)
+
+type PaymentClient struct {
+	conn   *grpc.ClientConn
+	client pb.PaymentServiceClient
+}
+
+func NewPaymentClient(addr string) (*PaymentClient, error) {
+	conn, err := dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentClient{conn: conn, client: pb.NewPaymentServiceClient(conn)}, nil
+}
+
+func (c *PaymentClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *PaymentClient) ProcessPayment(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	req := &pb.ProcessPaymentRequest{}
+	if err := decode(request, req); err != nil {
+		return nil, err
+	}
+	req.IdempotencyKey = idempotencyKey
+
+	resp, err := c.client.ProcessPayment(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return encode(resp)
+}
+
+func (c *PaymentClient) RefundPayment(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	req := &pb.RefundPaymentRequest{}
+	if err := decode(request, req); err != nil {
+		return nil, err
+	}
+	req.IdempotencyKey = idempotencyKey
+
+	resp, err := c.client.RefundPayment(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return encode(resp)
+}
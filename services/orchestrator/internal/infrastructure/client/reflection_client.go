@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"google.golang.org/grpc"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// idempotencyKeyField is the request field the idempotency key of a step is
+// forwarded in, as in the generated clients
+const idempotencyKeyField = "idempotency_key"
+
+// ReflectionClient calls methods of downstream services that are not known
+// at compile time, such as the ones named in saga definition files. Their
+// descriptors are fetched with gRPC server reflection, so the services must
+// enable it (REFLECTION=true).
+type ReflectionClient struct {
+	mu       sync.Mutex
+	conns    map[string]*grpc.ClientConn
+	services map[string]protoreflect.ServiceDescriptor
+}
+
+func NewReflectionClient() *ReflectionClient {
+	return &ReflectionClient{
+		conns:    make(map[string]*grpc.ClientConn),
+		services: make(map[string]protoreflect.ServiceDescriptor),
+	}
+}
+
+func (c *ReflectionClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for _, conn := range c.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	c.conns = make(map[string]*grpc.ClientConn)
+	return errors.Join(errs...)
+}
+
+// Method resolves a method, named package.Service/Method, of the service
+// listening on addr
+func (c *ReflectionClient) Method(ctx context.Context, addr, name string) (*DynamicMethod, error) {
+	serviceName, methodName, ok := strings.Cut(name, "/")
+	if !ok || serviceName == "" || methodName == "" {
+		return nil, pErrors.E(pErrors.Invalid, "method must be named package.Service/Method: "+name, nil)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conn, ok := c.conns[addr]
+	if !ok {
+		var err error
+		if conn, err = dial(addr); err != nil {
+			return nil, err
+		}
+		c.conns[addr] = conn
+	}
+
+	key := addr + "/" + serviceName
+	service, ok := c.services[key]
+	if !ok {
+		var err error
+		if service, err = describe(ctx, conn, serviceName); err != nil {
+			return nil, err
+		}
+		c.services[key] = service
+	}
+
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, pErrors.E(pErrors.NotFound, fmt.Sprintf("service %s has no method %s", serviceName, methodName), nil)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("method %s is streaming, only unary methods can be called", name), nil)
+	}
+	field := method.Input().Fields().ByName(idempotencyKeyField)
+	if field == nil || field.Kind() != protoreflect.StringKind || field.Cardinality() == protoreflect.Repeated {
+		return nil, pErrors.E(pErrors.Invalid, fmt.Sprintf("method %s takes no string %s, retried calls could not be deduplicated", name, idempotencyKeyField), nil)
+	}
+
+	return &DynamicMethod{conn: conn, desc: method}, nil
+}
+
+// describe fetches the descriptor of a service, with the files it depends on
+func describe(ctx context.Context, conn *grpc.ClientConn, serviceName string) (protoreflect.ServiceDescriptor, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to open reflection stream", err)
+	}
+	defer stream.CloseSend()
+
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: serviceName},
+	})
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to query reflection of "+serviceName, err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to query reflection of "+serviceName, err)
+	}
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, pErrors.E(pErrors.NotFound, fmt.Sprintf("service %s not found: %s", serviceName, e.ErrorMessage), nil)
+	}
+
+	files, err := buildFiles(resp.GetFileDescriptorResponse().GetFileDescriptorProto())
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "invalid descriptors of "+serviceName, err)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, pErrors.E(pErrors.NotFound, "service not found: "+serviceName, err)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, pErrors.E(pErrors.Invalid, serviceName+" is not a service", nil)
+	}
+	return service, nil
+}
+
+// buildFiles links serialized file descriptors. A dependency the server did
+// not send, e.g. a well-known type, is taken from the linked-in descriptors.
+func buildFiles(raw [][]byte) (*protoregistry.Files, error) {
+	protos := make(map[string]*descriptorpb.FileDescriptorProto, len(raw))
+	for _, b := range raw {
+		fdp := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(b, fdp); err != nil {
+			return nil, err
+		}
+		protos[fdp.GetName()] = fdp
+	}
+
+	files := &protoregistry.Files{}
+	var register func(path string) error
+	register = func(path string) error {
+		if _, err := files.FindFileByPath(path); err == nil {
+			return nil
+		}
+		fdp, ok := protos[path]
+		if !ok {
+			fd, err := protoregistry.GlobalFiles.FindFileByPath(path)
+			if err != nil {
+				return fmt.Errorf("missing dependency %s", path)
+			}
+			return files.RegisterFile(fd)
+		}
+		for _, dep := range fdp.GetDependency() {
+			if err := register(dep); err != nil {
+				return err
+			}
+		}
+		fd, err := protodesc.NewFile(fdp, files)
+		if err != nil {
+			return err
+		}
+		return files.RegisterFile(fd)
+	}
+
+	for path := range protos {
+		if err := register(path); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// DynamicMethod is a unary method called with JSON, like the generated
+// clients: the request is decoded into its input message and the output is
+// encoded back with proto field names
+type DynamicMethod struct {
+	conn *grpc.ClientConn
+	desc protoreflect.MethodDescriptor
+}
+
+// Input describes the request message of the method
+func (m *DynamicMethod) Input() protoreflect.MessageDescriptor {
+	return m.desc.Input()
+}
+
+// Invoke calls the method. It has the signature of a saga action.
+func (m *DynamicMethod) Invoke(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+	req := dynamicpb.NewMessage(m.desc.Input())
+	if err := decode(request, req); err != nil {
+		return nil, err
+	}
+	req.Set(m.desc.Input().Fields().ByName(idempotencyKeyField), protoreflect.ValueOfString(idempotencyKey))
+
+	resp := dynamicpb.NewMessage(m.desc.Output())
+	path := fmt.Sprintf("/%s/%s", m.desc.Parent().FullName(), m.desc.Name())
+	if err := m.conn.Invoke(ctx, path, req, resp); err != nil {
+		return nil, err
+	}
+	return encode(resp)
+}
@@ -0,0 +1,61 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/messaging"
+	eventsv1 "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/gen/proto/events/v1"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Group is the consumer group of the orchestrator
+const Group = "orchestrator"
+
+// CompletionTopic carries the completions of asynchronous steps
+const CompletionTopic = "SagaStepCompleted"
+
+type StepCompleter interface {
+	Execute(ctx context.Context, req dto.CompleteStepRequest) (*dto.CompleteStepResponse, error)
+}
+
+// CompletionHandler records the completions of asynchronous steps published
+// on the bus, the event counterpart of the CompleteStep callback
+type CompletionHandler struct {
+	completeUC StepCompleter
+}
+
+func NewCompletionHandler(completeUC StepCompleter) *CompletionHandler {
+	return &CompletionHandler{completeUC: completeUC}
+}
+
+// Register subscribes the handler to the completion topic
+func (h *CompletionHandler) Register(s messaging.Consumer) {
+	s.Subscribe(CompletionTopic, Group, h.completed)
+}
+
+func (h *CompletionHandler) completed(ctx context.Context, msg *messaging.Message) error {
+	var event eventsv1.SagaStepCompleted
+	if err := messaging.Decode(msg, &event); err != nil {
+		return err
+	}
+
+	var payload json.RawMessage
+	if event.Payload != nil {
+		b, err := protojson.Marshal(event.Payload)
+		if err != nil {
+			return messaging.Reject(err)
+		}
+		payload = b
+	}
+
+	_, err := h.completeUC.Execute(ctx, dto.CompleteStepRequest{
+		SagaID:       event.SagaId,
+		StepName:     event.StepName,
+		Payload:      payload,
+		ErrorMessage: event.ErrorMessage,
+		Source:       dto.CompletionSourceEvent,
+	})
+	return messaging.RejectPermanent(err)
+}
@@ -0,0 +1,134 @@
+package grpc
+
+import (
+	"context"
+
+	grpcPlatform "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/grpc"
+	pb "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/gen/proto/orchestrator/v1"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"google.golang.org/grpc"
+)
+
+// SagaIntervener is an operator action on a saga
+type SagaIntervener interface {
+	Execute(ctx context.Context, req dto.InterventionRequest) (*dto.SagaResponse, error)
+}
+
+// ProjectionChecker compares or repairs the stored state of a saga
+type ProjectionChecker interface {
+	Execute(ctx context.Context, sagaID string) (*dto.ProjectionResponse, error)
+}
+
+// DefinitionVersionLister reports the definition versions in use
+type DefinitionVersionLister interface {
+	Execute(ctx context.Context) (*dto.ListDefinitionVersionsResponse, error)
+}
+
+// DiagramRenderer draws a saga definition or a saga
+type DiagramRenderer interface {
+	Execute(ctx context.Context, req dto.RenderDiagramRequest) (*dto.DiagramResponse, error)
+}
+
+type AdminHandler struct {
+	pb.UnimplementedOrchestratorAdminServiceServer
+	retryCompensationUC SagaIntervener
+	markCompensatedUC   SagaIntervener
+	forceCompleteUC     SagaIntervener
+	verifyProjectionUC  ProjectionChecker
+	rebuildProjectionUC ProjectionChecker
+	listVersionsUC      DefinitionVersionLister
+	renderDiagramUC     DiagramRenderer
+}
+
+func NewAdminHandler(retryCompensationUC, markCompensatedUC, forceCompleteUC SagaIntervener, verifyProjectionUC, rebuildProjectionUC ProjectionChecker, listVersionsUC DefinitionVersionLister, renderDiagramUC DiagramRenderer) *AdminHandler {
+	return &AdminHandler{
+		retryCompensationUC: retryCompensationUC,
+		markCompensatedUC:   markCompensatedUC,
+		forceCompleteUC:     forceCompleteUC,
+		verifyProjectionUC:  verifyProjectionUC,
+		rebuildProjectionUC: rebuildProjectionUC,
+		listVersionsUC:      listVersionsUC,
+		renderDiagramUC:     renderDiagramUC,
+	}
+}
+
+func (h *AdminHandler) RegisterOrchestratorAdminServiceServer(s *grpc.Server) {
+	pb.RegisterOrchestratorAdminServiceServer(s, h)
+}
+
+func (h *AdminHandler) RetryCompensation(ctx context.Context, req *pb.InterventionRequest) (*pb.InterventionResponse, error) {
+	return intervene(ctx, h.retryCompensationUC, req)
+}
+
+func (h *AdminHandler) MarkStepCompensated(ctx context.Context, req *pb.InterventionRequest) (*pb.InterventionResponse, error) {
+	return intervene(ctx, h.markCompensatedUC, req)
+}
+
+func (h *AdminHandler) ForceCompleteSaga(ctx context.Context, req *pb.InterventionRequest) (*pb.InterventionResponse, error) {
+	return intervene(ctx, h.forceCompleteUC, req)
+}
+
+func (h *AdminHandler) VerifyProjection(ctx context.Context, req *pb.ProjectionRequest) (*pb.ProjectionResponse, error) {
+	return checkProjection(ctx, h.verifyProjectionUC, req)
+}
+
+func (h *AdminHandler) RebuildProjection(ctx context.Context, req *pb.ProjectionRequest) (*pb.ProjectionResponse, error) {
+	return checkProjection(ctx, h.rebuildProjectionUC, req)
+}
+
+func (h *AdminHandler) ListDefinitionVersions(ctx context.Context, req *pb.ListDefinitionVersionsRequest) (*pb.ListDefinitionVersionsResponse, error) {
+	result, err := h.listVersionsUC.Execute(ctx)
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+
+	versions := make([]*pb.DefinitionVersion, len(result.Versions))
+	for i, v := range result.Versions {
+		versions[i] = &pb.DefinitionVersion{
+			SagaType:   v.SagaType,
+			Version:    int32(v.Version),
+			Registered: v.Registered,
+			Latest:     v.Latest,
+			Sagas:      int32(v.Sagas),
+		}
+	}
+	return &pb.ListDefinitionVersionsResponse{Versions: versions}, nil
+}
+
+func (h *AdminHandler) RenderDiagram(ctx context.Context, req *pb.RenderDiagramRequest) (*pb.RenderDiagramResponse, error) {
+	result, err := h.renderDiagramUC.Execute(ctx, dto.RenderDiagramRequest{
+		SagaID:   req.SagaId,
+		SagaType: req.SagaType,
+		Version:  int(req.Version),
+		Format:   req.Format,
+	})
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+	return &pb.RenderDiagramResponse{Format: result.Format, Diagram: result.Diagram}, nil
+}
+
+func checkProjection(ctx context.Context, uc ProjectionChecker, req *pb.ProjectionRequest) (*pb.ProjectionResponse, error) {
+	result, err := uc.Execute(ctx, req.SagaId)
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+	return &pb.ProjectionResponse{
+		Saga:        toProtoSaga(result.Saga),
+		Differences: result.Differences,
+		Rebuilt:     result.Rebuilt,
+	}, nil
+}
+
+func intervene(ctx context.Context, uc SagaIntervener, req *pb.InterventionRequest) (*pb.InterventionResponse, error) {
+	result, err := uc.Execute(ctx, dto.InterventionRequest{
+		SagaID:     req.SagaId,
+		StepName:   req.StepName,
+		OperatorID: req.OperatorId,
+		Note:       req.Note,
+	})
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+	return &pb.InterventionResponse{Saga: toProtoSaga(result)}, nil
+}
@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"context"
+
+	grpcPlatform "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/grpc"
+	pb "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/gen/proto/orchestrator/v1"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"google.golang.org/grpc"
+)
+
+// ApprovalLister returns the work queue of approvers
+type ApprovalLister interface {
+	Execute(ctx context.Context, req dto.ListApprovalsRequest) (*dto.ListApprovalsResponse, error)
+}
+
+// ApprovalDecider approves or rejects an approval step
+type ApprovalDecider interface {
+	Execute(ctx context.Context, req dto.DecideApprovalRequest) (*dto.ApprovalDTO, error)
+}
+
+type ApprovalHandler struct {
+	pb.UnimplementedOrchestratorApprovalServiceServer
+	listUC   ApprovalLister
+	decideUC ApprovalDecider
+}
+
+func NewApprovalHandler(listUC ApprovalLister, decideUC ApprovalDecider) *ApprovalHandler {
+	return &ApprovalHandler{listUC: listUC, decideUC: decideUC}
+}
+
+func (h *ApprovalHandler) RegisterOrchestratorApprovalServiceServer(s *grpc.Server) {
+	pb.RegisterOrchestratorApprovalServiceServer(s, h)
+}
+
+func (h *ApprovalHandler) ListPendingApprovals(ctx context.Context, req *pb.ListPendingApprovalsRequest) (*pb.ListPendingApprovalsResponse, error) {
+	result, err := h.listUC.Execute(ctx, dto.ListApprovalsRequest{
+		SagaType: req.SagaType,
+		PageSize: int(req.PageSize),
+	})
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+
+	approvals := make([]*pb.Approval, len(result.Approvals))
+	for i := range result.Approvals {
+		approvals[i] = toProtoApproval(&result.Approvals[i])
+	}
+	return &pb.ListPendingApprovalsResponse{Approvals: approvals}, nil
+}
+
+func (h *ApprovalHandler) DecideApproval(ctx context.Context, req *pb.DecideApprovalRequest) (*pb.DecideApprovalResponse, error) {
+	result, err := h.decideUC.Execute(ctx, dto.DecideApprovalRequest{
+		SagaID:     req.SagaId,
+		StepName:   req.StepName,
+		Approved:   req.Approved,
+		ApproverID: req.ApproverId,
+		Comment:    req.Comment,
+	})
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+	return &pb.DecideApprovalResponse{Approval: toProtoApproval(result)}, nil
+}
+
+func toProtoApproval(approval *dto.ApprovalDTO) *pb.Approval {
+	return &pb.Approval{
+		SagaId:      approval.SagaID,
+		StepName:    approval.StepName,
+		SagaType:    approval.SagaType,
+		SagaPayload: toStruct(approval.SagaPayload),
+		Status:      approval.Status,
+		ApproverId:  approval.ApproverID,
+		Comment:     approval.Comment,
+		RequestedAt: formatTime(&approval.RequestedAt),
+		ExpiresAt:   formatTime(&approval.ExpiresAt),
+		DecidedAt:   formatTime(approval.DecidedAt),
+	}
+}
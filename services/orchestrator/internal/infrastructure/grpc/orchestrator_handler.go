@@ -0,0 +1,313 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	grpcPlatform "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/grpc"
+	pb "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/gen/proto/orchestrator/v1"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type SagaStarter interface {
+	Execute(ctx context.Context, req dto.StartSagaRequest) (*dto.SagaResponse, error)
+}
+type SagaGetter interface {
+	Execute(ctx context.Context, sagaID string) (*dto.SagaResponse, error)
+}
+type SagaLister interface {
+	Execute(ctx context.Context, req dto.ListSagasRequest) (*dto.ListSagasResponse, error)
+}
+type SagaCanceller interface {
+	Execute(ctx context.Context, sagaID string, reason string) (*dto.SagaResponse, error)
+}
+type SagaTimelineGetter interface {
+	Execute(ctx context.Context, sagaID string) (*dto.SagaTimelineResponse, error)
+}
+type ChoreographyGetter interface {
+	Execute(ctx context.Context, correlationID string) (*dto.ChoreographyResponse, error)
+}
+type StepCompleter interface {
+	Execute(ctx context.Context, req dto.CompleteStepRequest) (*dto.CompleteStepResponse, error)
+}
+type SagaSignaller interface {
+	Execute(ctx context.Context, req dto.SignalSagaRequest) (*dto.SignalSagaResponse, error)
+}
+
+type OrchestratorHandler struct {
+	pb.UnimplementedOrchestratorServiceServer
+	startUC        SagaStarter
+	getUC          SagaGetter
+	listUC         SagaLister
+	cancelUC       SagaCanceller
+	timelineUC     SagaTimelineGetter
+	choreographyUC ChoreographyGetter
+	completeUC     StepCompleter
+	signalUC       SagaSignaller
+}
+
+func NewOrchestratorHandler(startUC SagaStarter, getUC SagaGetter, listUC SagaLister, cancelUC SagaCanceller, timelineUC SagaTimelineGetter, choreographyUC ChoreographyGetter, completeUC StepCompleter, signalUC SagaSignaller) *OrchestratorHandler {
+	return &OrchestratorHandler{
+		startUC:        startUC,
+		getUC:          getUC,
+		listUC:         listUC,
+		cancelUC:       cancelUC,
+		timelineUC:     timelineUC,
+		choreographyUC: choreographyUC,
+		completeUC:     completeUC,
+		signalUC:       signalUC,
+	}
+}
+
+func (h *OrchestratorHandler) RegisterOrchestratorServiceServer(s *grpc.Server) {
+	pb.RegisterOrchestratorServiceServer(s, h)
+}
+
+func (h *OrchestratorHandler) StartSaga(ctx context.Context, req *pb.StartSagaRequest) (*pb.StartSagaResponse, error) {
+	var payload json.RawMessage
+	if req.Payload != nil {
+		b, err := protojson.Marshal(req.Payload)
+		if err != nil {
+			return nil, grpcPlatform.ToStatus(pErrors.E(pErrors.Invalid, "invalid payload", err))
+		}
+		payload = b
+	}
+
+	result, err := h.startUC.Execute(ctx, dto.StartSagaRequest{
+		IdempotencyKey: req.IdempotencyKey,
+		SagaType:       req.SagaType,
+		Payload:        payload,
+	})
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+
+	return &pb.StartSagaResponse{
+		SagaId: result.ID,
+		Status: result.Status,
+	}, nil
+}
+
+func (h *OrchestratorHandler) GetSaga(ctx context.Context, req *pb.GetSagaRequest) (*pb.GetSagaResponse, error) {
+	result, err := h.getUC.Execute(ctx, req.SagaId)
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+	return &pb.GetSagaResponse{Saga: toProtoSaga(result)}, nil
+}
+
+func (h *OrchestratorHandler) ListSagas(ctx context.Context, req *pb.ListSagasRequest) (*pb.ListSagasResponse, error) {
+	result, err := h.listUC.Execute(ctx, dto.ListSagasRequest{
+		SagaType:  req.SagaType,
+		Status:    req.Status,
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+	})
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+
+	sagas := make([]*pb.Saga, len(result.Sagas))
+	for i := range result.Sagas {
+		sagas[i] = toProtoSaga(&result.Sagas[i])
+	}
+
+	return &pb.ListSagasResponse{
+		Sagas:         sagas,
+		NextPageToken: result.NextPageToken,
+	}, nil
+}
+
+func (h *OrchestratorHandler) CancelSaga(ctx context.Context, req *pb.CancelSagaRequest) (*pb.CancelSagaResponse, error) {
+	result, err := h.cancelUC.Execute(ctx, req.SagaId, req.Reason)
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+	return &pb.CancelSagaResponse{
+		SagaId: result.ID,
+		Status: result.Status,
+	}, nil
+}
+
+func (h *OrchestratorHandler) GetSagaTimeline(ctx context.Context, req *pb.GetSagaTimelineRequest) (*pb.GetSagaTimelineResponse, error) {
+	result, err := h.timelineUC.Execute(ctx, req.SagaId)
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+
+	events := make([]*pb.SagaEvent, len(result.Events))
+	for i, event := range result.Events {
+		events[i] = &pb.SagaEvent{
+			Sequence:  event.Sequence,
+			StepName:  event.StepName,
+			Type:      event.Type,
+			Data:      toStruct(event.Data),
+			CreatedAt: formatTime(&event.CreatedAt),
+		}
+	}
+
+	return &pb.GetSagaTimelineResponse{
+		SagaId: result.SagaID,
+		Events: events,
+	}, nil
+}
+
+func (h *OrchestratorHandler) CompleteStep(ctx context.Context, req *pb.CompleteStepRequest) (*pb.CompleteStepResponse, error) {
+	var payload json.RawMessage
+	if req.Payload != nil {
+		b, err := protojson.Marshal(req.Payload)
+		if err != nil {
+			return nil, grpcPlatform.ToStatus(pErrors.E(pErrors.Invalid, "invalid payload", err))
+		}
+		payload = b
+	}
+
+	result, err := h.completeUC.Execute(ctx, dto.CompleteStepRequest{
+		SagaID:       req.SagaId,
+		StepName:     req.StepName,
+		Payload:      payload,
+		ErrorMessage: req.ErrorMessage,
+		Source:       dto.CompletionSourceCallback,
+	})
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+
+	return &pb.CompleteStepResponse{
+		SagaId:    result.SagaID,
+		StepName:  result.StepName,
+		Duplicate: result.Duplicate,
+	}, nil
+}
+
+func (h *OrchestratorHandler) SignalSaga(ctx context.Context, req *pb.SignalSagaRequest) (*pb.SignalSagaResponse, error) {
+	var payload json.RawMessage
+	if req.Payload != nil {
+		b, err := protojson.Marshal(req.Payload)
+		if err != nil {
+			return nil, grpcPlatform.ToStatus(pErrors.E(pErrors.Invalid, "invalid payload", err))
+		}
+		payload = b
+	}
+
+	result, err := h.signalUC.Execute(ctx, dto.SignalSagaRequest{
+		SagaID:         req.SagaId,
+		SignalName:     req.SignalName,
+		IdempotencyKey: req.IdempotencyKey,
+		Payload:        payload,
+	})
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+
+	return &pb.SignalSagaResponse{
+		SagaId:     result.SagaID,
+		SignalName: result.SignalName,
+		ReceivedAt: formatTime(&result.ReceivedAt),
+		Duplicate:  result.Duplicate,
+	}, nil
+}
+
+func (h *OrchestratorHandler) GetChoreography(ctx context.Context, req *pb.GetChoreographyRequest) (*pb.GetChoreographyResponse, error) {
+	result, err := h.choreographyUC.Execute(ctx, req.CorrelationId)
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+
+	events := make([]*pb.ChoreographyEvent, len(result.Events))
+	for i, event := range result.Events {
+		events[i] = &pb.ChoreographyEvent{
+			Type:        event.Type,
+			MessageId:   event.MessageID,
+			Payload:     toStruct(event.Payload),
+			PublishedAt: formatTime(&event.PublishedAt),
+			ReceivedAt:  formatTime(&event.ReceivedAt),
+		}
+	}
+
+	return &pb.GetChoreographyResponse{
+		Choreography: &pb.Choreography{
+			CorrelationId: result.CorrelationID,
+			SagaType:      result.SagaType,
+			Status:        result.Status,
+			ErrorMessage:  result.ErrorMessage,
+			StartedAt:     formatTime(&result.StartedAt),
+			UpdatedAt:     formatTime(&result.UpdatedAt),
+			CompletedAt:   formatTime(result.CompletedAt),
+			Events:        events,
+		},
+	}, nil
+}
+
+// toProtoSaga converts DTO to protobuf
+func toProtoSaga(saga *dto.SagaResponse) *pb.Saga {
+	steps := make([]*pb.SagaStep, len(saga.Steps))
+	for i, step := range saga.Steps {
+		steps[i] = &pb.SagaStep{
+			Name:          step.Name,
+			StepOrder:     int32(step.Order),
+			StepGroup:     int32(step.Group),
+			Status:        step.Status,
+			ErrorMessage:  step.ErrorMessage,
+			RetryCount:    int32(step.RetryCount),
+			Request:       toStruct(step.Request),
+			Response:      toStruct(step.Response),
+			ExecutedAt:    formatTime(step.ExecutedAt),
+			CompensatedAt: formatTime(step.CompensatedAt),
+			WaitUntil:     formatTime(step.WaitUntil),
+		}
+	}
+
+	interventions := make([]*pb.Intervention, len(saga.Interventions))
+	for i, intervention := range saga.Interventions {
+		interventions[i] = &pb.Intervention{
+			StepName:       intervention.StepName,
+			Action:         intervention.Action,
+			OperatorId:     intervention.OperatorID,
+			Note:           intervention.Note,
+			PreviousStatus: intervention.PreviousStatus,
+			CreatedAt:      formatTime(&intervention.CreatedAt),
+		}
+	}
+
+	return &pb.Saga{
+		Id:                saga.ID,
+		SagaType:          saga.SagaType,
+		Status:            saga.Status,
+		Payload:           toStruct(saga.Payload),
+		ErrorMessage:      saga.ErrorMessage,
+		CreatedAt:         formatTime(&saga.CreatedAt),
+		UpdatedAt:         formatTime(&saga.UpdatedAt),
+		CompletedAt:       formatTime(saga.CompletedAt),
+		Steps:             steps,
+		Interventions:     interventions,
+		ParentSagaId:      saga.ParentSagaID,
+		ParentStep:        saga.ParentStep,
+		DefinitionVersion: int32(saga.DefinitionVersion),
+	}
+}
+
+// toStruct converts a JSON object to a Struct. Anything else, e.g. an empty
+// payload, is left out of the response.
+func toStruct(b json.RawMessage) *structpb.Struct {
+	if len(b) == 0 {
+		return nil
+	}
+	s := &structpb.Struct{}
+	if err := protojson.Unmarshal(b, s); err != nil {
+		return nil
+	}
+	return s
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
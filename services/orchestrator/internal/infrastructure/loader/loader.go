@@ -0,0 +1,521 @@
+package loader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/infrastructure/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads the saga definitions declared in the .yaml, .yml and .json
+// files of dir, for flows that are not written in Go. A file declares one
+// saga type:
+//
+//	type: refund_order
+//	version: 1
+//	deadline: 2m
+//	services:
+//	  orders: ${ORDER_SERVICE_ADDR}
+//	  payments: ${PAYMENT_SERVICE_ADDR}
+//	steps:
+//	  - name: cancel_order
+//	    action:
+//	      service: orders
+//	      method: order.v1.OrderService/CancelOrder
+//	      timeout: 5s
+//	      request:
+//	        order_id: $payload.order_id
+//	        reason: customer refund
+//	    retry: default
+//	  - parallel:
+//	      - name: refund_payment
+//	        action: ...
+//
+// A request is a template of the input message of its method: a string
+// starting with $ references the saga state like a definition.Ref ($$
+// escapes a literal $), and {$for_each: $payload.items, $item: {...}} maps
+// an array like definition.ForEach. The idempotency key is set by the
+// orchestrator.
+//
+// Methods are resolved through gRPC reflection and requests are checked
+// against their input messages, so the services must be reachable. Every
+// problem found is reported with its file, line and column.
+func Load(ctx context.Context, dir string, methods *client.ReflectionClient) ([]*definition.Definition, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		return nil, pErrors.E(pErrors.Invalid, "invalid saga definitions directory "+dir, err)
+	}
+	sort.Strings(paths)
+
+	var defs []*definition.Definition
+	var problems []string
+	for _, path := range paths {
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+
+		def, errs := loadFile(ctx, path, methods)
+		for _, err := range errs {
+			// file:line:column: problem
+			var specErr *specError
+			if errors.As(err, &specErr) {
+				problems = append(problems, filepath.Base(path)+":"+err.Error())
+			} else {
+				problems = append(problems, filepath.Base(path)+": "+err.Error())
+			}
+		}
+		if def != nil {
+			defs = append(defs, def)
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, pErrors.E(pErrors.Invalid, "invalid saga definition files:\n"+strings.Join(problems, "\n"), nil)
+	}
+	return defs, nil
+}
+
+// fileLoader builds the definition of one file, collecting its problems
+type fileLoader struct {
+	ctx      context.Context
+	methods  *client.ReflectionClient
+	services map[string]string
+	errs     []error
+}
+
+func (l *fileLoader) fail(pos position, format string, args ...any) {
+	l.errs = append(l.errs, &specError{pos: pos, msg: fmt.Sprintf(format, args...)})
+}
+
+func loadFile(ctx context.Context, path string, methods *client.ReflectionClient) (*definition.Definition, []error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer f.Close()
+
+	// JSON is a subset of YAML, both are read the same way
+	var spec fileSpec
+	if err := yaml.NewDecoder(f).Decode(&spec); err != nil {
+		return nil, decodeErrors(err)
+	}
+
+	l := &fileLoader{ctx: ctx, methods: methods, services: make(map[string]string)}
+	def := l.build(&spec)
+	if len(l.errs) > 0 {
+		return nil, l.errs
+	}
+	return def, nil
+}
+
+// decodeErrors turns the errors of the YAML decoder, which only know the
+// line, into specErrors where possible
+func decodeErrors(err error) []error {
+	var specErr *specError
+	if errors.As(err, &specErr) {
+		return []error{specErr}
+	}
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return []error{err}
+	}
+	errs := make([]error, len(typeErr.Errors))
+	for i, msg := range typeErr.Errors {
+		// "line 7: cannot unmarshal ..."
+		var line int
+		if _, scanErr := fmt.Sscanf(msg, "line %d:", &line); scanErr == nil {
+			_, msg, _ = strings.Cut(msg, ": ")
+			errs[i] = &specError{pos: position{line: line}, msg: msg}
+			continue
+		}
+		errs[i] = errors.New(msg)
+	}
+	return errs
+}
+
+func (l *fileLoader) build(spec *fileSpec) *definition.Definition {
+	if spec.Type == "" {
+		l.fail(spec.pos, "type is required")
+		return nil
+	}
+	if len(spec.Steps) == 0 {
+		l.fail(spec.pos, "saga %s has no steps", spec.Type)
+		return nil
+	}
+
+	for name, service := range spec.Services {
+		addr, err := expand(service.Addr)
+		if err != nil {
+			l.fail(service.pos, "service %s: %v", name, err)
+		}
+		l.services[name] = addr
+	}
+
+	b := definition.New(spec.Type).Deadline(spec.Deadline)
+	if spec.Version != 0 {
+		b.Version(spec.Version)
+	}
+
+	for _, s := range spec.Steps {
+		if s.Parallel == nil {
+			l.step(s, b.Step(s.Name))
+			continue
+		}
+
+		if s.Name != "" || s.Action != nil || s.Compensation != nil || s.Retry != nil || s.Deadline != 0 || s.Pivot {
+			l.fail(s.pos, "a parallel group only lists its steps")
+			continue
+		}
+		if len(s.Parallel) == 0 {
+			l.fail(s.pos, "parallel group has no steps")
+			continue
+		}
+		g := b.Parallel()
+		for _, inner := range s.Parallel {
+			if inner.Parallel != nil {
+				l.fail(inner.pos, "parallel groups cannot be nested")
+				continue
+			}
+			l.step(inner, g.Step(inner.Name))
+		}
+	}
+
+	if len(l.errs) > 0 {
+		return nil
+	}
+
+	// What is left is checked across steps, e.g. the references to other
+	// steps and the pivot rules
+	def, err := b.Build()
+	if err != nil {
+		l.fail(spec.pos, "%v", err)
+		return nil
+	}
+	return def
+}
+
+func (l *fileLoader) step(s stepSpec, sb *definition.StepBuilder) {
+	if s.Name == "" {
+		l.fail(s.pos, "step has no name")
+		return
+	}
+	if s.Action == nil {
+		l.fail(s.pos, "step %s has no action", s.Name)
+		return
+	}
+
+	if action, request, ok := l.call(s.Name, s.Action); ok {
+		sb.ActionTemplate(action, request)
+	}
+	if s.Compensation != nil {
+		if action, request, ok := l.call(s.Name, s.Compensation); ok {
+			sb.CompensationTemplate(action, request)
+		}
+	}
+
+	if s.Retry != nil {
+		if policy, ok := l.retry(s.Retry); ok {
+			sb.Retry(policy)
+		}
+	}
+	if s.Deadline != 0 {
+		sb.Deadline(s.Deadline)
+	}
+	if s.Pivot {
+		sb.Pivot()
+	}
+}
+
+// call resolves the method of a call and checks its request template
+// against the input message
+func (l *fileLoader) call(step string, c *callSpec) (definition.Action, definition.Template, bool) {
+	addr, ok := l.services[c.Service]
+	if !ok {
+		l.fail(c.pos, "step %s: unknown service %q, it must be listed in services", step, c.Service)
+		return nil, nil, false
+	}
+	// An address that could not be expanded is already reported
+	if addr == "" {
+		return nil, nil, false
+	}
+	if c.Timeout < 0 {
+		l.fail(c.pos, "step %s: negative timeout", step)
+		return nil, nil, false
+	}
+
+	method, err := l.methods.Method(l.ctx, addr, c.Method)
+	if err != nil {
+		l.fail(c.pos, "step %s: %v", step, err)
+		return nil, nil, false
+	}
+
+	request := definition.Template{}
+	if c.Request.Kind != 0 {
+		var ok bool
+		if request, ok = l.message(&c.Request, method.Input()); !ok {
+			return nil, nil, false
+		}
+	}
+
+	action := definition.Action(method.Invoke)
+	if c.Timeout > 0 {
+		action = withTimeout(action, c.Timeout)
+	}
+	return action, request, true
+}
+
+func (l *fileLoader) retry(r *retrySpec) (definition.RetryPolicy, bool) {
+	if r.Default {
+		return definition.DefaultRetryPolicy, true
+	}
+
+	policy := definition.RetryPolicy{
+		MaxAttempts:    r.MaxAttempts,
+		InitialBackoff: r.InitialBackoff,
+		MaxBackoff:     r.MaxBackoff,
+		Multiplier:     r.Multiplier,
+		Jitter:         r.Jitter,
+	}
+	for _, name := range r.RetryableCodes {
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(`"` + name + `"`)); err != nil {
+			l.fail(r.pos, "unknown gRPC status code %q", name)
+			return policy, false
+		}
+		policy.RetryableCodes = append(policy.RetryableCodes, code)
+	}
+	return policy, true
+}
+
+// message converts a mapping into a template of a message. Fields are named
+// by their proto or JSON name. md is nil where the content is not checked,
+// e.g. in a google.protobuf.Struct.
+func (l *fileLoader) message(n *yaml.Node, md protoreflect.MessageDescriptor) (definition.Template, bool) {
+	if n.Kind != yaml.MappingNode {
+		l.fail(at(n), "request must be a mapping")
+		return nil, false
+	}
+
+	template := definition.Template{}
+	ok := true
+	for i := 0; i < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+
+		var field protoreflect.FieldDescriptor
+		if md != nil {
+			field = md.Fields().ByName(protoreflect.Name(key.Value))
+			if field == nil {
+				field = md.Fields().ByJSONName(key.Value)
+			}
+			if field == nil {
+				l.fail(at(key), "%s has no field %s", md.FullName(), key.Value)
+				ok = false
+				continue
+			}
+			if field.Name() == "idempotency_key" {
+				l.fail(at(key), "idempotency_key is set by the orchestrator")
+				ok = false
+				continue
+			}
+		}
+
+		v, valid := l.value(value, field)
+		if !valid {
+			ok = false
+			continue
+		}
+		template[key.Value] = v
+	}
+	return template, ok
+}
+
+// value converts a node into a template value for a field; field is nil
+// where the content is not checked
+func (l *fileLoader) value(n *yaml.Node, field protoreflect.FieldDescriptor) (any, bool) {
+	if n.Kind == yaml.AliasNode {
+		return l.value(n.Alias, field)
+	}
+
+	switch {
+	case n.Kind == yaml.ScalarNode && n.Tag == "!!str" && strings.HasPrefix(n.Value, "$$"):
+		return n.Value[1:], true
+	case n.Kind == yaml.ScalarNode && n.Tag == "!!str" && strings.HasPrefix(n.Value, "$"):
+		return l.ref(n)
+	case n.Kind == yaml.ScalarNode:
+		var v any
+		if err := n.Decode(&v); err != nil {
+			l.fail(at(n), "%v", err)
+			return nil, false
+		}
+		return v, true
+	}
+
+	list := n.Kind == yaml.SequenceNode || hasKey(n, "$for_each")
+	if field != nil {
+		switch {
+		case list && !field.IsList():
+			l.fail(at(n), "field %s is not repeated", field.Name())
+			return nil, false
+		case !list && field.IsList():
+			l.fail(at(n), "field %s is repeated", field.Name())
+			return nil, false
+		case !list && field.Message() == nil:
+			l.fail(at(n), "field %s is not a message", field.Name())
+			return nil, false
+		}
+	}
+
+	switch {
+	case n.Kind == yaml.SequenceNode:
+		items := make([]any, len(n.Content))
+		ok := true
+		for i, item := range n.Content {
+			v, valid := l.element(item, field)
+			if !valid {
+				ok = false
+				continue
+			}
+			items[i] = v
+		}
+		return items, ok
+	case list:
+		return l.each(n, field)
+	default:
+		return l.message(n, messageOf(field))
+	}
+}
+
+// element converts an element of the repeated field
+func (l *fileLoader) element(n *yaml.Node, field protoreflect.FieldDescriptor) (any, bool) {
+	if n.Kind == yaml.AliasNode {
+		return l.element(n.Alias, field)
+	}
+	if field == nil || n.Kind == yaml.ScalarNode {
+		return l.value(n, nil)
+	}
+
+	if n.Kind == yaml.SequenceNode || hasKey(n, "$for_each") {
+		l.fail(at(n), "elements of %s cannot be lists", field.Name())
+		return nil, false
+	}
+	if field.Message() == nil {
+		l.fail(at(n), "elements of %s are not messages", field.Name())
+		return nil, false
+	}
+	return l.message(n, messageOf(field))
+}
+
+// each converts {$for_each: $ref, $item: {...}}
+func (l *fileLoader) each(n *yaml.Node, field protoreflect.FieldDescriptor) (any, bool) {
+	var over, item *yaml.Node
+	for i := 0; i < len(n.Content); i += 2 {
+		switch key := n.Content[i]; key.Value {
+		case "$for_each":
+			over = n.Content[i+1]
+		case "$item":
+			item = n.Content[i+1]
+		default:
+			l.fail(at(key), "unknown field %q in $for_each, only $item is allowed", key.Value)
+			return nil, false
+		}
+	}
+
+	if over.Kind != yaml.ScalarNode || !strings.HasPrefix(over.Value, "$") {
+		l.fail(at(over), "$for_each must reference an array, e.g. $payload.items")
+		return nil, false
+	}
+	ref, ok := l.ref(over)
+	if !ok {
+		return nil, false
+	}
+	if item == nil {
+		l.fail(at(n), "$for_each needs an $item")
+		return nil, false
+	}
+	if field != nil && field.Message() == nil {
+		l.fail(at(item), "elements of %s are not messages", field.Name())
+		return nil, false
+	}
+	template, ok := l.message(item, messageOf(field))
+	if !ok {
+		return nil, false
+	}
+	return definition.ForEach(ref, template), true
+}
+
+// ref converts $root.path into a reference; the path itself is checked when
+// the definition is built
+func (l *fileLoader) ref(n *yaml.Node) (definition.Ref, bool) {
+	path := strings.TrimPrefix(n.Value, "$")
+	root, _, _ := strings.Cut(path, ".")
+	switch root {
+	case "payload", "steps", "item":
+		return definition.Ref(path), true
+	}
+	l.fail(at(n), "reference %s must start with $payload, $steps or $item", n.Value)
+	return "", false
+}
+
+func hasKey(n *yaml.Node, key string) bool {
+	for i := 0; i < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
+
+// messageOf is the message a field holds, when its content can be checked:
+// maps and well-known types such as google.protobuf.Struct are taken as is
+func messageOf(field protoreflect.FieldDescriptor) protoreflect.MessageDescriptor {
+	if field == nil || field.Message() == nil || field.IsMap() {
+		return nil
+	}
+	if field.Message().ParentFile().Package() == "google.protobuf" {
+		return nil
+	}
+	return field.Message()
+}
+
+// expand replaces the ${VAR} references of an address
+func expand(s string) (string, error) {
+	var missing []string
+	addr := os.Expand(s, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	if addr == "" {
+		return "", fmt.Errorf("address is empty")
+	}
+	return addr, nil
+}
+
+// withTimeout bounds each call of an action
+func withTimeout(action definition.Action, timeout time.Duration) definition.Action {
+	return func(ctx context.Context, idempotencyKey string, request json.RawMessage) (json.RawMessage, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return action(ctx, idempotencyKey, request)
+	}
+}
@@ -0,0 +1,352 @@
+package loader
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	orderpb "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/gen/proto/order/v1"
+	paymentpb "github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/gen/proto/payment/v1"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/definition"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/infrastructure/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+type orderServer struct {
+	orderpb.UnimplementedOrderServiceServer
+}
+
+func (orderServer) CreateOrder(ctx context.Context, req *orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error) {
+	return &orderpb.CreateOrderResponse{
+		OrderId: req.IdempotencyKey + "/" + req.CustomerId,
+		Status:  "CREATED",
+	}, nil
+}
+
+type paymentServer struct {
+	paymentpb.UnimplementedPaymentServiceServer
+}
+
+// serve starts the order and payment services with reflection, and points
+// ORDER_SERVICE_ADDR and PAYMENT_SERVICE_ADDR to them
+func serve(t *testing.T) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	orderpb.RegisterOrderServiceServer(server, orderServer{})
+	paymentpb.RegisterPaymentServiceServer(server, paymentServer{})
+	reflection.Register(server)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	t.Setenv("ORDER_SERVICE_ADDR", lis.Addr().String())
+	t.Setenv("PAYMENT_SERVICE_ADDR", lis.Addr().String())
+}
+
+func load(t *testing.T, dir string) ([]*definition.Definition, error) {
+	t.Helper()
+
+	methods := client.NewReflectionClient()
+	t.Cleanup(func() { methods.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return Load(ctx, dir, methods)
+}
+
+func TestLoadExample(t *testing.T) {
+	serve(t)
+
+	defs, err := load(t, filepath.Join("..", "..", "..", "definitions"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(defs) != 1 {
+		t.Fatalf("Load() = %d definitions, want 1", len(defs))
+	}
+
+	def := defs[0]
+	if def.Type != "digital_order" || def.Version != 1 || def.Deadline != 2*time.Minute {
+		t.Errorf("definition = %s v%d, deadline %v", def.Type, def.Version, def.Deadline)
+	}
+
+	want := []struct {
+		name         string
+		pivot        bool
+		compensation bool
+		maxAttempts  int
+	}{
+		{"create_order", false, true, definition.DefaultRetryPolicy.MaxAttempts},
+		{"process_payment", true, false, 3},
+		{"confirm_order", false, false, definition.DefaultRetryPolicy.MaxAttempts},
+	}
+	if len(def.Steps) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(def.Steps), len(want))
+	}
+	for i, w := range want {
+		step := def.Steps[i]
+		if step.Name != w.name || step.Pivot != w.pivot || step.HasCompensation() != w.compensation || step.Retry.MaxAttempts != w.maxAttempts {
+			t.Errorf("step %d = %s (pivot %v, compensation %v, %d attempts), want %+v",
+				i, step.Name, step.Pivot, step.HasCompensation(), step.Retry.MaxAttempts, w)
+		}
+	}
+}
+
+func TestLoadedActionCallsMethod(t *testing.T) {
+	serve(t)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "create.yaml", `
+type: create
+services:
+  orders: ${ORDER_SERVICE_ADDR}
+steps:
+  - name: create_order
+    action:
+      service: orders
+      method: order.v1.OrderService/CreateOrder
+      request:
+        customer_id: $payload.customer_id
+        items:
+          $for_each: $payload.items
+          $item:
+            product_id: $item.sku
+            quantity: $item.quantity
+`)
+	defs, err := load(t, dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	step := defs[0].Steps[0]
+	request, err := step.Request(definition.State{
+		Payload: json.RawMessage(`{"customer_id": "c-1", "items": [{"sku": "p-1", "quantity": 2}]}`),
+	})
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if want := `{"customer_id":"c-1","items":[{"product_id":"p-1","quantity":2}]}`; string(request) != want {
+		t.Errorf("Request() = %s, want %s", request, want)
+	}
+
+	response, err := step.Action(context.Background(), "key-1", request)
+	if err != nil {
+		t.Fatalf("Action() error = %v", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(response, &out); err != nil {
+		t.Fatalf("response %s: %v", response, err)
+	}
+	// The idempotency key is forwarded by the orchestrator
+	if out["order_id"] != "key-1/c-1" {
+		t.Errorf("Action() = %s, want order_id key-1/c-1", response)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	serve(t)
+
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{
+			name: "unknown step field",
+			file: `
+type: bad
+steps:
+  - name: create_order
+    acton: {}
+`,
+			want: []string{`bad.yaml:4:5: unknown field "acton" in step`},
+		},
+		{
+			name: "missing type",
+			file: `
+steps:
+  - name: create_order
+`,
+			want: []string{"bad.yaml:1:1: type is required"},
+		},
+		{
+			name: "value of the wrong type",
+			file: `
+type: bad
+deadline: soon
+steps:
+  - name: create_order
+`,
+			want: []string{"bad.yaml:2: cannot unmarshal !!str `soon` into time.Duration"},
+		},
+		{
+			name: "every problem of the steps",
+			file: `
+type: bad
+services:
+  orders: ${ORDER_SERVICE_ADDR}
+steps:
+  - name: create_order
+    action:
+      service: orders
+      method: order.v1.OrderService/CreateOrder
+      request:
+        custmer_id: $payload.customer_id
+        idempotency_key: fixed
+        total_amount: $input.total
+  - name: cancel
+    action:
+      service: billing
+      method: billing.v1.BillingService/Cancel
+  - name: confirm_order
+    action:
+      service: orders
+      method: order.v1.OrderService/Confirm
+`,
+			want: []string{
+				"bad.yaml:10:9: order.v1.CreateOrderRequest has no field custmer_id",
+				"bad.yaml:11:9: idempotency_key is set by the orchestrator",
+				"bad.yaml:12:23: reference $input.total must start with $payload, $steps or $item",
+				`bad.yaml:15:7: step cancel: unknown service "billing", it must be listed in services`,
+				"bad.yaml:19:7: step confirm_order: service order.v1.OrderService has no method Confirm",
+			},
+		},
+		{
+			name: "field shapes",
+			file: `
+type: bad
+services:
+  orders: ${ORDER_SERVICE_ADDR}
+steps:
+  - name: create_order
+    action:
+      service: orders
+      method: order.v1.OrderService/CreateOrder
+      request:
+        customer_id: [a, b]
+        items:
+          product_id: p-1
+`,
+			want: []string{
+				"bad.yaml:10:22: field customer_id is not repeated",
+				"bad.yaml:12:11: field items is repeated",
+			},
+		},
+		{
+			name: "invalid retry",
+			file: `
+type: bad
+steps:
+  - name: create_order
+    retry: sometimes
+`,
+			want: []string{`bad.yaml:4:12: retry must be a policy or "default", not "sometimes"`},
+		},
+		{
+			name: "unknown method",
+			file: `
+type: bad
+services:
+  orders: ${ORDER_SERVICE_ADDR}
+steps:
+  - name: confirm_order
+    action:
+      service: orders
+      method: order.v1.OrderService/Confirm
+`,
+			want: []string{"bad.yaml:7:7: step confirm_order: service order.v1.OrderService has no method Confirm"},
+		},
+		{
+			name: "unset address",
+			file: `
+type: bad
+services:
+  orders: ${BILLING_SERVICE_ADDR}
+steps:
+  - name: create_order
+    action:
+      service: orders
+      method: order.v1.OrderService/CreateOrder
+`,
+			want: []string{"bad.yaml:3:11: service orders: environment variable BILLING_SERVICE_ADDR is not set"},
+		},
+		{
+			name: "checked when the definition is built",
+			file: `
+type: bad
+services:
+  orders: ${ORDER_SERVICE_ADDR}
+steps:
+  - name: cancel_order
+    action:
+      service: orders
+      method: order.v1.OrderService/CancelOrder
+      request:
+        order_id: $steps.create_order.order_id
+`,
+			want: []string{"bad.yaml:1:1: saga bad: step cancel_order: request: order_id: reference \"steps.create_order.order_id\": step create_order has not run when the request is built"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "bad.yaml", tt.file)
+			// Files of other extensions are ignored
+			writeFile(t, dir, "README.md", "not a definition")
+
+			_, err := load(t, dir)
+			if err == nil {
+				t.Fatal("Load() error = nil")
+			}
+
+			got := strings.Split(err.Error(), "\n")[1:]
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Load() problems =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	serve(t)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "confirm.json", `{
+  "type": "confirm",
+  "services": {"orders": "${ORDER_SERVICE_ADDR}"},
+  "steps": [
+    {
+      "name": "confirm_order",
+      "action": {
+        "service": "orders",
+        "method": "order.v1.OrderService/ConfirmOrder",
+        "request": {"order_id": "$payload.order_id", "note": 1}
+      }
+    }
+  ]
+}`)
+
+	_, err := load(t, dir)
+	want := `confirm.json:10:54: order.v1.ConfirmOrderRequest has no field note`
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("Load() error = %v, want %q", err, want)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.TrimPrefix(content, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
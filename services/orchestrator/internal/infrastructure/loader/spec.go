@@ -0,0 +1,148 @@
+package loader
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The layout of a definition file. Every part records where it starts, so
+// that problems are reported at their line and column.
+
+// position is a line and column of a definition file, both from 1; the
+// column is 0 when unknown
+type position struct {
+	line, column int
+}
+
+func at(n *yaml.Node) position {
+	return position{line: n.Line, column: n.Column}
+}
+
+type fileSpec struct {
+	Type     string                 `yaml:"type"`
+	Version  int                    `yaml:"version"`
+	Deadline time.Duration          `yaml:"deadline"`
+	Services map[string]serviceSpec `yaml:"services"`
+	Steps    []stepSpec             `yaml:"steps"`
+	pos      position
+}
+
+func (f *fileSpec) UnmarshalYAML(n *yaml.Node) error {
+	type plain fileSpec
+	f.pos = at(n)
+	return decodeStrict(n, "saga definition", (*plain)(f))
+}
+
+// serviceSpec is the address of a downstream service. ${VAR} references are
+// replaced with environment variables.
+type serviceSpec struct {
+	Addr string
+	pos  position
+}
+
+func (s *serviceSpec) UnmarshalYAML(n *yaml.Node) error {
+	s.pos = at(n)
+	return n.Decode(&s.Addr)
+}
+
+// stepSpec is a step, or a group of steps running concurrently when
+// Parallel is set
+type stepSpec struct {
+	Name         string        `yaml:"name"`
+	Action       *callSpec     `yaml:"action"`
+	Compensation *callSpec     `yaml:"compensation"`
+	Retry        *retrySpec    `yaml:"retry"`
+	Deadline     time.Duration `yaml:"deadline"`
+	Pivot        bool          `yaml:"pivot"`
+	Parallel     []stepSpec    `yaml:"parallel"`
+	pos          position
+}
+
+func (s *stepSpec) UnmarshalYAML(n *yaml.Node) error {
+	type plain stepSpec
+	s.pos = at(n)
+	return decodeStrict(n, "step", (*plain)(s))
+}
+
+// callSpec is a call of a unary gRPC method. Request is the request template.
+type callSpec struct {
+	Service string        `yaml:"service"`
+	Method  string        `yaml:"method"`
+	Timeout time.Duration `yaml:"timeout"`
+	Request yaml.Node     `yaml:"request"`
+	pos     position
+}
+
+func (c *callSpec) UnmarshalYAML(n *yaml.Node) error {
+	type plain callSpec
+	c.pos = at(n)
+	return decodeStrict(n, "call", (*plain)(c))
+}
+
+// retrySpec is a retry policy, or the default one when given as "default"
+type retrySpec struct {
+	Default        bool          `yaml:"-"`
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	Multiplier     float64       `yaml:"multiplier"`
+	Jitter         float64       `yaml:"jitter"`
+	RetryableCodes []string      `yaml:"retryable_codes"`
+	pos            position
+}
+
+func (r *retrySpec) UnmarshalYAML(n *yaml.Node) error {
+	type plain retrySpec
+	r.pos = at(n)
+	if n.Kind == yaml.ScalarNode {
+		if n.Value != "default" {
+			return &specError{pos: r.pos, msg: fmt.Sprintf("retry must be a policy or \"default\", not %q", n.Value)}
+		}
+		r.Default = true
+		return nil
+	}
+	return decodeStrict(n, "retry policy", (*plain)(r))
+}
+
+// specError is a problem at a position of a definition file
+type specError struct {
+	pos position
+	msg string
+}
+
+// Error formats the problem as line:column: message. The decoder only knows
+// the line of the values it cannot convert.
+func (e *specError) Error() string {
+	if e.pos.column == 0 {
+		return fmt.Sprintf("%d: %s", e.pos.line, e.msg)
+	}
+	return fmt.Sprintf("%d:%d: %s", e.pos.line, e.pos.column, e.msg)
+}
+
+// decodeStrict decodes a mapping into a struct, refusing the keys that are
+// not one of its yaml fields
+func decodeStrict(n *yaml.Node, what string, out any) error {
+	if n.Kind != yaml.MappingNode {
+		return &specError{pos: at(n), msg: what + " must be a mapping"}
+	}
+
+	known := make(map[string]bool)
+	t := reflect.TypeOf(out).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			known[name] = true
+		}
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		if key := n.Content[i]; !known[key.Value] {
+			return &specError{pos: at(key), msg: fmt.Sprintf("unknown field %q in %s", key.Value, what)}
+		}
+	}
+
+	return n.Decode(out)
+}
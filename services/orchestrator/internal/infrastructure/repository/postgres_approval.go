@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+type postgresApprovalRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresApprovalRepository(db *sql.DB) repository.ApprovalRepository {
+	return &postgresApprovalRepository{db: db}
+}
+
+const approvalColumns = `a.saga_id, a.step_name, a.status, a.approver_id, a.comment, a.requested_at, a.expires_at, a.decided_at`
+
+func (r *postgresApprovalRepository) Request(ctx context.Context, approval *entity.Approval) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO saga_approvals (saga_id, step_name, status, requested_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (saga_id, step_name) DO NOTHING
+	`
+	result, err := tx.ExecContext(ctx, query,
+		approval.SagaID, approval.StepName, string(approval.Status), approval.RequestedAt, approval.ExpiresAt,
+	)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to insert saga approval", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return nil
+	}
+
+	if err := appendEvent(ctx, tx, entity.NewApprovalEvent(approval)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return nil
+}
+
+func (r *postgresApprovalRepository) Find(ctx context.Context, sagaID, stepName string) (*entity.Approval, error) {
+	query := `SELECT ` + approvalColumns + ` FROM saga_approvals a WHERE a.saga_id = $1 AND a.step_name = $2`
+	approval, err := scanApproval(r.db.QueryRowContext(ctx, query, sagaID, stepName))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga approval", err)
+	}
+	return approval, nil
+}
+
+func (r *postgresApprovalRepository) Decide(ctx context.Context, approval *entity.Approval) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockSagaForEvent(ctx, tx, approval.SagaID); err != nil {
+		return err
+	}
+
+	// Expiry is checked again: the executor may not have expired it yet
+	query := `
+		UPDATE saga_approvals
+		SET status = $3, approver_id = $4, comment = $5, decided_at = $6
+		WHERE saga_id = $1 AND step_name = $2 AND status = 'PENDING' AND expires_at > $6
+	`
+	result, err := tx.ExecContext(ctx, query,
+		approval.SagaID, approval.StepName, string(approval.Status), approval.ApproverID,
+		nullString(approval.Comment), approval.DecidedAt,
+	)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to update saga approval", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return pErrors.E(pErrors.Conflict, "approval of step "+approval.StepName+" was already decided or expired", nil)
+	}
+
+	if err := appendEvent(ctx, tx, entity.NewApprovalEvent(approval)); err != nil {
+		return err
+	}
+
+	// The wait timer of the step fires now instead of at expiry
+	query = `
+		UPDATE saga_timers SET fire_at = NOW()
+		FROM saga_steps
+		WHERE saga_timers.step_id = saga_steps.id
+		AND saga_steps.saga_id = $1 AND saga_steps.step_name = $2
+		AND saga_timers.kind = 'STEP_WAIT' AND saga_timers.fired_at IS NULL
+	`
+	if _, err := tx.ExecContext(ctx, query, approval.SagaID, approval.StepName); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to wake up saga", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return nil
+}
+
+func (r *postgresApprovalRepository) Expire(ctx context.Context, sagaID, stepName string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE saga_approvals a SET status = 'EXPIRED', decided_at = NOW()
+		WHERE a.saga_id = $1 AND a.step_name = $2 AND a.status = 'PENDING'
+		RETURNING ` + approvalColumns
+	approval, err := scanApproval(tx.QueryRowContext(ctx, query, sagaID, stepName))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to expire saga approval", err)
+	}
+
+	if err := appendEvent(ctx, tx, entity.NewApprovalEvent(approval)); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return true, nil
+}
+
+func (r *postgresApprovalRepository) ListPending(ctx context.Context, filter repository.ApprovalFilter) ([]*entity.Approval, error) {
+	// An approval whose step was abandoned, e.g. by a cancellation, stays
+	// PENDING but leaves the queue
+	query := `
+		SELECT ` + approvalColumns + `, s.saga_type, s.payload
+		FROM saga_approvals a
+		JOIN sagas s ON s.id = a.saga_id
+		JOIN saga_steps st ON st.saga_id = a.saga_id AND st.step_name = a.step_name
+		WHERE a.status = 'PENDING' AND st.status = 'WAITING'
+		AND ($1 = '' OR s.saga_type = $1)
+		ORDER BY a.requested_at, a.saga_id, a.step_name
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, filter.SagaType, filter.Limit)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga approvals", err)
+	}
+	defer rows.Close()
+
+	var approvals []*entity.Approval
+	for rows.Next() {
+		var sagaType string
+		var payload []byte
+		approval, err := scanApproval(rows, &sagaType, &payload)
+		if err != nil {
+			return nil, pErrors.E(pErrors.Internal, "scan saga approval", err)
+		}
+		approval.SagaType = sagaType
+		approval.SagaPayload = payload
+		approvals = append(approvals, approval)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga approvals", err)
+	}
+
+	return approvals, nil
+}
+
+// scanApproval reads approvalColumns, then the extra columns into extra
+func scanApproval(row rowScanner, extra ...any) (*entity.Approval, error) {
+	var approval entity.Approval
+	var status string
+	var approverID, comment sql.NullString
+	var decidedAt sql.NullTime
+	dest := []any{
+		&approval.SagaID, &approval.StepName, &status, &approverID, &comment,
+		&approval.RequestedAt, &approval.ExpiresAt, &decidedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	approval.Status = entity.ApprovalStatus(status)
+	approval.ApproverID = approverID.String
+	approval.Comment = comment.String
+	if decidedAt.Valid {
+		approval.DecidedAt = &decidedAt.Time
+	}
+	return &approval, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+type postgresChoreographyRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresChoreographyRepository(db *sql.DB) repository.ChoreographyRepository {
+	return &postgresChoreographyRepository{db: db}
+}
+
+func (r *postgresChoreographyRepository) AddEvent(ctx context.Context, sagaType string, event *entity.ChoreographyEvent) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	// Events of a run may arrive in any order, the first one creates it
+	query := `
+		INSERT INTO choreographies (correlation_id, saga_type, status, started_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (correlation_id) DO NOTHING
+	`
+	_, err = tx.ExecContext(ctx, query, event.CorrelationID, sagaType, string(entity.SagaStatusExecuting), event.PublishedAt)
+	if err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to insert choreography", err)
+	}
+
+	query = `
+		INSERT INTO choreography_events (correlation_id, event_type, message_id, payload, published_at, received_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (correlation_id, event_type) DO NOTHING
+	`
+	result, err := tx.ExecContext(ctx, query,
+		event.CorrelationID, event.Type, event.MessageID, []byte(event.Payload), event.PublishedAt, event.ReceivedAt,
+	)
+	if err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to insert choreography event", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *postgresChoreographyRepository) FindByCorrelationID(ctx context.Context, correlationID string) (*entity.Choreography, error) {
+	query := `
+		SELECT correlation_id, saga_type, status, error_message, started_at, updated_at, completed_at, version
+		FROM choreographies
+		WHERE correlation_id = $1
+	`
+	var c entity.Choreography
+	var status string
+	var errorMessage sql.NullString
+	err := r.db.QueryRowContext(ctx, query, correlationID).Scan(
+		&c.CorrelationID, &c.Type, &status, &errorMessage, &c.StartedAt, &c.UpdatedAt, &c.CompletedAt, &c.Version,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pErrors.E(pErrors.NotFound, "choreography not found", nil)
+	}
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to find choreography", err)
+	}
+	c.Status = entity.SagaStatus(status)
+	c.ErrorMessage = errorMessage.String
+
+	query = `
+		SELECT event_type, message_id, payload, published_at, received_at
+		FROM choreography_events
+		WHERE correlation_id = $1
+		ORDER BY published_at ASC, received_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, correlationID)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query choreography events", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event := entity.ChoreographyEvent{CorrelationID: correlationID}
+		var payload []byte
+		if err := rows.Scan(&event.Type, &event.MessageID, &payload, &event.PublishedAt, &event.ReceivedAt); err != nil {
+			return nil, pErrors.E(pErrors.Internal, "scan choreography event", err)
+		}
+		event.Payload = payload
+		c.Events = append(c.Events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query choreography events", err)
+	}
+
+	return &c, nil
+}
+
+func (r *postgresChoreographyRepository) Update(ctx context.Context, c *entity.Choreography) error {
+	query := `
+		UPDATE choreographies
+		SET status = $3, error_message = $4, updated_at = $5, completed_at = $6, version = version + 1
+		WHERE correlation_id = $1 AND version = $2
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		c.CorrelationID, c.Version, string(c.Status), nullString(c.ErrorMessage), c.UpdatedAt, c.CompletedAt,
+	)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to update choreography", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return pErrors.E(pErrors.Conflict, "choreography changed concurrently", nil)
+	}
+
+	c.Version++
+	return nil
+}
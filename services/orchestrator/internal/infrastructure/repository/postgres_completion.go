@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+type postgresCompletionRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresCompletionRepository(db *sql.DB) repository.CompletionRepository {
+	return &postgresCompletionRepository{db: db}
+}
+
+func (r *postgresCompletionRepository) Record(ctx context.Context, completion *entity.StepCompletion) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockSagaForEvent(ctx, tx, completion.SagaID); err != nil {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO saga_step_completions (saga_id, step_name, payload, error_message, source, received_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (saga_id, step_name) DO NOTHING
+	`
+	result, err := tx.ExecContext(ctx, query,
+		completion.SagaID, completion.StepName, nullJSON(completion.Payload), nullString(completion.ErrorMessage),
+		completion.Source, completion.ReceivedAt,
+	)
+	if err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to insert step completion", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return false, nil
+	}
+
+	if err := appendEvent(ctx, tx, entity.NewCompletionEvent(completion)); err != nil {
+		return false, err
+	}
+
+	// The wait timer of the step fires now instead of at its timeout
+	query = `
+		UPDATE saga_timers SET fire_at = NOW()
+		FROM saga_steps
+		WHERE saga_timers.step_id = saga_steps.id
+		AND saga_steps.saga_id = $1 AND saga_steps.step_name = $2
+		AND saga_timers.kind = 'STEP_WAIT' AND saga_timers.fired_at IS NULL
+	`
+	if _, err := tx.ExecContext(ctx, query, completion.SagaID, completion.StepName); err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to wake up saga", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return true, nil
+}
+
+func (r *postgresCompletionRepository) Find(ctx context.Context, sagaID, stepName string) (*entity.StepCompletion, error) {
+	query := `
+		SELECT payload, error_message, source, received_at
+		FROM saga_step_completions
+		WHERE saga_id = $1 AND step_name = $2
+	`
+	completion := entity.StepCompletion{SagaID: sagaID, StepName: stepName}
+	var payload []byte
+	var errorMessage sql.NullString
+	err := r.db.QueryRowContext(ctx, query, sagaID, stepName).Scan(&payload, &errorMessage, &completion.Source, &completion.ReceivedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query step completion", err)
+	}
+
+	completion.Payload = payload
+	completion.ErrorMessage = errorMessage.String
+	return &completion, nil
+}
+
+func (r *postgresCompletionRepository) Discard(ctx context.Context, sagaID, stepName string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM saga_step_completions WHERE saga_id = $1 AND step_name = $2`, sagaID, stepName)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to discard step completion", err)
+	}
+	return nil
+}
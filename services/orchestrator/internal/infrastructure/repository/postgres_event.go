@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+type postgresEventRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresEventRepository(db *sql.DB) repository.EventRepository {
+	return &postgresEventRepository{db: db}
+}
+
+func (r *postgresEventRepository) FindBySaga(ctx context.Context, sagaID string) ([]*entity.SagaEvent, error) {
+	query := `
+		SELECT sequence, saga_id, step_name, event_type, data, created_at
+		FROM saga_events
+		WHERE saga_id = $1
+		ORDER BY sequence ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, sagaID)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga events", err)
+	}
+	defer rows.Close()
+
+	var events []*entity.SagaEvent
+	for rows.Next() {
+		var event entity.SagaEvent
+		var eventType string
+		var stepName sql.NullString
+		var data []byte
+		if err := rows.Scan(&event.Sequence, &event.SagaID, &stepName, &eventType, &data, &event.CreatedAt); err != nil {
+			return nil, pErrors.E(pErrors.Internal, "scan saga event", err)
+		}
+		event.StepName = stepName.String
+		event.Type = entity.EventType(eventType)
+		event.Data = data
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga events", err)
+	}
+
+	return events, nil
+}
+
+func (r *postgresEventRepository) FindSagaIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	// Served by idx_saga_events_saga
+	query := `
+		SELECT DISTINCT saga_id
+		FROM saga_events
+		WHERE $1 = '' OR saga_id > $1::uuid
+		ORDER BY saga_id
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga ids", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, pErrors.E(pErrors.Internal, "scan saga id", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga ids", err)
+	}
+
+	return ids, nil
+}
+
+// lockSagaForEvent locks the row of a saga before an event is appended by a
+// write that is not fenced, such as a completion or a signal, so that no
+// event is appended while its projection is restored. It fails with NotFound
+// for an unknown saga.
+func lockSagaForEvent(ctx context.Context, tx *sql.Tx, sagaID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx, `SELECT id FROM sagas WHERE id = $1 FOR SHARE`, sagaID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pErrors.E(pErrors.NotFound, "saga not found", err)
+	}
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "query saga", err)
+	}
+	return nil
+}
+
+// appendEvent adds an event to the log. It is called inside the transaction
+// of the change the event records.
+func appendEvent(ctx context.Context, db execer, event *entity.SagaEvent) error {
+	query := `
+		INSERT INTO saga_events (saga_id, step_name, event_type, data, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := db.ExecContext(ctx, query,
+		event.SagaID, nullString(event.StepName), string(event.Type), []byte(event.Data), event.CreatedAt,
+	)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to append saga event", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+type postgresInterventionRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresInterventionRepository(db *sql.DB) repository.InterventionRepository {
+	return &postgresInterventionRepository{db: db}
+}
+
+func (r *postgresInterventionRepository) Record(ctx context.Context, lease *entity.Lease, saga *entity.Saga, steps []*entity.SagaStep, intervention *entity.Intervention) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	// An action is only recorded together with the changes it made
+	for _, step := range steps {
+		if err := updateStep(ctx, tx, lease, step); err != nil {
+			return err
+		}
+	}
+	if err := updateSaga(ctx, tx, lease, saga); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO saga_interventions (id, saga_id, step_name, action, operator_id, note, previous_status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		intervention.ID, intervention.SagaID, nullString(intervention.StepName), string(intervention.Action),
+		intervention.OperatorID, nullString(intervention.Note), string(intervention.PreviousStatus), intervention.CreatedAt,
+	)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to insert saga intervention", err)
+	}
+
+	if err := appendEvent(ctx, tx, entity.NewInterventionEvent(intervention)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return nil
+}
+
+func (r *postgresInterventionRepository) FindBySaga(ctx context.Context, sagaID string) ([]*entity.Intervention, error) {
+	query := `
+		SELECT id, saga_id, step_name, action, operator_id, note, previous_status, created_at
+		FROM saga_interventions
+		WHERE saga_id = $1
+		ORDER BY created_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, sagaID)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga interventions", err)
+	}
+	defer rows.Close()
+
+	var interventions []*entity.Intervention
+	for rows.Next() {
+		var intervention entity.Intervention
+		var action, previousStatus string
+		var stepName, note sql.NullString
+		if err := rows.Scan(
+			&intervention.ID, &intervention.SagaID, &stepName, &action,
+			&intervention.OperatorID, &note, &previousStatus, &intervention.CreatedAt,
+		); err != nil {
+			return nil, pErrors.E(pErrors.Internal, "scan saga intervention", err)
+		}
+		intervention.StepName = stepName.String
+		intervention.Action = entity.InterventionAction(action)
+		intervention.Note = note.String
+		intervention.PreviousStatus = entity.SagaStatus(previousStatus)
+		interventions = append(interventions, &intervention)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga interventions", err)
+	}
+
+	return interventions, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+type postgresLockRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresLockRepository(db *sql.DB) repository.LockRepository {
+	return &postgresLockRepository{db: db}
+}
+
+func (r *postgresLockRepository) Acquire(ctx context.Context, sagaID, ownerID string, ttl time.Duration) (*entity.Lease, error) {
+	// Insert the lease, or steal it only if the current one has expired.
+	// Each successful acquisition draws a new fencing token.
+	query := `
+		INSERT INTO saga_locks (saga_id, owner_id, acquired_at, expires_at, fencing_token)
+		VALUES ($1, $2, NOW(), NOW() + make_interval(secs => $3), nextval('saga_lock_fencing_seq'))
+		ON CONFLICT (saga_id) DO UPDATE
+		SET owner_id = EXCLUDED.owner_id,
+			acquired_at = EXCLUDED.acquired_at,
+			expires_at = EXCLUDED.expires_at,
+			fencing_token = EXCLUDED.fencing_token
+		WHERE saga_locks.expires_at < NOW()
+		RETURNING fencing_token, expires_at
+	`
+	lease := entity.Lease{SagaID: sagaID, OwnerID: ownerID}
+	err := r.db.QueryRowContext(ctx, query, sagaID, ownerID, ttl.Seconds()).Scan(&lease.Token, &lease.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to acquire saga lock", err)
+	}
+
+	return &lease, nil
+}
+
+func (r *postgresLockRepository) Renew(ctx context.Context, lease *entity.Lease, ttl time.Duration) error {
+	query := `
+		UPDATE saga_locks
+		SET expires_at = NOW() + make_interval(secs => $3)
+		WHERE saga_id = $1 AND fencing_token = $2
+	`
+	result, err := r.db.ExecContext(ctx, query, lease.SagaID, lease.Token, ttl.Seconds())
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to renew saga lock", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return pErrors.E(pErrors.Conflict, "saga lease lost", nil)
+	}
+
+	return nil
+}
+
+func (r *postgresLockRepository) Release(ctx context.Context, lease *entity.Lease) error {
+	query := `DELETE FROM saga_locks WHERE saga_id = $1 AND fencing_token = $2`
+	if _, err := r.db.ExecContext(ctx, query, lease.SagaID, lease.Token); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to release saga lock", err)
+	}
+	return nil
+}
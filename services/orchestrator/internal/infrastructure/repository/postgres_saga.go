@@ -0,0 +1,793 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type postgresSagaRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresSagaRepository(db *sql.DB) repository.SagaRepository {
+	return &postgresSagaRepository{db: db}
+}
+
+func (r *postgresSagaRepository) FindPending(ctx context.Context, limit int) ([]string, error) {
+	return r.findUnlocked(ctx, []string{string(entity.SagaStatusPending)}, limit)
+}
+
+func (r *postgresSagaRepository) FindStuck(ctx context.Context, limit int) ([]string, error) {
+	return r.findUnlocked(ctx, []string{
+		string(entity.SagaStatusExecuting),
+		string(entity.SagaStatusCompensating),
+		string(entity.SagaStatusForwardRecovering),
+	}, limit)
+}
+
+func (r *postgresSagaRepository) FindByID(ctx context.Context, id string) (*entity.Saga, error) {
+	query := `SELECT ` + sagaColumns + ` FROM sagas WHERE id = $1`
+	saga, err := scanSaga(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pErrors.E(pErrors.NotFound, "saga not found", err)
+		}
+		return nil, pErrors.E(pErrors.Internal, "query saga", err)
+	}
+
+	steps, err := r.loadSteps(ctx, saga.ID)
+	if err != nil {
+		return nil, err
+	}
+	saga.Steps = steps
+
+	return saga, nil
+}
+
+func (r *postgresSagaRepository) Create(ctx context.Context, saga *entity.Saga, idempotencyKey string) (*entity.Saga, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	// Generate ID
+	saga.ID = uuid.New().String()
+
+	// A step starts at most one sub-saga
+	query := `
+		INSERT INTO sagas (id, saga_type, definition_version, status, payload, created_at, updated_at, parent_saga_id, parent_step_name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (parent_saga_id, parent_step_name) WHERE parent_saga_id IS NOT NULL DO NOTHING
+	`
+	result, err := tx.ExecContext(ctx, query,
+		saga.ID, saga.Type, saga.DefinitionVersion, string(saga.Status), []byte(saga.Payload),
+		saga.CreatedAt, saga.UpdatedAt,
+		nullString(saga.ParentSagaID), nullString(saga.ParentStep),
+	)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to insert saga", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return nil, pErrors.E(pErrors.Conflict, "step "+saga.ParentStep+" already started a sub-saga", nil)
+	}
+
+	// An expired key is taken over, a live one belongs to another saga
+	idempQuery := `
+		INSERT INTO idempotency_keys (key, saga_id, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key) DO UPDATE
+		SET saga_id = EXCLUDED.saga_id, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+	`
+	result, err = tx.ExecContext(ctx, idempQuery,
+		idempotencyKey, saga.ID, time.Now(), time.Now().Add(idempotencyTTL),
+	)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to insert idempotency key", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, pErrors.E(pErrors.Conflict, "idempotency key already used", nil)
+	}
+
+	if err := appendEvent(ctx, tx, entity.NewSagaEvent(saga)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return saga, nil
+}
+
+func (r *postgresSagaRepository) FindChild(ctx context.Context, parentSagaID, stepName string) (*entity.Saga, error) {
+	query := `SELECT ` + sagaColumns + ` FROM sagas WHERE parent_saga_id = $1 AND parent_step_name = $2`
+	saga, err := scanSaga(r.db.QueryRowContext(ctx, query, parentSagaID, stepName))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query sub-saga", err)
+	}
+
+	steps, err := r.loadSteps(ctx, saga.ID)
+	if err != nil {
+		return nil, err
+	}
+	saga.Steps = steps
+
+	return saga, nil
+}
+
+func (r *postgresSagaRepository) CheckIdempotency(ctx context.Context, key string) (*entity.Saga, error) {
+	query := `
+		SELECT ` + sagaColumns + `
+		FROM idempotency_keys
+		JOIN sagas ON idempotency_keys.saga_id = sagas.id
+		WHERE idempotency_keys.key = $1 AND idempotency_keys.expires_at > NOW()
+	`
+	saga, err := scanSaga(r.db.QueryRowContext(ctx, query, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga", err)
+	}
+	return saga, nil
+}
+
+func (r *postgresSagaRepository) List(ctx context.Context, filter repository.SagaFilter) ([]*entity.Saga, error) {
+	var conditions []string
+	var args []any
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, fmt.Sprintf("saga_type = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.AfterCreatedAt != nil {
+		args = append(args, *filter.AfterCreatedAt, filter.AfterID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	query := `SELECT ` + sagaColumns + ` FROM sagas`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query sagas", err)
+	}
+	defer rows.Close()
+
+	var sagas []*entity.Saga
+	for rows.Next() {
+		saga, err := scanSaga(rows)
+		if err != nil {
+			return nil, pErrors.E(pErrors.Internal, "scan saga", err)
+		}
+		sagas = append(sagas, saga)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query sagas", err)
+	}
+
+	return sagas, nil
+}
+
+func (r *postgresSagaRepository) RequestCancel(ctx context.Context, sagaID string, reason string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	// Not fenced: only the cancel columns are written, which the lease owner
+	// never writes. A repeated request keeps the first reason.
+	query := `
+		UPDATE sagas
+		SET cancel_requested_at = COALESCE(cancel_requested_at, NOW()),
+			cancel_reason = COALESCE(cancel_reason, $2)
+		WHERE id = $1 AND status = ANY($3)
+		RETURNING cancel_requested_at, cancel_requested_at = NOW()
+	`
+	var requestedAt time.Time
+	var first bool
+	err = tx.QueryRowContext(ctx, query, sagaID, nullString(reason), pq.Array([]string{
+		string(entity.SagaStatusPending),
+		string(entity.SagaStatusExecuting),
+		string(entity.SagaStatusCompensating),
+	})).Scan(&requestedAt, &first)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notCancellable(ctx, tx, sagaID)
+	}
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to request saga cancellation", err)
+	}
+
+	if first {
+		if err := appendEvent(ctx, tx, entity.NewCancelEvent(sagaID, reason, requestedAt)); err != nil {
+			return err
+		}
+	}
+
+	if err := wakeUp(ctx, tx, sagaID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return nil
+}
+
+// notCancellable explains why a saga cannot be flagged for cancellation
+func notCancellable(ctx context.Context, tx *sql.Tx, sagaID string) error {
+	var status entity.SagaStatus
+	err := tx.QueryRowContext(ctx, `SELECT status FROM sagas WHERE id = $1`, sagaID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pErrors.E(pErrors.NotFound, "saga not found", err)
+	}
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to request saga cancellation", err)
+	}
+
+	switch status {
+	case entity.SagaStatusForwardRecovering:
+		return pErrors.E(pErrors.Conflict, fmt.Sprintf("saga is %s, past its pivot, and cannot be cancelled", status), nil)
+	case entity.SagaStatusCompleted, entity.SagaStatusCompensated:
+		return pErrors.E(pErrors.Conflict, fmt.Sprintf("saga already finished with status %s", status), nil)
+	default:
+		return pErrors.E(pErrors.Conflict, fmt.Sprintf("saga is %s and cannot be cancelled", status), nil)
+	}
+}
+
+func (r *postgresSagaRepository) FindCancelRequest(ctx context.Context, sagaID string) (string, bool, error) {
+	query := `SELECT cancel_requested_at IS NOT NULL, cancel_reason FROM sagas WHERE id = $1`
+	var requested bool
+	var reason sql.NullString
+	err := r.db.QueryRowContext(ctx, query, sagaID).Scan(&requested, &reason)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, pErrors.E(pErrors.NotFound, "saga not found", err)
+		}
+		return "", false, pErrors.E(pErrors.Internal, "query saga cancellation", err)
+	}
+	return reason.String, requested, nil
+}
+
+func (r *postgresSagaRepository) CreateSteps(ctx context.Context, lease *entity.Lease, steps []*entity.SagaStep) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	// Hold the lease row for the whole transaction so it cannot be stolen midway
+	var token int64
+	fenceQuery := `SELECT fencing_token FROM saga_locks WHERE saga_id = $1 AND fencing_token = $2 FOR SHARE`
+	err = tx.QueryRowContext(ctx, fenceQuery, lease.SagaID, lease.Token).Scan(&token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errLeaseLost
+	}
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to check saga lease", err)
+	}
+
+	query := `
+		INSERT INTO saga_steps (id, saga_id, step_name, step_order, step_group, status, idempotency_key, fencing_token)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	for _, step := range steps {
+		_, err = tx.ExecContext(ctx, query,
+			step.ID, step.SagaID, step.Name, step.Order, step.Group, step.Status, step.IdempotencyKey, lease.Token,
+		)
+		if err != nil {
+			return pErrors.E(pErrors.Internal, "failed to insert saga step", err)
+		}
+
+		if err := appendEvent(ctx, tx, entity.NewStepEvent(step)); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return nil
+}
+
+func (r *postgresSagaRepository) UpdateSaga(ctx context.Context, lease *entity.Lease, saga *entity.Saga) error {
+	return updateSaga(ctx, r.db, lease, saga)
+}
+
+func (r *postgresSagaRepository) UpdateStep(ctx context.Context, lease *entity.Lease, step *entity.SagaStep) error {
+	return updateStep(ctx, r.db, lease, step)
+}
+
+func (r *postgresSagaRepository) Restore(ctx context.Context, lease *entity.Lease, saga *entity.Saga, sequence int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	var token int64
+	fenceQuery := `SELECT fencing_token FROM saga_locks WHERE saga_id = $1 AND fencing_token = $2 FOR SHARE`
+	err = tx.QueryRowContext(ctx, fenceQuery, lease.SagaID, lease.Token).Scan(&token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errLeaseLost
+	}
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to check saga lease", err)
+	}
+
+	// Cancellation is requested without the lease: lock the saga row so that
+	// no event is appended until the projection is restored
+	var last int64
+	lastQuery := `
+		SELECT COALESCE(MAX(saga_events.sequence), 0)
+		FROM (SELECT id FROM sagas WHERE id = $1 FOR UPDATE) AS saga
+		LEFT JOIN saga_events ON saga_events.saga_id = saga.id
+	`
+	if err := tx.QueryRowContext(ctx, lastQuery, saga.ID).Scan(&last); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to check saga events", err)
+	}
+	if last != sequence {
+		return pErrors.E(pErrors.Conflict, "saga changed while its projection was rebuilt", nil)
+	}
+
+	sagaQuery := `
+		UPDATE sagas
+		SET saga_type = $2, status = $3, payload = $4, error_message = $5,
+			created_at = $6, updated_at = $7, completed_at = $8,
+			cancel_requested_at = $9, cancel_reason = $10,
+			parent_saga_id = $11, parent_step_name = $12, definition_version = $13
+		WHERE id = $1
+	`
+	_, err = tx.ExecContext(ctx, sagaQuery,
+		saga.ID, saga.Type, string(saga.Status), []byte(saga.Payload), nullString(saga.ErrorMessage),
+		saga.CreatedAt, saga.UpdatedAt, saga.CompletedAt,
+		saga.CancelRequestedAt, nullString(saga.CancelReason),
+		nullString(saga.ParentSagaID), nullString(saga.ParentStep), saga.DefinitionVersion,
+	)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to restore saga", err)
+	}
+
+	// Steps the events do not know are dropped, the others written back
+	if err := writeSteps(ctx, tx, lease, saga); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return nil
+}
+
+// writeSteps makes the saga_steps rows of a saga exactly its steps: rows of
+// other steps are deleted, the others inserted or overwritten
+func writeSteps(ctx context.Context, tx *sql.Tx, lease *entity.Lease, saga *entity.Saga) error {
+	ids := make([]string, len(saga.Steps))
+	for i, step := range saga.Steps {
+		ids[i] = step.ID
+	}
+	_, err := tx.ExecContext(ctx, `DELETE FROM saga_steps WHERE saga_id = $1 AND NOT (id = ANY($2::uuid[]))`, saga.ID, pq.Array(ids))
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to write saga steps", err)
+	}
+
+	// step_order is unique per saga: move the kept rows out of the way of
+	// the orders they are given
+	_, err = tx.ExecContext(ctx, `UPDATE saga_steps SET step_order = -step_order WHERE saga_id = $1`, saga.ID)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to write saga steps", err)
+	}
+
+	stepQuery := `
+		INSERT INTO saga_steps (
+			id, saga_id, step_name, step_order, step_group, status, idempotency_key,
+			request_payload, response_payload, error_message,
+			executed_at, compensated_at, retry_count, next_retry_at, wait_until, fencing_token
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (id) DO UPDATE
+		SET step_name = EXCLUDED.step_name,
+			step_order = EXCLUDED.step_order,
+			step_group = EXCLUDED.step_group,
+			status = EXCLUDED.status,
+			idempotency_key = EXCLUDED.idempotency_key,
+			request_payload = EXCLUDED.request_payload,
+			response_payload = EXCLUDED.response_payload,
+			error_message = EXCLUDED.error_message,
+			executed_at = EXCLUDED.executed_at,
+			compensated_at = EXCLUDED.compensated_at,
+			retry_count = EXCLUDED.retry_count,
+			next_retry_at = EXCLUDED.next_retry_at,
+			wait_until = EXCLUDED.wait_until,
+			fencing_token = EXCLUDED.fencing_token
+	`
+	for _, step := range saga.Steps {
+		_, err = tx.ExecContext(ctx, stepQuery,
+			step.ID, saga.ID, step.Name, step.Order, step.Group, string(step.Status), step.IdempotencyKey,
+			nullJSON(step.RequestPayload), nullJSON(step.ResponsePayload), nullString(step.ErrorMessage),
+			step.ExecutedAt, step.CompensatedAt, step.RetryCount, step.NextRetryAt, step.WaitUntil, lease.Token,
+		)
+		if err != nil {
+			return pErrors.E(pErrors.Internal, "failed to write saga step", err)
+		}
+	}
+	return nil
+}
+
+func (r *postgresSagaRepository) Upgrade(ctx context.Context, lease *entity.Lease, saga *entity.Saga, fromVersion int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	// Hold the lease row for the whole transaction so it cannot be stolen midway
+	var token int64
+	fenceQuery := `SELECT fencing_token FROM saga_locks WHERE saga_id = $1 AND fencing_token = $2 FOR SHARE`
+	err = tx.QueryRowContext(ctx, fenceQuery, lease.SagaID, lease.Token).Scan(&token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errLeaseLost
+	}
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to check saga lease", err)
+	}
+
+	query := `
+		UPDATE sagas
+		SET definition_version = $2, payload = $3, updated_at = $4
+		WHERE id = $1 AND definition_version = $5
+	`
+	result, err := tx.ExecContext(ctx, query, saga.ID, saga.DefinitionVersion, []byte(saga.Payload), saga.UpdatedAt, fromVersion)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to upgrade saga", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return pErrors.E(pErrors.Conflict, fmt.Sprintf("saga is no longer on version %d", fromVersion), nil)
+	}
+
+	if err := writeSteps(ctx, tx, lease, saga); err != nil {
+		return err
+	}
+
+	if err := appendEvent(ctx, tx, entity.NewUpgradeEvent(saga, fromVersion)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return nil
+}
+
+func (r *postgresSagaRepository) VersionsInUse(ctx context.Context) ([]repository.VersionUsage, error) {
+	// Sagas that may run again, then the completed sub-sagas they may
+	// revert, down to any depth
+	query := `
+		WITH RECURSIVE in_use AS (
+			SELECT id, saga_type, definition_version
+			FROM sagas
+			WHERE status NOT IN ('COMPLETED', 'COMPENSATED')
+			UNION
+			SELECT child.id, child.saga_type, child.definition_version
+			FROM sagas child
+			JOIN in_use ON child.parent_saga_id = in_use.id
+			WHERE child.status = 'COMPLETED'
+		)
+		SELECT saga_type, definition_version, COUNT(*)
+		FROM in_use
+		GROUP BY saga_type, definition_version
+		ORDER BY saga_type, definition_version
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query definition versions in use", err)
+	}
+	defer rows.Close()
+
+	var usages []repository.VersionUsage
+	for rows.Next() {
+		var usage repository.VersionUsage
+		if err := rows.Scan(&usage.SagaType, &usage.Version, &usage.Sagas); err != nil {
+			return nil, pErrors.E(pErrors.Internal, "scan definition version", err)
+		}
+		usages = append(usages, usage)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query definition versions in use", err)
+	}
+
+	return usages, nil
+}
+
+// updateSaga is the fenced write of a saga, shared with the repositories that
+// write a saga inside their own transaction. The event of the new status is
+// appended by the same statement, only when the write applies. A sub-saga
+// that finished wakes up the parent step waiting for it.
+func updateSaga(ctx context.Context, db execer, lease *entity.Lease, saga *entity.Saga) error {
+	event := entity.NewSagaEvent(saga)
+	query := `
+		WITH updated AS (
+			UPDATE sagas
+			SET status = $2, error_message = $3, updated_at = $4, completed_at = $5
+			WHERE id = $1
+			AND EXISTS (
+				SELECT 1 FROM saga_locks
+				WHERE saga_locks.saga_id = sagas.id AND saga_locks.fencing_token = $6
+				FOR SHARE
+			)
+			RETURNING id, parent_saga_id, parent_step_name
+		), parent AS (
+			UPDATE saga_timers SET fire_at = NOW()
+			FROM saga_steps, updated
+			WHERE $10::boolean
+			AND saga_steps.saga_id = updated.parent_saga_id
+			AND saga_steps.step_name = updated.parent_step_name
+			AND saga_timers.step_id = saga_steps.id
+			AND saga_timers.kind = 'STEP_WAIT'
+			AND saga_timers.fired_at IS NULL
+		)
+		INSERT INTO saga_events (saga_id, event_type, data, created_at)
+		SELECT id, $7, $8::jsonb, $9::timestamptz FROM updated
+	`
+	result, err := db.ExecContext(ctx, query,
+		saga.ID, string(saga.Status), nullString(saga.ErrorMessage), saga.UpdatedAt, saga.CompletedAt,
+		lease.Token,
+		string(event.Type), []byte(event.Data), event.CreatedAt,
+		saga.IsTerminal(),
+	)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to update saga", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errLeaseLost
+	}
+
+	return nil
+}
+
+// updateStep is the fenced write of a saga step, appending its event like
+// updateSaga
+func updateStep(ctx context.Context, db execer, lease *entity.Lease, step *entity.SagaStep) error {
+	event := entity.NewStepEvent(step)
+	// The write only applies while the lease is current and no newer lease
+	// has written the step
+	query := `
+		WITH updated AS (
+			UPDATE saga_steps
+			SET status = $2,
+				request_payload = $3,
+				response_payload = $4,
+				error_message = $5,
+				executed_at = $6,
+				compensated_at = $7,
+				retry_count = $8,
+				next_retry_at = $9,
+				wait_until = $10,
+				fencing_token = $11
+			WHERE id = $1
+			AND fencing_token <= $11
+			AND EXISTS (
+				SELECT 1 FROM saga_locks
+				WHERE saga_locks.saga_id = saga_steps.saga_id AND saga_locks.fencing_token = $11
+				FOR SHARE
+			)
+			RETURNING saga_id, step_name
+		)
+		INSERT INTO saga_events (saga_id, step_name, event_type, data, created_at)
+		SELECT saga_id, step_name, $12, $13::jsonb, $14::timestamptz FROM updated
+	`
+	result, err := db.ExecContext(ctx, query,
+		step.ID, string(step.Status),
+		nullJSON(step.RequestPayload), nullJSON(step.ResponsePayload), nullString(step.ErrorMessage),
+		step.ExecutedAt, step.CompensatedAt, step.RetryCount, step.NextRetryAt, step.WaitUntil,
+		lease.Token,
+		string(event.Type), []byte(event.Data), event.CreatedAt,
+	)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to update saga step", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errLeaseLost
+	}
+
+	return nil
+}
+
+// findUnlocked returns the oldest sagas in one of the statuses whose lease is
+// free or expired. Sagas parked until a retry timer fires are left to the
+// timer poller, like sagas waiting for a completion. The status filter is
+// served by idx_sagas_status.
+func (r *postgresSagaRepository) findUnlocked(ctx context.Context, statuses []string, limit int) ([]string, error) {
+	query := `
+		SELECT sagas.id
+		FROM sagas
+		LEFT JOIN saga_locks ON saga_locks.saga_id = sagas.id
+		WHERE sagas.status = ANY($1)
+		AND (saga_locks.saga_id IS NULL OR saga_locks.expires_at < NOW())
+		AND NOT EXISTS (
+			SELECT 1 FROM saga_timers
+			WHERE saga_timers.saga_id = sagas.id
+			AND saga_timers.kind IN ('STEP_RETRY', 'STEP_WAIT') AND saga_timers.fired_at IS NULL
+		)
+		ORDER BY sagas.created_at ASC
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(statuses), limit)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to find unlocked sagas", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, pErrors.E(pErrors.Internal, "failed to scan saga id", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to find unlocked sagas", err)
+	}
+
+	return ids, nil
+}
+
+// loadSteps loads the steps of a saga in execution order
+func (r *postgresSagaRepository) loadSteps(ctx context.Context, sagaID string) ([]*entity.SagaStep, error) {
+	query := `
+		SELECT
+			id,
+			saga_id,
+			step_name,
+			step_order,
+			step_group,
+			status,
+			idempotency_key,
+			request_payload,
+			response_payload,
+			error_message,
+			executed_at,
+			compensated_at,
+			retry_count,
+			next_retry_at,
+			wait_until
+		FROM saga_steps
+		WHERE saga_id = $1
+		ORDER BY step_order ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, sagaID)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga steps", err)
+	}
+	defer rows.Close()
+
+	var steps []*entity.SagaStep
+	for rows.Next() {
+		var step entity.SagaStep
+		var status string
+		var request, response []byte
+		var errorMessage sql.NullString
+		if err := rows.Scan(
+			&step.ID, &step.SagaID, &step.Name, &step.Order, &step.Group, &status, &step.IdempotencyKey,
+			&request, &response, &errorMessage,
+			&step.ExecutedAt, &step.CompensatedAt, &step.RetryCount, &step.NextRetryAt, &step.WaitUntil,
+		); err != nil {
+			return nil, pErrors.E(pErrors.Internal, "scan saga step", err)
+		}
+		step.Status = entity.StepStatus(status)
+		step.RequestPayload = request
+		step.ResponsePayload = response
+		step.ErrorMessage = errorMessage.String
+		steps = append(steps, &step)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga steps", err)
+	}
+
+	return steps, nil
+}
+
+// sagaColumns are the columns read by scanSaga
+const sagaColumns = `
+	sagas.id,
+	sagas.saga_type,
+	sagas.status,
+	sagas.payload,
+	sagas.error_message,
+	sagas.created_at,
+	sagas.updated_at,
+	sagas.completed_at,
+	sagas.cancel_requested_at,
+	sagas.cancel_reason,
+	sagas.parent_saga_id,
+	sagas.parent_step_name,
+	sagas.definition_version
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// execer is a *sql.DB or a *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// scanSaga reads a saga row selected with sagaColumns, without its steps
+func scanSaga(row rowScanner) (*entity.Saga, error) {
+	var saga entity.Saga
+	var status string
+	var payload []byte
+	var errorMessage, cancelReason, parentSagaID, parentStep sql.NullString
+	err := row.Scan(
+		&saga.ID, &saga.Type, &status, &payload, &errorMessage,
+		&saga.CreatedAt, &saga.UpdatedAt, &saga.CompletedAt,
+		&saga.CancelRequestedAt, &cancelReason,
+		&parentSagaID, &parentStep, &saga.DefinitionVersion,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	saga.Status = entity.SagaStatus(status)
+	saga.Payload = payload
+	saga.ErrorMessage = errorMessage.String
+	saga.CancelReason = cancelReason.String
+	saga.ParentSagaID = parentSagaID.String
+	saga.ParentStep = parentStep.String
+	return &saga, nil
+}
+
+// idempotencyTTL is how long a StartSaga idempotency key maps to its saga
+const idempotencyTTL = 24 * time.Hour
+
+// errLeaseLost is returned by fenced writes made with a stale lease
+var errLeaseLost = pErrors.E(pErrors.Conflict, "saga lease lost: stale fencing token", nil)
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullJSON stores empty payloads as NULL instead of an invalid JSONB value
+func nullJSON(b json.RawMessage) any {
+	if len(b) == 0 {
+		return nil
+	}
+	return []byte(b)
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+type postgresSignalRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresSignalRepository(db *sql.DB) repository.SignalRepository {
+	return &postgresSignalRepository{db: db}
+}
+
+const signalColumns = `saga_id, name, idempotency_key, payload, received_at, consumed_by, consumed_at`
+
+func (r *postgresSignalRepository) Record(ctx context.Context, signal *entity.Signal) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockSagaForEvent(ctx, tx, signal.SagaID); err != nil {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO saga_signals (saga_id, name, idempotency_key, payload, received_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (saga_id, idempotency_key) DO NOTHING
+	`
+	result, err := tx.ExecContext(ctx, query,
+		signal.SagaID, signal.Name, signal.IdempotencyKey, nullJSON(signal.Payload), signal.ReceivedAt,
+	)
+	if err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to insert saga signal", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return false, nil
+	}
+
+	if err := appendEvent(ctx, tx, entity.NewSignalEvent(signal)); err != nil {
+		return false, err
+	}
+
+	// Which steps wait for this signal is only known to the definition:
+	// every waiting step of the saga is woken up and checks for itself
+	query = `
+		UPDATE saga_timers SET fire_at = NOW()
+		WHERE saga_id = $1 AND kind = 'STEP_WAIT' AND fired_at IS NULL
+	`
+	if _, err := tx.ExecContext(ctx, query, signal.SagaID); err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to wake up saga", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return true, nil
+}
+
+func (r *postgresSignalRepository) FindByKey(ctx context.Context, sagaID, idempotencyKey string) (*entity.Signal, error) {
+	query := `SELECT ` + signalColumns + ` FROM saga_signals WHERE saga_id = $1 AND idempotency_key = $2`
+	signal, err := scanSignal(r.db.QueryRowContext(ctx, query, sagaID, idempotencyKey))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "query saga signal", err)
+	}
+	return signal, nil
+}
+
+func (r *postgresSignalRepository) Claim(ctx context.Context, sagaID, name, stepName string) (*entity.Signal, error) {
+	// A step resumed after a crash finds the signal it already consumed
+	query := `SELECT ` + signalColumns + ` FROM saga_signals WHERE saga_id = $1 AND consumed_by = $2`
+	signal, err := scanSignal(r.db.QueryRowContext(ctx, query, sagaID, stepName))
+	if err == nil {
+		return signal, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, pErrors.E(pErrors.Internal, "query saga signal", err)
+	}
+
+	query = `
+		UPDATE saga_signals SET consumed_by = $3, consumed_at = NOW()
+		WHERE id = (
+			SELECT id FROM saga_signals
+			WHERE saga_id = $1 AND name = $2 AND consumed_at IS NULL
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + signalColumns
+	signal, err = scanSignal(r.db.QueryRowContext(ctx, query, sagaID, name, stepName))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to claim saga signal", err)
+	}
+	return signal, nil
+}
+
+func (r *postgresSignalRepository) Release(ctx context.Context, sagaID, stepName string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE saga_signals SET consumed_by = NULL WHERE saga_id = $1 AND consumed_by = $2`, sagaID, stepName)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to release saga signal", err)
+	}
+	return nil
+}
+
+func scanSignal(row rowScanner) (*entity.Signal, error) {
+	var signal entity.Signal
+	var payload []byte
+	var consumedBy sql.NullString
+	var consumedAt sql.NullTime
+	err := row.Scan(&signal.SagaID, &signal.Name, &signal.IdempotencyKey, &payload, &signal.ReceivedAt, &consumedBy, &consumedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	signal.Payload = payload
+	signal.ConsumedBy = consumedBy.String
+	if consumedAt.Valid {
+		signal.ConsumedAt = &consumedAt.Time
+	}
+	return &signal, nil
+}
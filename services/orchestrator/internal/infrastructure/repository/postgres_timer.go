@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/domain/repository"
+)
+
+type postgresTimerRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTimerRepository(db *sql.DB) repository.TimerRepository {
+	return &postgresTimerRepository{db: db}
+}
+
+func (r *postgresTimerRepository) Schedule(ctx context.Context, timer *entity.Timer) error {
+	onConflict := `DO NOTHING`
+	if timer.Rearmable() {
+		onConflict = `DO UPDATE SET fire_at = EXCLUDED.fire_at, fired_at = NULL`
+	}
+
+	query := `
+		INSERT INTO saga_timers (id, saga_id, step_id, kind, fire_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (saga_id, step_id, kind) ` + onConflict
+
+	_, err := r.db.ExecContext(ctx, query,
+		timer.ID, timer.SagaID, nullString(timer.StepID), string(timer.Kind), timer.FireAt,
+	)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to schedule saga timer", err)
+	}
+	return nil
+}
+
+func (r *postgresTimerRepository) FireDue(ctx context.Context, limit int) ([]*entity.Timer, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	// Rows locked by another replica are skipped, not waited for
+	query := `
+		UPDATE saga_timers
+		SET fired_at = NOW()
+		WHERE id IN (
+			SELECT id FROM saga_timers
+			WHERE fired_at IS NULL AND fire_at <= NOW()
+			ORDER BY fire_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, saga_id, step_id, kind, fire_at, fired_at
+	`
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to fire saga timers", err)
+	}
+
+	var timers []*entity.Timer
+	for rows.Next() {
+		var timer entity.Timer
+		var stepID sql.NullString
+		var kind string
+		if err := rows.Scan(&timer.ID, &timer.SagaID, &stepID, &kind, &timer.FireAt, &timer.FiredAt); err != nil {
+			rows.Close()
+			return nil, pErrors.E(pErrors.Internal, "failed to scan saga timer", err)
+		}
+		timer.StepID = stepID.String
+		timer.Kind = entity.TimerKind(kind)
+		timers = append(timers, &timer)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to fire saga timers", err)
+	}
+
+	for _, timer := range timers {
+		if err := r.expire(ctx, tx, timer); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
+	return timers, nil
+}
+
+// expire requests cancellation of a saga whose deadline fired while it was
+// still running forward, and wakes the saga up if it is parked on a retry.
+// The executor compensates it at its next step boundary.
+func (r *postgresTimerRepository) expire(ctx context.Context, tx *sql.Tx, timer *entity.Timer) error {
+	var query string
+	var args []any
+	switch timer.Kind {
+	case entity.TimerKindSagaDeadline:
+		query = `
+			UPDATE sagas
+			SET cancel_requested_at = COALESCE(cancel_requested_at, NOW()),
+				cancel_reason = COALESCE(cancel_reason, 'saga deadline exceeded')
+			WHERE id = $1 AND status IN ('PENDING', 'EXECUTING')
+			RETURNING cancel_requested_at, cancel_requested_at = NOW(), cancel_reason
+		`
+		args = []any{timer.SagaID}
+	case entity.TimerKindStepDeadline:
+		query = `
+			UPDATE sagas
+			SET cancel_requested_at = COALESCE(sagas.cancel_requested_at, NOW()),
+				cancel_reason = COALESCE(sagas.cancel_reason, 'step ' || saga_steps.step_name || ' deadline exceeded')
+			FROM saga_steps
+			WHERE sagas.id = $1 AND sagas.status = 'EXECUTING'
+			AND saga_steps.id = $2 AND saga_steps.status IN ('PENDING', 'EXECUTING', 'WAITING')
+			RETURNING sagas.cancel_requested_at, sagas.cancel_requested_at = NOW(), sagas.cancel_reason
+		`
+		args = []any{timer.SagaID, timer.StepID}
+	default:
+		return nil
+	}
+
+	var requestedAt time.Time
+	var first bool
+	var reason string
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&requestedAt, &first, &reason)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to expire saga", err)
+	}
+
+	// A deadline fired after a cancellation keeps the first request
+	if first {
+		if err := appendEvent(ctx, tx, entity.NewCancelEvent(timer.SagaID, reason, requestedAt)); err != nil {
+			return err
+		}
+	}
+
+	return wakeUp(ctx, tx, timer.SagaID)
+}
+
+func (r *postgresTimerRepository) DeleteBySaga(ctx context.Context, sagaID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM saga_timers WHERE saga_id = $1`, sagaID)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to delete saga timers", err)
+	}
+	return nil
+}
+
+// wakeUp makes a pending retry or wait of the saga due now, so a parked
+// saga notices its cancellation without waiting for the backoff or timeout
+func wakeUp(ctx context.Context, tx *sql.Tx, sagaID string) error {
+	query := `
+		UPDATE saga_timers SET fire_at = NOW()
+		WHERE saga_id = $1 AND kind IN ('STEP_RETRY', 'STEP_WAIT') AND fired_at IS NULL
+	`
+	if _, err := tx.ExecContext(ctx, query, sagaID); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to wake up saga", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	httpPlatform "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/http"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+)
+
+// SagaIntervener is an operator action on a saga
+type SagaIntervener interface {
+	Execute(ctx context.Context, req dto.InterventionRequest) (*dto.SagaResponse, error)
+}
+
+// ProjectionChecker compares or repairs the stored state of a saga
+type ProjectionChecker interface {
+	Execute(ctx context.Context, sagaID string) (*dto.ProjectionResponse, error)
+}
+
+// DefinitionVersionLister reports the definition versions in use
+type DefinitionVersionLister interface {
+	Execute(ctx context.Context) (*dto.ListDefinitionVersionsResponse, error)
+}
+
+// DiagramRenderer draws a saga definition or a saga
+type DiagramRenderer interface {
+	Execute(ctx context.Context, req dto.RenderDiagramRequest) (*dto.DiagramResponse, error)
+}
+
+// AdminHandler exposes the operator actions of the admin gRPC service
+type AdminHandler struct {
+	retryCompensationUC SagaIntervener
+	markCompensatedUC   SagaIntervener
+	forceCompleteUC     SagaIntervener
+	verifyProjectionUC  ProjectionChecker
+	rebuildProjectionUC ProjectionChecker
+	listVersionsUC      DefinitionVersionLister
+	renderDiagramUC     DiagramRenderer
+}
+
+func NewAdminHandler(retryCompensationUC, markCompensatedUC, forceCompleteUC SagaIntervener, verifyProjectionUC, rebuildProjectionUC ProjectionChecker, listVersionsUC DefinitionVersionLister, renderDiagramUC DiagramRenderer) *AdminHandler {
+	return &AdminHandler{
+		retryCompensationUC: retryCompensationUC,
+		markCompensatedUC:   markCompensatedUC,
+		forceCompleteUC:     forceCompleteUC,
+		verifyProjectionUC:  verifyProjectionUC,
+		rebuildProjectionUC: rebuildProjectionUC,
+		listVersionsUC:      listVersionsUC,
+		renderDiagramUC:     renderDiagramUC,
+	}
+}
+
+// Register adds the admin endpoints to mux
+func (h *AdminHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/admin/sagas/{id}/retry-compensation", h.handle(h.retryCompensationUC))
+	mux.HandleFunc("POST /api/v1/admin/sagas/{id}/steps/{step}/mark-compensated", h.handle(h.markCompensatedUC))
+	mux.HandleFunc("POST /api/v1/admin/sagas/{id}/force-complete", h.handle(h.forceCompleteUC))
+	mux.HandleFunc("GET /api/v1/admin/sagas/{id}/projection", h.projection(h.verifyProjectionUC))
+	mux.HandleFunc("POST /api/v1/admin/sagas/{id}/projection/rebuild", h.projection(h.rebuildProjectionUC))
+	mux.HandleFunc("GET /api/v1/admin/definitions", h.listVersions)
+	mux.HandleFunc("GET /api/v1/admin/definitions/{type}/diagram", h.diagram)
+	mux.HandleFunc("GET /api/v1/admin/sagas/{id}/diagram", h.diagram)
+}
+
+type interventionRequest struct {
+	OperatorID string `json:"operator_id"`
+	Note       string `json:"note"`
+}
+
+func (h *AdminHandler) handle(uc SagaIntervener) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req interventionRequest
+		if err := decode(w, r, &req); err != nil {
+			httpPlatform.WriteError(w, err)
+			return
+		}
+
+		result, err := uc.Execute(r.Context(), dto.InterventionRequest{
+			SagaID:     r.PathValue("id"),
+			StepName:   r.PathValue("step"),
+			OperatorID: req.OperatorID,
+			Note:       req.Note,
+		})
+		if err != nil {
+			httpPlatform.WriteError(w, err)
+			return
+		}
+		httpPlatform.WriteJSON(w, http.StatusOK, toSagaResponse(result))
+	}
+}
+
+type projectionResponse struct {
+	Saga        sagaResponse `json:"saga"`
+	Differences []string     `json:"differences"`
+	Rebuilt     bool         `json:"rebuilt"`
+}
+
+func (h *AdminHandler) projection(uc ProjectionChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := uc.Execute(r.Context(), r.PathValue("id"))
+		if err != nil {
+			httpPlatform.WriteError(w, err)
+			return
+		}
+
+		differences := result.Differences
+		if differences == nil {
+			differences = []string{}
+		}
+		httpPlatform.WriteJSON(w, http.StatusOK, projectionResponse{
+			Saga:        toSagaResponse(result.Saga),
+			Differences: differences,
+			Rebuilt:     result.Rebuilt,
+		})
+	}
+}
+
+type definitionVersionResponse struct {
+	SagaType   string `json:"saga_type"`
+	Version    int    `json:"version"`
+	Registered bool   `json:"registered"`
+	Latest     bool   `json:"latest"`
+	Sagas      int    `json:"sagas"`
+}
+
+type listDefinitionVersionsResponse struct {
+	Versions []definitionVersionResponse `json:"versions"`
+}
+
+func (h *AdminHandler) listVersions(w http.ResponseWriter, r *http.Request) {
+	result, err := h.listVersionsUC.Execute(r.Context())
+	if err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+
+	resp := listDefinitionVersionsResponse{Versions: make([]definitionVersionResponse, len(result.Versions))}
+	for i, v := range result.Versions {
+		resp.Versions[i] = definitionVersionResponse{
+			SagaType:   v.SagaType,
+			Version:    v.Version,
+			Registered: v.Registered,
+			Latest:     v.Latest,
+			Sagas:      v.Sagas,
+		}
+	}
+	httpPlatform.WriteJSON(w, http.StatusOK, resp)
+}
+
+// diagram writes the diagram as plain text, ready to paste or pipe into a
+// renderer. The format and, for a definition, the version are query
+// parameters.
+func (h *AdminHandler) diagram(w http.ResponseWriter, r *http.Request) {
+	var version int
+	if v := r.URL.Query().Get("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			httpPlatform.WriteError(w, pErrors.E(pErrors.Invalid, "version must be a number", err))
+			return
+		}
+		version = n
+	}
+
+	result, err := h.renderDiagramUC.Execute(r.Context(), dto.RenderDiagramRequest{
+		SagaID:   r.PathValue("id"),
+		SagaType: r.PathValue("type"),
+		Version:  version,
+		Format:   r.URL.Query().Get("format"),
+	})
+	if err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(result.Diagram))
+}
@@ -0,0 +1,131 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	httpPlatform "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/http"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+)
+
+// ApprovalLister returns the work queue of approvers
+type ApprovalLister interface {
+	Execute(ctx context.Context, req dto.ListApprovalsRequest) (*dto.ListApprovalsResponse, error)
+}
+
+// ApprovalDecider approves or rejects an approval step
+type ApprovalDecider interface {
+	Execute(ctx context.Context, req dto.DecideApprovalRequest) (*dto.ApprovalDTO, error)
+}
+
+// ApprovalHandler exposes the approval gRPC service to approvers
+type ApprovalHandler struct {
+	listUC   ApprovalLister
+	decideUC ApprovalDecider
+}
+
+func NewApprovalHandler(listUC ApprovalLister, decideUC ApprovalDecider) *ApprovalHandler {
+	return &ApprovalHandler{listUC: listUC, decideUC: decideUC}
+}
+
+// Register adds the approval endpoints to mux
+func (h *ApprovalHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/approvals", h.listApprovals)
+	mux.HandleFunc("POST /api/v1/sagas/{id}/steps/{step}/approve", h.decide(true))
+	mux.HandleFunc("POST /api/v1/sagas/{id}/steps/{step}/reject", h.decide(false))
+}
+
+type decideApprovalRequest struct {
+	ApproverID string `json:"approver_id"`
+	Comment    string `json:"comment"`
+}
+
+type listApprovalsResponse struct {
+	Approvals []approvalResponse `json:"approvals"`
+}
+
+type approvalResponse struct {
+	SagaID      string          `json:"saga_id"`
+	StepName    string          `json:"step_name"`
+	SagaType    string          `json:"saga_type,omitempty"`
+	SagaPayload json.RawMessage `json:"saga_payload,omitempty"`
+	Status      string          `json:"status"`
+	ApproverID  string          `json:"approver_id,omitempty"`
+	Comment     string          `json:"comment,omitempty"`
+	RequestedAt time.Time       `json:"requested_at"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	DecidedAt   *time.Time      `json:"decided_at,omitempty"`
+}
+
+func (h *ApprovalHandler) listApprovals(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var pageSize int
+	if v := query.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			httpPlatform.WriteError(w, pErrors.E(pErrors.Invalid, "page_size must be a number", err))
+			return
+		}
+		pageSize = n
+	}
+
+	result, err := h.listUC.Execute(r.Context(), dto.ListApprovalsRequest{
+		SagaType: query.Get("saga_type"),
+		PageSize: pageSize,
+	})
+	if err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+
+	resp := listApprovalsResponse{Approvals: make([]approvalResponse, len(result.Approvals))}
+	for i := range result.Approvals {
+		resp.Approvals[i] = toApprovalResponse(&result.Approvals[i])
+	}
+	httpPlatform.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *ApprovalHandler) decide(approved bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req decideApprovalRequest
+		if err := decode(w, r, &req); err != nil {
+			httpPlatform.WriteError(w, err)
+			return
+		}
+
+		result, err := h.decideUC.Execute(r.Context(), dto.DecideApprovalRequest{
+			SagaID:     r.PathValue("id"),
+			StepName:   r.PathValue("step"),
+			Approved:   approved,
+			ApproverID: req.ApproverID,
+			Comment:    req.Comment,
+		})
+		if err != nil {
+			httpPlatform.WriteError(w, err)
+			return
+		}
+
+		// The saga resumes asynchronously
+		httpPlatform.WriteJSON(w, http.StatusAccepted, toApprovalResponse(result))
+	}
+}
+
+func toApprovalResponse(approval *dto.ApprovalDTO) approvalResponse {
+	return approvalResponse{
+		SagaID:      approval.SagaID,
+		StepName:    approval.StepName,
+		SagaType:    approval.SagaType,
+		SagaPayload: approval.SagaPayload,
+		Status:      approval.Status,
+		ApproverID:  approval.ApproverID,
+		Comment:     approval.Comment,
+		RequestedAt: approval.RequestedAt,
+		ExpiresAt:   approval.ExpiresAt,
+		DecidedAt:   approval.DecidedAt,
+	}
+}
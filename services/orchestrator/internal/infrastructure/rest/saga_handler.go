@@ -0,0 +1,466 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	httpPlatform "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/http"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/orchestrator/internal/application/dto"
+)
+
+// maxBodyBytes bounds request bodies, saga payloads are small
+const maxBodyBytes = 1 << 20
+
+type SagaStarter interface {
+	Execute(ctx context.Context, req dto.StartSagaRequest) (*dto.SagaResponse, error)
+}
+type SagaGetter interface {
+	Execute(ctx context.Context, sagaID string) (*dto.SagaResponse, error)
+}
+type SagaLister interface {
+	Execute(ctx context.Context, req dto.ListSagasRequest) (*dto.ListSagasResponse, error)
+}
+type SagaCanceller interface {
+	Execute(ctx context.Context, sagaID string, reason string) (*dto.SagaResponse, error)
+}
+type SagaTimelineGetter interface {
+	Execute(ctx context.Context, sagaID string) (*dto.SagaTimelineResponse, error)
+}
+type ChoreographyGetter interface {
+	Execute(ctx context.Context, correlationID string) (*dto.ChoreographyResponse, error)
+}
+type StepCompleter interface {
+	Execute(ctx context.Context, req dto.CompleteStepRequest) (*dto.CompleteStepResponse, error)
+}
+type SagaSignaller interface {
+	Execute(ctx context.Context, req dto.SignalSagaRequest) (*dto.SignalSagaResponse, error)
+}
+
+// SagaHandler is the REST/JSON front door of the orchestrator for external
+// clients. It calls the same use cases as the gRPC handler.
+type SagaHandler struct {
+	startUC        SagaStarter
+	getUC          SagaGetter
+	listUC         SagaLister
+	cancelUC       SagaCanceller
+	timelineUC     SagaTimelineGetter
+	choreographyUC ChoreographyGetter
+	completeUC     StepCompleter
+	signalUC       SagaSignaller
+}
+
+func NewSagaHandler(startUC SagaStarter, getUC SagaGetter, listUC SagaLister, cancelUC SagaCanceller, timelineUC SagaTimelineGetter, choreographyUC ChoreographyGetter, completeUC StepCompleter, signalUC SagaSignaller) *SagaHandler {
+	return &SagaHandler{
+		startUC:        startUC,
+		getUC:          getUC,
+		listUC:         listUC,
+		cancelUC:       cancelUC,
+		timelineUC:     timelineUC,
+		choreographyUC: choreographyUC,
+		completeUC:     completeUC,
+		signalUC:       signalUC,
+	}
+}
+
+// Register adds the saga endpoints to mux
+func (h *SagaHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /health", h.health)
+	mux.HandleFunc("POST /api/v1/sagas", h.startSaga)
+	mux.HandleFunc("GET /api/v1/sagas", h.listSagas)
+	mux.HandleFunc("GET /api/v1/sagas/{id}", h.getSaga)
+	mux.HandleFunc("POST /api/v1/sagas/{id}/cancel", h.cancelSaga)
+	mux.HandleFunc("GET /api/v1/sagas/{id}/events", h.getSagaTimeline)
+	mux.HandleFunc("POST /api/v1/sagas/{id}/steps/{step}/complete", h.completeStep)
+	mux.HandleFunc("POST /api/v1/sagas/{id}/signals/{name}", h.signalSaga)
+	mux.HandleFunc("GET /api/v1/choreographies/{id}", h.getChoreography)
+}
+
+type startSagaRequest struct {
+	IdempotencyKey string          `json:"idempotency_key"`
+	SagaType       string          `json:"saga_type"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+type cancelSagaRequest struct {
+	Reason string `json:"reason"`
+}
+
+type sagaStatusResponse struct {
+	SagaID string `json:"saga_id"`
+	Status string `json:"status"`
+}
+
+type listSagasResponse struct {
+	Sagas         []sagaResponse `json:"sagas"`
+	NextPageToken string         `json:"next_page_token,omitempty"`
+}
+
+type sagaResponse struct {
+	ID                string                 `json:"id"`
+	SagaType          string                 `json:"saga_type"`
+	Status            string                 `json:"status"`
+	Payload           json.RawMessage        `json:"payload,omitempty"`
+	ErrorMessage      string                 `json:"error_message,omitempty"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+	CompletedAt       *time.Time             `json:"completed_at,omitempty"`
+	Steps             []stepResponse         `json:"steps,omitempty"`
+	Interventions     []interventionResponse `json:"interventions,omitempty"`
+	ParentSagaID      string                 `json:"parent_saga_id,omitempty"`
+	ParentStep        string                 `json:"parent_step,omitempty"`
+	DefinitionVersion int                    `json:"definition_version"`
+}
+
+type stepResponse struct {
+	Name          string          `json:"name"`
+	StepOrder     int             `json:"step_order"`
+	StepGroup     int             `json:"step_group"`
+	Status        string          `json:"status"`
+	ErrorMessage  string          `json:"error_message,omitempty"`
+	RetryCount    int             `json:"retry_count"`
+	Request       json.RawMessage `json:"request,omitempty"`
+	Response      json.RawMessage `json:"response,omitempty"`
+	ExecutedAt    *time.Time      `json:"executed_at,omitempty"`
+	CompensatedAt *time.Time      `json:"compensated_at,omitempty"`
+	WaitUntil     *time.Time      `json:"wait_until,omitempty"`
+}
+
+type interventionResponse struct {
+	StepName       string    `json:"step_name,omitempty"`
+	Action         string    `json:"action"`
+	OperatorID     string    `json:"operator_id"`
+	Note           string    `json:"note,omitempty"`
+	PreviousStatus string    `json:"previous_status"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type timelineResponse struct {
+	SagaID string          `json:"saga_id"`
+	Events []eventResponse `json:"events"`
+}
+
+type eventResponse struct {
+	Sequence  int64           `json:"sequence"`
+	StepName  string          `json:"step_name,omitempty"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type completeStepRequest struct {
+	Payload      json.RawMessage `json:"payload"`
+	ErrorMessage string          `json:"error_message"`
+}
+
+type completeStepResponse struct {
+	SagaID    string `json:"saga_id"`
+	StepName  string `json:"step_name"`
+	Duplicate bool   `json:"duplicate"`
+}
+
+type signalSagaRequest struct {
+	Payload        json.RawMessage `json:"payload"`
+	IdempotencyKey string          `json:"idempotency_key"`
+}
+
+type signalSagaResponse struct {
+	SagaID     string    `json:"saga_id"`
+	SignalName string    `json:"signal_name"`
+	ReceivedAt time.Time `json:"received_at"`
+	Duplicate  bool      `json:"duplicate"`
+}
+
+type choreographyResponse struct {
+	CorrelationID string                      `json:"correlation_id"`
+	SagaType      string                      `json:"saga_type"`
+	Status        string                      `json:"status"`
+	ErrorMessage  string                      `json:"error_message,omitempty"`
+	StartedAt     time.Time                   `json:"started_at"`
+	UpdatedAt     time.Time                   `json:"updated_at"`
+	CompletedAt   *time.Time                  `json:"completed_at,omitempty"`
+	Events        []choreographyEventResponse `json:"events"`
+}
+
+type choreographyEventResponse struct {
+	Type        string          `json:"type"`
+	MessageID   string          `json:"message_id"`
+	Payload     json.RawMessage `json:"payload"`
+	PublishedAt time.Time       `json:"published_at"`
+	ReceivedAt  time.Time       `json:"received_at"`
+}
+
+func (h *SagaHandler) health(w http.ResponseWriter, r *http.Request) {
+	httpPlatform.WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *SagaHandler) startSaga(w http.ResponseWriter, r *http.Request) {
+	var req startSagaRequest
+	if err := decode(w, r, &req); err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+
+	// The Idempotency-Key header takes precedence over the body field
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey == "" {
+		idempotencyKey = req.IdempotencyKey
+	}
+
+	result, err := h.startUC.Execute(r.Context(), dto.StartSagaRequest{
+		IdempotencyKey: idempotencyKey,
+		SagaType:       req.SagaType,
+		Payload:        req.Payload,
+	})
+	if err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+
+	// The saga runs asynchronously, its progress is read with GET
+	w.Header().Set("Location", "/api/v1/sagas/"+result.ID)
+	httpPlatform.WriteJSON(w, http.StatusAccepted, sagaStatusResponse{
+		SagaID: result.ID,
+		Status: result.Status,
+	})
+}
+
+func (h *SagaHandler) getSaga(w http.ResponseWriter, r *http.Request) {
+	result, err := h.getUC.Execute(r.Context(), r.PathValue("id"))
+	if err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+	httpPlatform.WriteJSON(w, http.StatusOK, toSagaResponse(result))
+}
+
+func (h *SagaHandler) listSagas(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var pageSize int
+	if v := query.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			httpPlatform.WriteError(w, pErrors.E(pErrors.Invalid, "page_size must be a number", err))
+			return
+		}
+		pageSize = n
+	}
+
+	result, err := h.listUC.Execute(r.Context(), dto.ListSagasRequest{
+		SagaType:  query.Get("saga_type"),
+		Status:    query.Get("status"),
+		PageSize:  pageSize,
+		PageToken: query.Get("page_token"),
+	})
+	if err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+
+	resp := listSagasResponse{
+		Sagas:         make([]sagaResponse, len(result.Sagas)),
+		NextPageToken: result.NextPageToken,
+	}
+	for i := range result.Sagas {
+		resp.Sagas[i] = toSagaResponse(&result.Sagas[i])
+	}
+	httpPlatform.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *SagaHandler) cancelSaga(w http.ResponseWriter, r *http.Request) {
+	var req cancelSagaRequest
+	if r.ContentLength != 0 {
+		if err := decode(w, r, &req); err != nil {
+			httpPlatform.WriteError(w, err)
+			return
+		}
+	}
+
+	result, err := h.cancelUC.Execute(r.Context(), r.PathValue("id"), req.Reason)
+	if err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+	httpPlatform.WriteJSON(w, http.StatusAccepted, sagaStatusResponse{
+		SagaID: result.ID,
+		Status: result.Status,
+	})
+}
+
+func (h *SagaHandler) getSagaTimeline(w http.ResponseWriter, r *http.Request) {
+	result, err := h.timelineUC.Execute(r.Context(), r.PathValue("id"))
+	if err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+
+	resp := timelineResponse{
+		SagaID: result.SagaID,
+		Events: make([]eventResponse, len(result.Events)),
+	}
+	for i, event := range result.Events {
+		resp.Events[i] = eventResponse{
+			Sequence:  event.Sequence,
+			StepName:  event.StepName,
+			Type:      event.Type,
+			Data:      event.Data,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+	httpPlatform.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *SagaHandler) completeStep(w http.ResponseWriter, r *http.Request) {
+	var req completeStepRequest
+	if r.ContentLength != 0 {
+		if err := decode(w, r, &req); err != nil {
+			httpPlatform.WriteError(w, err)
+			return
+		}
+	}
+
+	result, err := h.completeUC.Execute(r.Context(), dto.CompleteStepRequest{
+		SagaID:       r.PathValue("id"),
+		StepName:     r.PathValue("step"),
+		Payload:      req.Payload,
+		ErrorMessage: req.ErrorMessage,
+		Source:       dto.CompletionSourceCallback,
+	})
+	if err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+
+	// The saga resumes asynchronously
+	httpPlatform.WriteJSON(w, http.StatusAccepted, completeStepResponse{
+		SagaID:    result.SagaID,
+		StepName:  result.StepName,
+		Duplicate: result.Duplicate,
+	})
+}
+
+func (h *SagaHandler) signalSaga(w http.ResponseWriter, r *http.Request) {
+	var req signalSagaRequest
+	if r.ContentLength != 0 {
+		if err := decode(w, r, &req); err != nil {
+			httpPlatform.WriteError(w, err)
+			return
+		}
+	}
+
+	// The Idempotency-Key header takes precedence over the body field
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey == "" {
+		idempotencyKey = req.IdempotencyKey
+	}
+
+	result, err := h.signalUC.Execute(r.Context(), dto.SignalSagaRequest{
+		SagaID:         r.PathValue("id"),
+		SignalName:     r.PathValue("name"),
+		IdempotencyKey: idempotencyKey,
+		Payload:        req.Payload,
+	})
+	if err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+
+	// The saga resumes asynchronously
+	httpPlatform.WriteJSON(w, http.StatusAccepted, signalSagaResponse{
+		SagaID:     result.SagaID,
+		SignalName: result.SignalName,
+		ReceivedAt: result.ReceivedAt,
+		Duplicate:  result.Duplicate,
+	})
+}
+
+func (h *SagaHandler) getChoreography(w http.ResponseWriter, r *http.Request) {
+	result, err := h.choreographyUC.Execute(r.Context(), r.PathValue("id"))
+	if err != nil {
+		httpPlatform.WriteError(w, err)
+		return
+	}
+
+	resp := choreographyResponse{
+		CorrelationID: result.CorrelationID,
+		SagaType:      result.SagaType,
+		Status:        result.Status,
+		ErrorMessage:  result.ErrorMessage,
+		StartedAt:     result.StartedAt,
+		UpdatedAt:     result.UpdatedAt,
+		CompletedAt:   result.CompletedAt,
+		Events:        make([]choreographyEventResponse, len(result.Events)),
+	}
+	for i, event := range result.Events {
+		resp.Events[i] = choreographyEventResponse{
+			Type:        event.Type,
+			MessageID:   event.MessageID,
+			Payload:     event.Payload,
+			PublishedAt: event.PublishedAt,
+			ReceivedAt:  event.ReceivedAt,
+		}
+	}
+	httpPlatform.WriteJSON(w, http.StatusOK, resp)
+}
+
+// decode reads a JSON request body into v
+func decode(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return pErrors.E(pErrors.Invalid, "invalid JSON body: "+err.Error(), err)
+	}
+	return nil
+}
+
+// toSagaResponse converts DTO to JSON response
+func toSagaResponse(saga *dto.SagaResponse) sagaResponse {
+	steps := make([]stepResponse, len(saga.Steps))
+	for i, step := range saga.Steps {
+		steps[i] = stepResponse{
+			Name:          step.Name,
+			StepOrder:     step.Order,
+			StepGroup:     step.Group,
+			Status:        step.Status,
+			ErrorMessage:  step.ErrorMessage,
+			RetryCount:    step.RetryCount,
+			Request:       step.Request,
+			Response:      step.Response,
+			ExecutedAt:    step.ExecutedAt,
+			CompensatedAt: step.CompensatedAt,
+			WaitUntil:     step.WaitUntil,
+		}
+	}
+
+	interventions := make([]interventionResponse, len(saga.Interventions))
+	for i, intervention := range saga.Interventions {
+		interventions[i] = interventionResponse{
+			StepName:       intervention.StepName,
+			Action:         intervention.Action,
+			OperatorID:     intervention.OperatorID,
+			Note:           intervention.Note,
+			PreviousStatus: intervention.PreviousStatus,
+			CreatedAt:      intervention.CreatedAt,
+		}
+	}
+
+	return sagaResponse{
+		ID:                saga.ID,
+		SagaType:          saga.SagaType,
+		Status:            saga.Status,
+		Payload:           saga.Payload,
+		ErrorMessage:      saga.ErrorMessage,
+		CreatedAt:         saga.CreatedAt,
+		UpdatedAt:         saga.UpdatedAt,
+		CompletedAt:       saga.CompletedAt,
+		Steps:             steps,
+		Interventions:     interventions,
+		ParentSagaID:      saga.ParentSagaID,
+		ParentStep:        saga.ParentStep,
+		DefinitionVersion: saga.DefinitionVersion,
+	}
+}
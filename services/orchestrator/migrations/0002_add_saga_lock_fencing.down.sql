@@ -0,0 +1,3 @@
+ALTER TABLE saga_steps DROP COLUMN IF EXISTS fencing_token;
+ALTER TABLE saga_locks DROP COLUMN IF EXISTS fencing_token;
+DROP SEQUENCE IF EXISTS saga_lock_fencing_seq;
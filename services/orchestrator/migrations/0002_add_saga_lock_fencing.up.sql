@@ -0,0 +1,11 @@
+-- Fencing tokens: every lease acquisition gets a new, strictly increasing token.
+-- Writes to sagas/saga_steps are only applied while the writer's token is the
+-- current one, so a paused-then-resumed stale owner cannot overwrite progress.
+CREATE SEQUENCE saga_lock_fencing_seq;
+
+ALTER TABLE saga_locks
+    ADD COLUMN fencing_token BIGINT NOT NULL DEFAULT nextval('saga_lock_fencing_seq');
+
+-- Token of the last lease that wrote the step
+ALTER TABLE saga_steps
+    ADD COLUMN fencing_token BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_sagas_created;
+
+ALTER TABLE sagas DROP COLUMN IF EXISTS cancel_reason;
+ALTER TABLE sagas DROP COLUMN IF EXISTS cancel_requested_at;
@@ -0,0 +1,7 @@
+-- Cancellation requested through the API. The owner of the saga lease picks
+-- it up before the next step and compensates the steps that already succeeded.
+ALTER TABLE sagas ADD COLUMN cancel_requested_at TIMESTAMPTZ;
+ALTER TABLE sagas ADD COLUMN cancel_reason TEXT;
+
+-- Newest-first pagination for ListSagas
+CREATE INDEX idx_sagas_created ON sagas(created_at DESC, id DESC);
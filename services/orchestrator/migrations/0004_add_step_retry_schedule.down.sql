@@ -0,0 +1 @@
+ALTER TABLE saga_steps DROP COLUMN IF EXISTS next_retry_at;
@@ -0,0 +1,3 @@
+-- When the failed call of a step is sent again. Persisted with retry_count so
+-- a resumed saga continues the backoff schedule instead of restarting it.
+ALTER TABLE saga_steps ADD COLUMN next_retry_at TIMESTAMPTZ;
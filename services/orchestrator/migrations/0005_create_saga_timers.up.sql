@@ -0,0 +1,19 @@
+-- Durable timers: step retries, step deadlines and saga deadlines.
+-- Due timers are claimed with FOR UPDATE SKIP LOCKED, so each fires once
+-- across replicas.
+CREATE TABLE saga_timers (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    saga_id         UUID NOT NULL REFERENCES sagas(id) ON DELETE CASCADE,
+    step_id         UUID REFERENCES saga_steps(id) ON DELETE CASCADE,  -- NULL for saga timers
+    kind            VARCHAR(20) NOT NULL,
+    fire_at         TIMESTAMPTZ NOT NULL,
+    fired_at        TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_timer_kind CHECK (kind IN (
+        'STEP_RETRY', 'STEP_DEADLINE', 'SAGA_DEADLINE'
+    )),
+    UNIQUE NULLS NOT DISTINCT (saga_id, step_id, kind)
+);
+
+CREATE INDEX idx_saga_timers_due ON saga_timers(fire_at) WHERE fired_at IS NULL;
@@ -0,0 +1,5 @@
+-- Steps of the same group run concurrently. step_order stays the unique
+-- position of the step in its definition; existing steps each form a group.
+ALTER TABLE saga_steps ADD COLUMN step_group INT;
+UPDATE saga_steps SET step_group = step_order;
+ALTER TABLE saga_steps ALTER COLUMN step_group SET NOT NULL;
@@ -0,0 +1,15 @@
+-- A SKIPPED step never ran: no earlier status says so, and turning it into
+-- SUCCEEDED would compensate effects that never happened
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM saga_steps WHERE status = 'SKIPPED') THEN
+        RAISE EXCEPTION 'saga_steps has SKIPPED steps, which cannot be rolled back';
+    END IF;
+END;
+$$;
+
+ALTER TABLE saga_steps DROP CONSTRAINT valid_step_status;
+ALTER TABLE saga_steps ADD CONSTRAINT valid_step_status CHECK (status IN (
+    'PENDING', 'EXECUTING', 'SUCCEEDED', 'FAILED',
+    'COMPENSATING', 'COMPENSATED', 'COMPENSATION_FAILED'
+));
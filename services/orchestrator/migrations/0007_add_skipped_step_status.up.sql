@@ -0,0 +1,6 @@
+-- Steps whose condition did not hold are SKIPPED: never called nor compensated
+ALTER TABLE saga_steps DROP CONSTRAINT valid_step_status;
+ALTER TABLE saga_steps ADD CONSTRAINT valid_step_status CHECK (status IN (
+    'PENDING', 'EXECUTING', 'SUCCEEDED', 'SKIPPED', 'FAILED',
+    'COMPENSATING', 'COMPENSATED', 'COMPENSATION_FAILED'
+));
@@ -0,0 +1,10 @@
+UPDATE sagas SET status = 'EXECUTING' WHERE status = 'FORWARD_RECOVERING';
+
+DROP INDEX idx_sagas_status;
+CREATE INDEX idx_sagas_status ON sagas(status) WHERE status IN ('PENDING', 'EXECUTING', 'COMPENSATING');
+
+ALTER TABLE sagas DROP CONSTRAINT valid_status;
+ALTER TABLE sagas ADD CONSTRAINT valid_status CHECK (status IN (
+    'PENDING', 'EXECUTING', 'COMPLETED',
+    'COMPENSATING', 'COMPENSATED', 'FAILED'
+));
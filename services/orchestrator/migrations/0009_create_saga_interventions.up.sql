@@ -0,0 +1,17 @@
+-- Audit trail of operator actions on sagas the executor could not finish
+CREATE TABLE saga_interventions (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    saga_id         UUID NOT NULL REFERENCES sagas(id) ON DELETE CASCADE,
+    step_name       VARCHAR(100),                   -- NULL for actions on the whole saga
+    action          VARCHAR(30) NOT NULL,
+    operator_id     VARCHAR(100) NOT NULL,
+    note            TEXT,
+    previous_status VARCHAR(20) NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_intervention_action CHECK (action IN (
+        'RETRY_COMPENSATION', 'MARK_COMPENSATED', 'FORCE_COMPLETE'
+    ))
+);
+
+CREATE INDEX idx_saga_interventions_saga ON saga_interventions(saga_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saga_events;
+DROP FUNCTION IF EXISTS reject_saga_event_change();
@@ -0,0 +1,23 @@
+-- Append-only log of saga transitions. Each event is written in the same
+-- transaction as the change it records and carries the resulting state.
+CREATE TABLE saga_events (
+    sequence        BIGSERIAL PRIMARY KEY,          -- total order of the log
+    saga_id         UUID NOT NULL REFERENCES sagas(id),
+    step_name       VARCHAR(100),                   -- NULL for saga events
+    event_type      VARCHAR(50) NOT NULL,           -- e.g., 'StepSucceeded'
+    data            JSONB NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_saga_events_saga ON saga_events(saga_id, sequence);
+
+-- Events are never changed nor removed
+CREATE FUNCTION reject_saga_event_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'saga_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER saga_events_append_only
+    BEFORE UPDATE OR DELETE ON saga_events
+    FOR EACH ROW EXECUTE FUNCTION reject_saga_event_change();
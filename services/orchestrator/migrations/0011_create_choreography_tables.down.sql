@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS choreography_events;
+DROP TABLE IF EXISTS choreographies;
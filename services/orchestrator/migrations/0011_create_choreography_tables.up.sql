@@ -0,0 +1,30 @@
+-- Progress of choreographed saga runs, observed from the domain events the
+-- services publish. A run is identified by the correlation id of its events.
+CREATE TABLE choreographies (
+    correlation_id  VARCHAR(100) PRIMARY KEY,
+    saga_type       VARCHAR(100) NOT NULL,
+    status          VARCHAR(20) NOT NULL,
+    error_message   TEXT,
+    started_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at    TIMESTAMPTZ,
+    version         BIGINT NOT NULL DEFAULT 0,      -- optimistic concurrency
+
+    CONSTRAINT valid_choreography_status CHECK (status IN (
+        'EXECUTING', 'COMPLETED', 'COMPENSATING', 'COMPENSATED'
+    ))
+);
+
+CREATE INDEX idx_choreographies_status ON choreographies(status, updated_at);
+
+-- Each event type is recorded once per run, redeliveries are ignored
+CREATE TABLE choreography_events (
+    correlation_id  VARCHAR(100) NOT NULL REFERENCES choreographies(correlation_id) ON DELETE CASCADE,
+    event_type      VARCHAR(100) NOT NULL,          -- topic, e.g., 'InventoryReserved'
+    message_id      VARCHAR(100) NOT NULL,
+    payload         JSONB NOT NULL,
+    published_at    TIMESTAMPTZ NOT NULL,
+    received_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (correlation_id, event_type)
+);
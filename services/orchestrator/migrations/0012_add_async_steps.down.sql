@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS saga_step_completions;
+
+DELETE FROM saga_timers WHERE kind = 'STEP_WAIT';
+ALTER TABLE saga_timers DROP CONSTRAINT valid_timer_kind;
+ALTER TABLE saga_timers ADD CONSTRAINT valid_timer_kind CHECK (kind IN (
+    'STEP_RETRY', 'STEP_DEADLINE', 'SAGA_DEADLINE'
+));
+
+ALTER TABLE saga_steps DROP COLUMN wait_until;
+
+UPDATE saga_steps SET status = 'EXECUTING' WHERE status = 'WAITING';
+UPDATE saga_steps SET status = 'FAILED' WHERE status = 'TIMED_OUT';
+ALTER TABLE saga_steps DROP CONSTRAINT valid_step_status;
+ALTER TABLE saga_steps ADD CONSTRAINT valid_step_status CHECK (status IN (
+    'PENDING', 'EXECUTING', 'SUCCEEDED', 'SKIPPED', 'FAILED',
+    'COMPENSATING', 'COMPENSATED', 'COMPENSATION_FAILED'
+));
@@ -0,0 +1,27 @@
+-- Asynchronous steps issue a command, then wait for its completion
+ALTER TABLE saga_steps DROP CONSTRAINT valid_step_status;
+ALTER TABLE saga_steps ADD CONSTRAINT valid_step_status CHECK (status IN (
+    'PENDING', 'EXECUTING', 'WAITING', 'TIMED_OUT', 'SUCCEEDED', 'SKIPPED', 'FAILED',
+    'COMPENSATING', 'COMPENSATED', 'COMPENSATION_FAILED'
+));
+
+-- When a WAITING step times out
+ALTER TABLE saga_steps ADD COLUMN wait_until TIMESTAMPTZ;
+
+ALTER TABLE saga_timers DROP CONSTRAINT valid_timer_kind;
+ALTER TABLE saga_timers ADD CONSTRAINT valid_timer_kind CHECK (kind IN (
+    'STEP_RETRY', 'STEP_WAIT', 'STEP_DEADLINE', 'SAGA_DEADLINE'
+));
+
+-- Completions reported by callbacks or events, correlated by saga id and
+-- step name. The first one received for a step wins.
+CREATE TABLE saga_step_completions (
+    saga_id         UUID NOT NULL REFERENCES sagas(id) ON DELETE CASCADE,
+    step_name       VARCHAR(100) NOT NULL,
+    payload         JSONB,
+    error_message   TEXT,                           -- NULL when the command succeeded
+    source          VARCHAR(20) NOT NULL,           -- 'callback' or 'event'
+    received_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (saga_id, step_name)
+);
@@ -0,0 +1,17 @@
+-- Signals pushed into running sagas by external systems, in the order they
+-- were received. Deliveries are deduplicated by their idempotency key; each
+-- signal is consumed by at most one step waiting for its name.
+CREATE TABLE saga_signals (
+    id              BIGSERIAL PRIMARY KEY,
+    saga_id         UUID NOT NULL REFERENCES sagas(id) ON DELETE CASCADE,
+    name            VARCHAR(100) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    payload         JSONB,
+    received_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    consumed_by     VARCHAR(100),                   -- step the signal became the output of
+    consumed_at     TIMESTAMPTZ,                    -- stays set once the step let it go
+
+    CONSTRAINT unique_signal_key UNIQUE (saga_id, idempotency_key)
+);
+
+CREATE INDEX idx_saga_signals_unconsumed ON saga_signals(saga_id, name, id) WHERE consumed_at IS NULL;
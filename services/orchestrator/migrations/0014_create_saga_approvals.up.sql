@@ -0,0 +1,18 @@
+-- Human decisions requested by approval steps, the work queue of approvers
+CREATE TABLE saga_approvals (
+    saga_id         UUID NOT NULL REFERENCES sagas(id) ON DELETE CASCADE,
+    step_name       VARCHAR(100) NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    approver_id     VARCHAR(100),                   -- NULL until decided, and when expired
+    comment         TEXT,
+    requested_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at      TIMESTAMPTZ NOT NULL,
+    decided_at      TIMESTAMPTZ,
+
+    PRIMARY KEY (saga_id, step_name),
+    CONSTRAINT valid_approval_status CHECK (status IN (
+        'PENDING', 'APPROVED', 'REJECTED', 'EXPIRED'
+    ))
+);
+
+CREATE INDEX idx_saga_approvals_pending ON saga_approvals(requested_at) WHERE status = 'PENDING';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_sagas_parent;
+
+ALTER TABLE sagas DROP COLUMN IF EXISTS parent_step_name;
+ALTER TABLE sagas DROP COLUMN IF EXISTS parent_saga_id;
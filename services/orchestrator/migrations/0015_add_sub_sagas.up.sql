@@ -0,0 +1,7 @@
+-- A sub-saga is started by a step of its parent, which waits for it and
+-- compensates it with the rest of the parent. A step starts at most one child.
+ALTER TABLE sagas ADD COLUMN parent_saga_id UUID REFERENCES sagas(id);
+ALTER TABLE sagas ADD COLUMN parent_step_name VARCHAR(100);
+
+CREATE UNIQUE INDEX idx_sagas_parent ON sagas(parent_saga_id, parent_step_name)
+    WHERE parent_saga_id IS NOT NULL;
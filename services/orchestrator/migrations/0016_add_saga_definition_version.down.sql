@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_sagas_definition_version;
+
+ALTER TABLE sagas DROP CONSTRAINT IF EXISTS valid_definition_version;
+ALTER TABLE sagas DROP COLUMN IF EXISTS definition_version;
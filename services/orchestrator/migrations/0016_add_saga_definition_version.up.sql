@@ -0,0 +1,9 @@
+-- The version of the definition a saga runs against. Sagas created before
+-- definitions were versioned run version 1.
+ALTER TABLE sagas ADD COLUMN definition_version INT NOT NULL DEFAULT 1;
+ALTER TABLE sagas ADD CONSTRAINT valid_definition_version CHECK (definition_version >= 1);
+
+-- Versions still in use: sagas that may run again, FAILED ones included as
+-- an operator can resume their compensation
+CREATE INDEX idx_sagas_definition_version ON sagas(saga_type, definition_version)
+    WHERE status NOT IN ('COMPLETED', 'COMPENSATED');
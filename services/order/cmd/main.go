@@ -9,6 +9,7 @@ import (
 	grpcServer "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/grpc"
 	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
 	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/internal/application/usecase"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/internal/infrastructure/choreography"
 	grpcHandler "github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/internal/infrastructure/grpc"
 	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/internal/infrastructure/repository"
 	"google.golang.org/grpc"
@@ -28,12 +29,24 @@ func main() {
 		log.Fatalf("failed to create app: %v", err)
 	}
 
-	repo := repository.NewPostgresOrderRepository(app.DB)
+	// The order events start the choreography, see choreography.OrderHandler
+	repo := repository.NewPostgresOrderRepository(app.DB, app.Cfg.Saga.Mode == config.SagaModeChoreography)
 	ucReserve := usecase.NewCreateOrderUseCase(repo, app.Log)
 	ucRelease := usecase.NewCancelOrderUseCase(repo, app.Log)
-	handler := grpcHandler.NewOrderHandler(ucReserve, ucRelease)
+	ucConfirm := usecase.NewConfirmOrderUseCase(repo, app.Log)
+	handler := grpcHandler.NewOrderHandler(ucReserve, ucRelease, ucConfirm)
 	handler.RegisterOrderServiceServer(app.GRPC.Instance())
 
+	if err := app.EnableMessaging(); err != nil {
+		app.Log.Fatal().Err(err).Msg("failed to enable messaging")
+	}
+	if app.Cfg.Saga.Mode == config.SagaModeChoreography {
+		if err := app.EnableOutbox(); err != nil {
+			app.Log.Fatal().Err(err).Msg("failed to enable outbox")
+		}
+		choreography.NewOrderHandler(ucRelease, ucConfirm, app.Log).Register(app)
+	}
+
 	if err := app.Run(); err != nil {
 		app.Log.Fatal().Err(err).Msg("failed to run app")
 	}
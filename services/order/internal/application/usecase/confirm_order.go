@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/internal/application/dto"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/internal/domain/entity"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/internal/domain/repository"
+)
+
+// ConfirmOrderUseCase confirms an order once it has been paid. Confirming a
+// confirmed order returns it unchanged, so a retried call is harmless.
+type ConfirmOrderUseCase struct {
+	repo   repository.OrderRepository
+	logger *logger.Logger
+}
+
+func NewConfirmOrderUseCase(repo repository.OrderRepository, logger *logger.Logger) *ConfirmOrderUseCase {
+	return &ConfirmOrderUseCase{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (uc *ConfirmOrderUseCase) Execute(ctx context.Context, orderID string) (*dto.OrderResponse, error) {
+	order, err := uc.repo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch order.Status {
+	case entity.OrderStatusConfirmed:
+		uc.logger.Info().
+			Str("order_id", orderID).
+			Msg("Order already confirmed")
+		return uc.toDTO(order), nil
+	case entity.OrderStatusCreated:
+	default:
+		return nil, pErrors.E(pErrors.Conflict, "order is "+string(order.Status)+", it cannot be confirmed", nil)
+	}
+
+	order.Confirm()
+
+	if err := uc.repo.Update(ctx, order); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info().
+		Str("order_id", orderID).
+		Msg("Order confirmed successfully")
+
+	return uc.toDTO(order), nil
+}
+
+func (uc *ConfirmOrderUseCase) toDTO(order *entity.Order) *dto.OrderResponse {
+	return &dto.OrderResponse{
+		ID:          order.ID,
+		CustomerID:  order.CustomerID,
+		Status:      string(order.Status),
+		TotalAmount: order.TotalAmount,
+		CreatedAt:   order.CreatedAt,
+	}
+}
@@ -0,0 +1,80 @@
+package choreography
+
+import (
+	"context"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/messaging"
+	eventsv1 "github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/gen/proto/events/v1"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/internal/application/dto"
+)
+
+// Group is the consumer group of the order service
+const Group = "order-service"
+
+type OrderCanceller interface {
+	Execute(ctx context.Context, orderID string, idempotencyKey string) (*dto.OrderResponse, error)
+}
+type OrderConfirmer interface {
+	Execute(ctx context.Context, orderID string) (*dto.OrderResponse, error)
+}
+
+// OrderHandler ends the order saga when it runs as a choreography: the order
+// is confirmed once paid, and cancelled when its stock or payment failed.
+// The order events are then published from the outbox by the repository.
+type OrderHandler struct {
+	cancelUC  OrderCanceller
+	confirmUC OrderConfirmer
+	logger    *logger.Logger
+}
+
+func NewOrderHandler(cancelUC OrderCanceller, confirmUC OrderConfirmer, log *logger.Logger) *OrderHandler {
+	return &OrderHandler{cancelUC: cancelUC, confirmUC: confirmUC, logger: log}
+}
+
+// Register subscribes the handler to the events it reacts to
+func (h *OrderHandler) Register(s messaging.Consumer) {
+	s.Subscribe("PaymentProcessed", Group, h.paymentProcessed)
+	s.Subscribe("PaymentFailed", Group, h.paymentFailed)
+	s.Subscribe("InventoryReservationFailed", Group, h.reservationFailed)
+}
+
+func (h *OrderHandler) paymentProcessed(ctx context.Context, msg *messaging.Message) error {
+	var event eventsv1.PaymentProcessed
+	if err := messaging.Decode(msg, &event); err != nil {
+		return err
+	}
+
+	if _, err := h.confirmUC.Execute(ctx, event.OrderId); err != nil {
+		return messaging.RejectPermanent(err)
+	}
+	return nil
+}
+
+func (h *OrderHandler) paymentFailed(ctx context.Context, msg *messaging.Message) error {
+	var event eventsv1.PaymentFailed
+	if err := messaging.Decode(msg, &event); err != nil {
+		return err
+	}
+	return h.cancel(ctx, event.OrderId, event.Reason)
+}
+
+func (h *OrderHandler) reservationFailed(ctx context.Context, msg *messaging.Message) error {
+	var event eventsv1.InventoryReservationFailed
+	if err := messaging.Decode(msg, &event); err != nil {
+		return err
+	}
+	return h.cancel(ctx, event.OrderId, event.Reason)
+}
+
+func (h *OrderHandler) cancel(ctx context.Context, orderID, reason string) error {
+	h.logger.InfoWithTrace(ctx).
+		Str("order_id", orderID).
+		Str("reason", reason).
+		Msg("Cancelling order of failed saga")
+
+	if _, err := h.cancelUC.Execute(ctx, orderID, "choreography:cancel:"+orderID); err != nil {
+		return messaging.RejectPermanent(err)
+	}
+	return nil
+}
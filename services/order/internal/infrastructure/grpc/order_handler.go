@@ -15,17 +15,22 @@ type OrderCreator interface {
 type OrderCanceller interface {
 	Execute(ctx context.Context, orderID string, idempotencyKey string) (*dto.OrderResponse, error)
 }
+type OrderConfirmer interface {
+	Execute(ctx context.Context, orderID string) (*dto.OrderResponse, error)
+}
 
 type OrderHandler struct {
 	pb.UnimplementedOrderServiceServer
-	createUC OrderCreator
-	cancelUC OrderCanceller
+	createUC  OrderCreator
+	cancelUC  OrderCanceller
+	confirmUC OrderConfirmer
 }
 
-func NewOrderHandler(createUC OrderCreator, cancelUC OrderCanceller) *OrderHandler {
+func NewOrderHandler(createUC OrderCreator, cancelUC OrderCanceller, confirmUC OrderConfirmer) *OrderHandler {
 	return &OrderHandler{
-		createUC: createUC,
-		cancelUC: cancelUC,
+		createUC:  createUC,
+		cancelUC:  cancelUC,
+		confirmUC: confirmUC,
 	}
 }
 
@@ -75,3 +80,14 @@ func (h *OrderHandler) CancelOrder(ctx context.Context, req *pb.CancelOrderReque
 		Status:  result.Status,
 	}, nil
 }
+
+func (h *OrderHandler) ConfirmOrder(ctx context.Context, req *pb.ConfirmOrderRequest) (*pb.ConfirmOrderResponse, error) {
+	result, err := h.confirmUC.Execute(ctx, req.OrderId)
+	if err != nil {
+		return nil, grpcPlatform.ToStatus(err)
+	}
+	return &pb.ConfirmOrderResponse{
+		OrderId: result.ID,
+		Status:  result.Status,
+	}, nil
+}
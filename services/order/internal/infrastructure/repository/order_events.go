@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/outbox"
+	eventsv1 "github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/gen/proto/events/v1"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/internal/domain/entity"
+	"google.golang.org/protobuf/proto"
+)
+
+const orderAggregate = "order"
+
+// orderEvent is the domain event of the current status of an order
+func orderEvent(order *entity.Order) (*outbox.Message, error) {
+	var event proto.Message
+	switch order.Status {
+	case entity.OrderStatusCreated:
+		items := make([]*eventsv1.OrderItem, len(order.Items))
+		for i, item := range order.Items {
+			items[i] = &eventsv1.OrderItem{
+				ProductId: item.ProductID,
+				Quantity:  int32(item.Quantity),
+				Price:     item.Price,
+			}
+		}
+		event = &eventsv1.OrderCreated{
+			OrderId:     order.ID,
+			CustomerId:  order.CustomerID,
+			TotalAmount: order.TotalAmount,
+			Items:       items,
+		}
+	case entity.OrderStatusConfirmed:
+		event = &eventsv1.OrderConfirmed{OrderId: order.ID}
+	case entity.OrderStatusCancelled:
+		event = &eventsv1.OrderCancelled{OrderId: order.ID}
+	default:
+		return nil, nil
+	}
+
+	return outbox.NewEvent(orderAggregate, order.ID, event)
+}
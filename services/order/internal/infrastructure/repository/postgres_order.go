@@ -8,6 +8,7 @@ import (
 	"time"
 
 	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/outbox"
 	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/internal/domain/entity"
 	domainRepo "github.com/dandirahmadani19/distributed-saga-orchestrator/services/order/internal/domain/repository"
 	"github.com/google/uuid"
@@ -15,10 +16,13 @@ import (
 
 type postgresOrderRepository struct {
 	db *sql.DB
+	// publishEvents writes the domain events of the orders to the outbox,
+	// for the services that react to them in choreography mode
+	publishEvents bool
 }
 
-func NewPostgresOrderRepository(db *sql.DB) domainRepo.OrderRepository {
-	return &postgresOrderRepository{db: db}
+func NewPostgresOrderRepository(db *sql.DB, publishEvents bool) domainRepo.OrderRepository {
+	return &postgresOrderRepository{db: db, publishEvents: publishEvents}
 }
 
 func (r *postgresOrderRepository) Create(ctx context.Context, order *entity.Order, idempotencyKey string) (*entity.Order, error) {
@@ -72,6 +76,10 @@ func (r *postgresOrderRepository) Create(ctx context.Context, order *entity.Orde
 		return nil, pErrors.E(pErrors.Internal, "failed to insert idempotency key", err)
 	}
 
+	if err := r.writeEvent(ctx, tx, order); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, pErrors.E(pErrors.Internal, "failed to commit transaction", err)
 	}
@@ -162,15 +170,42 @@ func (r *postgresOrderRepository) FindByID(ctx context.Context, id string) (*ent
 }
 
 func (r *postgresOrderRepository) Update(ctx context.Context, order *entity.Order) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return pErrors.E(pErrors.Internal, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE orders
 		SET status = $1, updated_at = $2
 		WHERE id = $3
 	`
-	_, err := r.db.ExecContext(ctx, query, order.Status, order.UpdatedAt, order.ID)
+	_, err = tx.ExecContext(ctx, query, order.Status, order.UpdatedAt, order.ID)
 	if err != nil {
 		return pErrors.E(pErrors.Internal, "failed to update order", err)
 	}
 
+	if err := r.writeEvent(ctx, tx, order); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return pErrors.E(pErrors.Internal, "failed to commit transaction", err)
+	}
 	return nil
 }
+
+// writeEvent stores the event of the new status of an order in the outbox,
+// published by the outbox relay once committed
+func (r *postgresOrderRepository) writeEvent(ctx context.Context, tx *sql.Tx, order *entity.Order) error {
+	if !r.publishEvents {
+		return nil
+	}
+
+	event, err := orderEvent(order)
+	if err != nil || event == nil {
+		return err
+	}
+	return outbox.Write(ctx, tx, event)
+}
@@ -0,0 +1,83 @@
+package choreography
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/messaging"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/outbox"
+	eventsv1 "github.com/dandirahmadani19/distributed-saga-orchestrator/services/payment/gen/proto/events/v1"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/payment/internal/application/dto"
+	"google.golang.org/protobuf/proto"
+)
+
+// Group is the consumer group of the payment service
+const Group = "payment-service"
+
+const paymentAggregate = "payment"
+
+type PaymentProcessor interface {
+	Execute(ctx context.Context, req dto.CreatePaymentRequest) (*dto.PaymentResponse, error)
+}
+
+// PaymentHandler takes part in the order saga when it runs as a
+// choreography: an order is charged once its items are reserved. The outcome
+// is stored in the outbox once the use case succeeded; a redelivered event
+// repeats the idempotent use case and stores the outcome again.
+type PaymentHandler struct {
+	db        *sql.DB
+	processUC PaymentProcessor
+	logger    *logger.Logger
+}
+
+func NewPaymentHandler(db *sql.DB, processUC PaymentProcessor, log *logger.Logger) *PaymentHandler {
+	return &PaymentHandler{db: db, processUC: processUC, logger: log}
+}
+
+// Register subscribes the handler to the events it reacts to
+func (h *PaymentHandler) Register(s messaging.Consumer) {
+	s.Subscribe("InventoryReserved", Group, h.inventoryReserved)
+}
+
+func (h *PaymentHandler) inventoryReserved(ctx context.Context, msg *messaging.Message) error {
+	var event eventsv1.InventoryReserved
+	if err := messaging.Decode(msg, &event); err != nil {
+		return err
+	}
+
+	payment, err := h.processUC.Execute(ctx, dto.CreatePaymentRequest{
+		IdempotencyKey: "choreography:payment:" + event.OrderId,
+		CustomerID:     event.CustomerId,
+		OrderID:        event.OrderId,
+		Amount:         event.TotalAmount,
+	})
+	if err != nil {
+		if messaging.IsRejected(messaging.RejectPermanent(err)) {
+			h.logger.WarnWithTrace(ctx).
+				Err(err).
+				Str("order_id", event.OrderId).
+				Msg("Payment failed")
+
+			return h.store(ctx, event.OrderId, &eventsv1.PaymentFailed{
+				OrderId: event.OrderId,
+				Reason:  err.Error(),
+			})
+		}
+		return err
+	}
+
+	return h.store(ctx, event.OrderId, &eventsv1.PaymentProcessed{
+		OrderId:   event.OrderId,
+		PaymentId: payment.ID,
+		Amount:    payment.Amount,
+	})
+}
+
+func (h *PaymentHandler) store(ctx context.Context, orderID string, event proto.Message) error {
+	msg, err := outbox.NewEvent(paymentAggregate, orderID, event)
+	if err != nil {
+		return err
+	}
+	return outbox.Store(ctx, h.db, msg)
+}
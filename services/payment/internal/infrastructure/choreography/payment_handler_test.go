@@ -0,0 +1,187 @@
+package choreography
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	pErrors "github.com/dandirahmadani19/distributed-saga-orchestrator/platform/errors"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/logger"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/platform/messaging"
+	eventsv1 "github.com/dandirahmadani19/distributed-saga-orchestrator/services/payment/gen/proto/events/v1"
+	"github.com/dandirahmadani19/distributed-saga-orchestrator/services/payment/internal/application/dto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// outboxDB is a database that only takes the inserts of outbox.Store and
+// keeps the event type and payload of each message
+type outboxDB struct {
+	mu     sync.Mutex
+	stored []storedEvent
+}
+
+type storedEvent struct {
+	eventType string
+	payload   string
+}
+
+func (d *outboxDB) events() []storedEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]storedEvent(nil), d.stored...)
+}
+
+func (d *outboxDB) Connect(ctx context.Context) (driver.Conn, error) { return outboxConn{d}, nil }
+func (d *outboxDB) Driver() driver.Driver                            { return nil }
+
+type outboxConn struct{ db *outboxDB }
+
+func (c outboxConn) Prepare(query string) (driver.Stmt, error) { return outboxStmt(c), nil }
+func (c outboxConn) Close() error                              { return nil }
+func (c outboxConn) Begin() (driver.Tx, error)                 { return outboxTx{}, nil }
+
+type outboxTx struct{}
+
+func (outboxTx) Commit() error   { return nil }
+func (outboxTx) Rollback() error { return nil }
+
+type outboxStmt struct{ db *outboxDB }
+
+func (s outboxStmt) Close() error  { return nil }
+func (s outboxStmt) NumInput() int { return -1 }
+
+func (s outboxStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("unexpected statement")
+}
+
+// Query inserts a message: aggregate type, aggregate id, event type, payload...
+func (s outboxStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.stored = append(s.db.stored, storedEvent{eventType: args[2].(string), payload: string(args[3].([]byte))})
+	return &idRows{id: int64(len(s.db.stored))}, nil
+}
+
+// idRows returns the id of an inserted message
+type idRows struct {
+	id   int64
+	done bool
+}
+
+func (r *idRows) Columns() []string { return []string{"id"} }
+func (r *idRows) Close() error      { return nil }
+
+func (r *idRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.id
+	return nil
+}
+
+// processor answers every payment with err
+type processor struct{ err error }
+
+func (p processor) Execute(ctx context.Context, req dto.CreatePaymentRequest) (*dto.PaymentResponse, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &dto.PaymentResponse{ID: "payment-1", OrderID: req.OrderID, Amount: req.Amount}, nil
+}
+
+func TestInventoryReserved(t *testing.T) {
+	log := logger.New("test")
+	payload, err := protojson.Marshal(&eventsv1.InventoryReserved{OrderId: "order-1", CustomerId: "customer-1", TotalAmount: 42})
+	if err != nil {
+		t.Fatalf("protojson.Marshal() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		payload    string
+		err        error
+		wantErr    bool
+		wantReject bool
+		wantEvent  string
+		wantReason string
+	}{
+		{
+			name:      "charged",
+			payload:   string(payload),
+			wantEvent: "PaymentProcessed",
+		},
+		{
+			name:       "declined",
+			payload:    string(payload),
+			err:        pErrors.E(pErrors.Invalid, "insufficient funds", nil),
+			wantEvent:  "PaymentFailed",
+			wantReason: "insufficient funds",
+		},
+		{
+			name:       "already charged for another amount",
+			payload:    string(payload),
+			err:        pErrors.E(pErrors.Conflict, "idempotency key reused", nil),
+			wantEvent:  "PaymentFailed",
+			wantReason: "idempotency key reused",
+		},
+		{
+			name:    "database unavailable is redelivered",
+			payload: string(payload),
+			err:     pErrors.E(pErrors.Internal, "failed to create payment", errors.New("connection refused")),
+			wantErr: true,
+		},
+		{
+			name:       "undecodable event",
+			payload:    `{"order_id":`,
+			wantErr:    true,
+			wantReject: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := &outboxDB{}
+			db := sql.OpenDB(outbox)
+			defer db.Close()
+			h := NewPaymentHandler(db, processor{err: tt.err}, log)
+
+			err := h.inventoryReserved(context.Background(), &messaging.Message{
+				Topic:   "InventoryReserved",
+				Key:     "order-1",
+				Payload: json.RawMessage(tt.payload),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("inventoryReserved() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && messaging.IsRejected(err) != tt.wantReject {
+				t.Errorf("IsRejected(%v) = %v, want %v", err, !tt.wantReject, tt.wantReject)
+			}
+
+			stored := outbox.events()
+			if tt.wantEvent == "" {
+				if len(stored) != 0 {
+					t.Errorf("stored %v, want no event", stored)
+				}
+				return
+			}
+			if len(stored) != 1 || stored[0].eventType != tt.wantEvent {
+				t.Fatalf("stored %v, want one %s", stored, tt.wantEvent)
+			}
+			if tt.wantReason != "" {
+				var failed eventsv1.PaymentFailed
+				if err := protojson.Unmarshal([]byte(stored[0].payload), &failed); err != nil {
+					t.Fatalf("stored payload %s: %v", stored[0].payload, err)
+				}
+				if failed.OrderId != "order-1" || failed.Reason != tt.wantReason {
+					t.Errorf("PaymentFailed = %q %q, want order-1 %q", failed.OrderId, failed.Reason, tt.wantReason)
+				}
+			}
+		})
+	}
+}